@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/detector"
+)
+
+// cdnRangesCmd represents the cdn-ranges command
+var cdnRangesCmd = &cobra.Command{
+	Use:   "cdn-ranges",
+	Short: "Manage the local dataset of CDN and cloud IP ranges",
+	Long: `The CDN range dataset holds the published IP ranges of CDNs and clouds
+(Cloudflare, Fastly, CloudFront, Akamai, Google, ...). Scans use it to tell
+CDN edge addresses from origin candidates.`,
+}
+
+var cdnRangesUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Refresh the CDN ranges from the providers' published lists",
+	Long: `Download the published range lists of each provider and save the
+dataset to catalogs.cdn_ranges, or ~/.fogger/cdn_ranges.yaml when it is not
+set. Providers whose lists fail keep their current ranges.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		timeout, _ := cmd.Flags().GetInt("timeout")
+		path, err := cdnRangesPath()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		current := ""
+		if _, err := os.Stat(path); err == nil {
+			current = path
+		}
+		catalog, err := detector.LoadCDNRanges(current)
+		if err != nil {
+			fmt.Printf("Error loading CDN ranges: %v\n", err)
+			os.Exit(1)
+		}
+
+		client := &http.Client{Timeout: time.Duration(timeout) * time.Second}
+		updated, errs := detector.FetchCDNRanges(context.Background(), client, catalog)
+		for _, err := range errs {
+			fmt.Printf("Keeping current ranges of %v\n", err)
+		}
+
+		data, err := yaml.Marshal(updated)
+		if err != nil {
+			fmt.Printf("Error encoding CDN ranges: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			fmt.Printf("Error creating %s: %v\n", filepath.Dir(path), err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			fmt.Printf("Error saving CDN ranges: %v\n", err)
+			os.Exit(1)
+		}
+
+		prefixes := 0
+		for _, provider := range updated.Providers {
+			prefixes += len(provider.Prefixes)
+		}
+		fmt.Printf("Saved %d prefixes of %d providers to %s\n", prefixes, len(updated.Providers), path)
+	},
+}
+
+var cdnRangesLookupCmd = &cobra.Command{
+	Use:   "lookup <ip>...",
+	Short: "Show which CDN or cloud ranges hold IP addresses",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		current := ""
+		if path, err := cdnRangesPath(); err == nil {
+			if _, err := os.Stat(path); err == nil {
+				current = path
+			}
+		}
+		catalog, err := detector.LoadCDNRanges(current)
+		if err != nil {
+			fmt.Printf("Error loading CDN ranges: %v\n", err)
+			os.Exit(1)
+		}
+		ranges, err := detector.NewIPRanges(catalog)
+		if err != nil {
+			fmt.Printf("Error loading CDN ranges: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, ip := range args {
+			if provider := ranges.Lookup(ip); provider != nil {
+				fmt.Printf("%s: %s (%s)\n", ip, provider.Name, provider.Kind)
+			} else {
+				fmt.Printf("%s: not in known ranges\n", ip)
+			}
+		}
+	},
+}
+
+// cdnRangesPath returns catalogs.cdn_ranges, or ~/.fogger/cdn_ranges.yaml
+func cdnRangesPath() (string, error) {
+	if path := config.Get().Catalogs.CDNRanges; path != "" {
+		return path, nil
+	}
+	return detector.DefaultCDNRangesPath()
+}
+
+func init() {
+	cdnRangesCmd.AddCommand(cdnRangesUpdateCmd)
+	cdnRangesCmd.AddCommand(cdnRangesLookupCmd)
+	rootCmd.AddCommand(cdnRangesCmd)
+
+	cdnRangesUpdateCmd.Flags().Int("timeout", 30, "Download timeout (default: 30)")
+}
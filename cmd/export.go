@@ -92,15 +92,21 @@ func MonitorDomain(domain string, interval time.Duration, duration time.Duration
 	endTime := time.Now().Add(duration)
 	
 	fmt.Printf("Monitoring %s every %v for %v\n", domain, interval, duration)
+
+	var lastBlockStatus *models.BlockStatus
 	
 	for time.Now().Before(endTime) {
 		fmt.Printf("Scanning %s at %s...\n", domain, time.Now().Format(time.RFC3339))
 		
 		// Perform analysis
 		result := analyzer.AnalyzeDomain(domain, 10*time.Second, "standard")
+		lastBlockStatus = analyzer.CarryBlockStatus(result, lastBlockStatus)
 		
 		// Display result
 		fmt.Printf("JLI Score: %.3f, Level: %s\n", result.JLIScore, result.JLILevel)
+		if result.Domain.BlockStatus != nil {
+			fmt.Printf("Block Status: %s\n", blockStatusSummary(result.Domain.BlockStatus))
+		}
 		
 		// Wait for next scan
 		time.Sleep(interval)
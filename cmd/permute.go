@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+
+	"github.com/genesis410/fogger/internal/analyzer"
+	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/permute"
+	"github.com/genesis410/fogger/internal/resolver"
+	"github.com/genesis410/fogger/internal/store"
+)
+
+// permuteHit is a live candidate with the result of its quick scan
+type permuteHit struct {
+	permute.Hit
+	JLIScore float64 `json:"jli_score"`
+	JLILevel string  `json:"jli_level"`
+}
+
+// permuteCmd represents the permute command
+var permuteCmd = &cobra.Command{
+	Use:   "permute <domain>",
+	Short: "Find unreported mirrors by generating and resolving candidate domains",
+	Long: `Permute generates candidate mirror domains from the naming pattern of a
+seed domain: stepped numbers and suffix counters (slot88a.com -> slot89a.com,
+slot88b.com), judol TLDs (.xyz, .site, .online, .vip, ...), hyphenations and
+typos. Candidates are resolved concurrently, answers of wildcard TLDs are
+filtered out, and the live ones are quick-scanned.
+
+Live candidates are saved to the local store and linked to the seed as
+siblings of one rotation set.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		seed := args[0]
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		timeout, _ := cmd.Flags().GetInt("timeout")
+		profile, _ := cmd.Flags().GetString("profile")
+
+		cfg := config.Get()
+		if cmd.Flags().Changed("tlds") {
+			cfg.Permute.TLDs, _ = cmd.Flags().GetStringSlice("tlds")
+		}
+		if cmd.Flags().Changed("max-candidates") {
+			cfg.Permute.MaxCandidates, _ = cmd.Flags().GetInt("max-candidates")
+		}
+		if cmd.Flags().Changed("concurrency") {
+			cfg.Permute.Concurrency, _ = cmd.Flags().GetInt("concurrency")
+		}
+		if cmd.Flags().Changed("resolver") {
+			cfg.DNS.Server, _ = cmd.Flags().GetString("resolver")
+		}
+		clientTimeout := time.Duration(timeout) * time.Second
+
+		seedName, err := detector.ParseDomainName(seed)
+		if err != nil {
+			fmt.Printf("Invalid seed domain %s: %v\n", seed, err)
+			os.Exit(1)
+		}
+		candidates, _ := permute.NewGenerator(cfg.Permute.TLDs, cfg.Permute.MaxCandidates).Generate(seedName.Domain)
+
+		r, err := resolver.New(cfg.DNS.Server, clientTimeout)
+		if err != nil {
+			fmt.Printf("Invalid resolver: %v\n", err)
+			os.Exit(1)
+		}
+		if !jsonOutput {
+			fmt.Printf("Resolving %d candidates for %s\n", len(candidates), color.GreenString(seedName.Domain))
+		}
+		hits := permute.NewChecker(r, cfg.Permute.Concurrency).Resolve(context.Background(), candidates)
+
+		var domains []string
+		for _, hit := range hits {
+			domains = append(domains, hit.Domain)
+		}
+		if !jsonOutput && len(domains) > 0 {
+			fmt.Printf("Quick-scanning %d live candidates\n", len(domains))
+		}
+		// A quick scan skips the slow network modules
+		cfg.Modules.Skip = append(cfg.Modules.Skip, cfg.Permute.SkipModules...)
+		results := analyzer.AnalyzeDomains(domains, clientTimeout, profile, cfg.Permute.Concurrency)
+
+		saveHits(seedName.Domain, hits, results)
+
+		output := make([]permuteHit, len(hits))
+		for i, hit := range hits {
+			output[i] = permuteHit{Hit: hit, JLIScore: results[i].JLIScore, JLILevel: results[i].JLILevel}
+		}
+
+		if jsonOutput {
+			jsonData, err := json.MarshalIndent(output, "", "  ")
+			if err != nil {
+				fmt.Printf("Error marshaling JSON: %v\n", err)
+				return
+			}
+			fmt.Println(string(jsonData))
+			return
+		}
+
+		if len(output) == 0 {
+			fmt.Printf("No live candidates for %s\n", seedName.Domain)
+			return
+		}
+
+		permuteTable := table.NewWriter()
+		permuteTable.SetOutputMirror(color.Output)
+		permuteTable.AppendHeader(table.Row{"Domain", "Kind", "Addresses", "JLI Score", "JLI Level"})
+		for _, hit := range output {
+			permuteTable.AppendRow([]interface{}{
+				hit.Domain,
+				hit.Kind,
+				strings.Join(hit.Addresses, ", "),
+				fmt.Sprintf("%.3f", hit.JLIScore),
+				hit.JLILevel,
+			})
+		}
+		permuteTable.SetStyle(table.StyleLight)
+		permuteTable.Render()
+	},
+}
+
+// saveHits stores the results of live candidates as SIBLING_OF the seed,
+// in the seed's cluster, and links them to the seed in the store
+func saveHits(seed string, hits []permute.Hit, results []*models.AnalysisResult) {
+	if len(hits) == 0 {
+		return
+	}
+	db, err := store.OpenDefault()
+	if err != nil {
+		fmt.Printf("Error opening local store: %v\n", err)
+		return
+	}
+
+	var clusterID *string
+	if seedResult, ok := db.GetResult(seed); ok {
+		clusterID = seedResult.Domain.ClusterID
+	}
+	for i, hit := range hits {
+		result := results[i]
+		// The churn module relates the seed already when it is stored
+		linked := false
+		for _, target := range relationTargets(result, models.RelationSiblingOf) {
+			linked = linked || target == seed
+		}
+		if !linked {
+			result.Domain.Relations = append(result.Domain.Relations, models.Relation{
+				Source: hit.Domain,
+				Target: seed,
+				Type:   models.RelationSiblingOf,
+				Evidence: []models.Evidence{
+					{
+						Type:      "permutation",
+						Reference: hit.Kind + " variant of " + seed + " resolving to " + strings.Join(hit.Addresses, ", "),
+						Timestamp: time.Now(),
+					},
+				},
+			})
+		}
+		if clusterID != nil {
+			result.Domain.ClusterID = clusterID
+		}
+		db.PutResult(result)
+		if result.Domain.Registration != nil {
+			db.PutRegistration(result.Domain.Registration)
+		}
+		db.LinkSiblingRelations(result)
+		db.LinkSiblings(seed, hit.Domain)
+	}
+
+	if err := db.Save(); err != nil {
+		fmt.Printf("Error saving to local store: %v\n", err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(permuteCmd)
+
+	// Add flags for the permute command
+	permuteCmd.Flags().Bool("json", false, "Output JSON")
+	permuteCmd.Flags().Int("timeout", 5, "Network timeout (default: 5)")
+	permuteCmd.Flags().String("profile", "standard", "Scoring profile (default: standard)")
+	permuteCmd.Flags().StringSlice("tlds", nil, "TLDs to try (comma-separated; default: permute.tlds)")
+	permuteCmd.Flags().Int("max-candidates", 0, "Maximum number of candidates (default: permute.max_candidates)")
+	permuteCmd.Flags().Int("concurrency", 0, "Concurrent lookups and scans (default: permute.concurrency)")
+	permuteCmd.Flags().String("resolver", "", "DNS resolver for the lookups (default: dns.server)")
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+
+	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/plugin"
+)
+
+// samplePluginPage is sent by `plugins test` when no page file is given
+const samplePluginPage = `<html><head><title>SLOT GACOR HARI INI - Daftar Situs Slot Online</title></head>
+<body><h1>Slot Gacor Maxwin</h1><p>Deposit via QRIS, DANA dan OVO. Min depo 10rb.</p>
+<a href="/daftar">Daftar</a> <a href="/login">Login</a></body></html>`
+
+// pluginsCmd represents the plugins command
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "Manage external detector plugins",
+	Long: `Plugins are external executables listed under "plugins" in the config
+file. fogger sends each scanned page to them as JSON on stdin and reads
+signals back from stdout; see DOCUMENTATION.md for the protocol.`,
+}
+
+var pluginsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured plugins and check that they respond",
+	Run: func(cmd *cobra.Command, args []string) {
+		plugins := config.Get().Plugins
+		if len(plugins) == 0 {
+			fmt.Println("No plugins configured")
+			return
+		}
+
+		pluginTable := table.NewWriter()
+		pluginTable.SetOutputMirror(color.Output)
+		pluginTable.AppendHeader(table.Row{"Name", "Command", "Category", "Version", "Status"})
+		for _, cfg := range plugins {
+			row := table.Row{cfg.Name, cfg.Command, cfg.Category, "", ""}
+
+			p, err := plugin.New(cfg)
+			if err != nil {
+				row[4] = color.RedString(err.Error())
+				pluginTable.AppendRow(row)
+				continue
+			}
+			row[2] = p.Category()
+
+			description, err := p.Describe(context.Background())
+			if err != nil {
+				row[4] = color.RedString(err.Error())
+			} else {
+				row[3] = description.Version
+				row[4] = color.GreenString("OK") + " " + description.Description
+			}
+			pluginTable.AppendRow(row)
+		}
+		pluginTable.SetStyle(table.StyleLight)
+		pluginTable.Render()
+	},
+}
+
+var pluginsTestCmd = &cobra.Command{
+	Use:   "test <name>",
+	Short: "Run a plugin against a sample page and show its signals",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("file")
+		domain, _ := cmd.Flags().GetString("domain")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		var p *plugin.Plugin
+		for _, cfg := range config.Get().Plugins {
+			if cfg.Name != args[0] {
+				continue
+			}
+			var err error
+			if p, err = plugin.New(cfg); err != nil {
+				fmt.Printf("Error loading plugin: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if p == nil {
+			fmt.Printf("Plugin %s is not configured\n", args[0])
+			os.Exit(1)
+		}
+
+		body := samplePluginPage
+		if file != "" {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				fmt.Printf("Error reading page: %v\n", err)
+				os.Exit(1)
+			}
+			body = string(data)
+		}
+
+		page := &detector.Page{
+			Domain:     domain,
+			URL:        "https://" + domain + "/",
+			StatusCode: 200,
+			Headers:    map[string][]string{"Content-Type": {"text/html"}},
+			Body:       body,
+		}
+
+		start := time.Now()
+		signals, err := p.Analyze(context.Background(), page)
+		elapsed := time.Since(start)
+
+		if jsonOutput {
+			jsonData, _ := json.MarshalIndent(signals, "", "  ")
+			fmt.Println(string(jsonData))
+		} else {
+			fmt.Printf("Plugin %s returned %d signals in %s\n", args[0], len(signals), elapsed.Round(time.Millisecond))
+			for i, signal := range signals {
+				fmt.Printf("  %d. [%s] %s %s (Confidence: %.2f)\n",
+					i+1, signal.Category, signal.SignalID, signal.Description, signal.Confidence)
+			}
+		}
+
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	pluginsCmd.AddCommand(pluginsListCmd)
+	pluginsCmd.AddCommand(pluginsTestCmd)
+	rootCmd.AddCommand(pluginsCmd)
+
+	pluginsTestCmd.Flags().String("file", "", "HTML file to send as the page body (default: a built-in sample)")
+	pluginsTestCmd.Flags().String("domain", "example.com", "Domain to report for the page")
+	pluginsTestCmd.Flags().Bool("json", false, "Output the returned signals as JSON")
+}
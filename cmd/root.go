@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/plugin"
 )
 
 var (
@@ -62,4 +63,9 @@ func initConfig() {
 
 	// Initialize the configuration
 	config.Initialize()
+
+	// Configured plugins run as detection modules alongside the built-in ones
+	if err := plugin.RegisterConfigured(config.Get().Plugins); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
 }
\ No newline at end of file
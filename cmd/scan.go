@@ -12,7 +12,10 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/genesis410/fogger/internal/analyzer"
+	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/detector"
 	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/store"
 )
 
 // OutputJSON outputs the result in JSON format with enhanced structure
@@ -25,18 +28,37 @@ func OutputJSON(r *models.AnalysisResult) {
 			"scan_duration": "N/A", // Would be added in real implementation
 		},
 		"risk_assessment": map[string]interface{}{
-			"jli_score":   r.JLIScore,
-			"risk_level":  r.JLILevel,
-			"confidence":  calculateOverallConfidence(r),
+			"jli_score":           r.JLIScore,
+			"risk_level":          r.JLILevel,
+			"confidence":          calculateOverallConfidence(r),
+			"fli_score":           r.FLIScore,
+			"fli_level":           r.FLILevel,
+			"threat_type":         r.ThreatType,
+			"host_classification": r.HostClassification,
 		},
 		"technical_details": map[string]interface{}{
 			"cdn_provider":    r.Domain.CDNProvider,
+			"cdn_matches":     r.Domain.CDNMatches,
+			"cname_chains":    r.Domain.CNAMEChains,
 			"ip_address":      "N/A", // Would be added in real implementation
 			"origin_ip_guess": "N/A", // Would be added in real implementation
 			"ssl_info":        map[string]interface{}{},
+			"game_providers":  r.Domain.GameProviders,
+			"platform":        r.Domain.Platform,
+			"vertical":        r.Domain.Vertical,
+			"verticals":       r.Domain.Verticals,
+			"mirrors":         relationTargets(r, models.RelationMirrorOf),
+			"siblings":        relationTargets(r, models.RelationSiblingOf),
+			"redirect_chains": r.Domain.RedirectChains,
+			"injected_urls":   r.Domain.InjectedURLs,
+			"dns_records":     r.Domain.DNSRecords,
+			"block_status":    r.Domain.BlockStatus,
+			"registration":    r.Domain.Registration,
+			"subdomains":      r.Domain.Subdomains,
 		},
 		"detection_evidence": r.Domain.Signals,
 		"category_breakdown": r.CategoryBreakdown,
+		"modules":            r.Modules,
 	}
 
 	jsonData, err := json.MarshalIndent(output, "", "  ")
@@ -89,6 +111,73 @@ func OutputTable(r *models.AnalysisResult) {
 	summaryTable.SetStyle(table.StyleLight)
 	summaryTable.Render()
 
+	if r.ThreatType != "" {
+		fmt.Printf("Threat Type: %s\n", r.ThreatType)
+	}
+	if r.HostClassification != "" {
+		fmt.Printf("Host Classification: %s\n", r.HostClassification)
+	}
+	if r.FLIScore > 0 {
+		fmt.Printf("Fraud Likelihood Index: %.3f (%s)\n", r.FLIScore, r.FLILevel)
+	}
+	if len(r.Domain.GameProviders) > 0 {
+		fmt.Printf("Game Providers: %s\n", strings.Join(r.Domain.GameProviders, ", "))
+	}
+	if r.Domain.Platform != "" {
+		fmt.Printf("Platform: %s\n", r.Domain.Platform)
+	}
+	if nameservers := detector.DNSValues(r.Domain.DNSRecords, "NS"); len(nameservers) > 0 {
+		fmt.Printf("Nameservers: %s\n", strings.Join(nameservers, ", "))
+	}
+	if addresses := addressSummary(r.Domain.DNSRecords); len(addresses) > 0 {
+		fmt.Printf("Addresses: %s\n", strings.Join(addresses, ", "))
+	}
+	for _, chain := range r.Domain.CNAMEChains {
+		fmt.Printf("CNAME Chain: %s\n", chain.String())
+	}
+	if r.Domain.BlockStatus != nil {
+		fmt.Printf("Block Status: %s\n", blockStatusSummary(r.Domain.BlockStatus))
+	}
+	if reg := r.Domain.Registration; reg != nil {
+		registration := reg.Registrar
+		if reg.CreatedAt != nil {
+			registration += fmt.Sprintf(", registered %s (%d days ago)", reg.CreatedAt.Format("2006-01-02"), int(reg.Age(time.Now()).Hours()/24))
+		}
+		if reg.PrivacyService != "" {
+			registration += ", privacy service " + reg.PrivacyService
+		}
+		fmt.Printf("Registration: %s\n", strings.TrimPrefix(registration, ", "))
+	}
+	if len(r.Domain.Verticals) > 0 {
+		fmt.Printf("Verticals: %s\n", strings.Join(r.Domain.Verticals, ", "))
+	}
+	if mirrors := relationTargets(r, models.RelationMirrorOf); len(mirrors) > 0 {
+		fmt.Printf("Mirrors: %s\n", strings.Join(mirrors, ", "))
+	}
+	if siblings := relationTargets(r, models.RelationSiblingOf); len(siblings) > 0 {
+		fmt.Printf("Sibling Domains: %s\n", strings.Join(siblings, ", "))
+	}
+	if len(r.Domain.InjectedURLs) > 0 {
+		fmt.Printf("Injected URLs:\n")
+		for _, injected := range r.Domain.InjectedURLs {
+			fmt.Printf("  - %s\n", injected)
+		}
+	}
+	if len(r.Domain.Subdomains) > 0 {
+		fmt.Printf("Subdomains:\n")
+		for _, subdomain := range r.Domain.Subdomains {
+			fmt.Printf("  - %s -> %s (CDN: %s)\n", subdomain.Name, strings.Join(subdomain.Addresses, ", "), subdomain.CDN)
+		}
+	}
+	for _, chain := range r.Domain.RedirectChains {
+		fmt.Printf("Shortlink: %s -> %s\n", strings.Join(chain.Hops, " -> "), chain.Destination)
+	}
+	for _, run := range r.Modules {
+		if run.Error != "" && !run.Skipped {
+			fmt.Printf("Module %s failed: %s\n", run.Name, run.Error)
+		}
+	}
+
 	fmt.Println()
 
 	// Category Breakdown Table
@@ -199,6 +288,21 @@ func OutputDetailedReport(r *models.AnalysisResult) {
 
 	fmt.Println()
 
+	// Module timings
+	fmt.Println("MODULES:")
+	for _, run := range r.Modules {
+		status := fmt.Sprintf("%d signals", run.Signals)
+		if run.Skipped {
+			status = "skipped"
+		}
+		if run.Error != "" {
+			status += " (" + run.Error + ")"
+		}
+		fmt.Printf("  %-16s %10s  %s\n", run.Name, run.Duration.Round(time.Millisecond), status)
+	}
+
+	fmt.Println()
+
 	// Confidence summary
 	confidence := calculateOverallConfidence(r)
 	fmt.Printf("OVERALL CONFIDENCE: %.2f\n", confidence)
@@ -239,6 +343,31 @@ along with evidence of gambling-related activities.`,
 		timeout, _ := cmd.Flags().GetInt("timeout")
 		profile, _ := cmd.Flags().GetString("profile")
 		save, _ := cmd.Flags().GetBool("save")
+		mirrorDepth := config.Get().Discovery.MirrorDepth
+		if cmd.Flags().Changed("mirror-depth") {
+			mirrorDepth, _ = cmd.Flags().GetInt("mirror-depth")
+		}
+
+		// Module selection overrides the modules section of the config
+		if cmd.Flags().Changed("modules") {
+			config.Get().Modules.Enabled, _ = cmd.Flags().GetStringSlice("modules")
+		}
+		if cmd.Flags().Changed("skip-modules") {
+			config.Get().Modules.Skip, _ = cmd.Flags().GetStringSlice("skip-modules")
+		}
+		if cmd.Flags().Changed("compromise-check") {
+			config.Get().Compromise.Enabled, _ = cmd.Flags().GetBool("compromise-check")
+		}
+		if cmd.Flags().Changed("block-check") {
+			config.Get().BlockCheck.Enabled, _ = cmd.Flags().GetBool("block-check")
+		}
+		if cmd.Flags().Changed("resolver") {
+			config.Get().DNS.Server, _ = cmd.Flags().GetString("resolver")
+		}
+		if _, err := detector.DefaultRegistry.Select(config.Get().Modules.Enabled, config.Get().Modules.Skip); err != nil {
+			fmt.Printf("Invalid module selection: %v\n", err)
+			os.Exit(1)
+		}
 
 		if noColor {
 			color.NoColor = true
@@ -253,20 +382,32 @@ along with evidence of gambling-related activities.`,
 
 		// Perform the analysis
 		result := analyzer.AnalyzeDomain(domain, clientTimeout, profile)
+		results := []*models.AnalysisResult{result}
 
-		if jsonOutput {
-			OutputJSON(result)
-		} else if csvOutput {
-			OutputCSV(result)
-		} else if detailedOutput {
-			OutputDetailedReport(result)
-		} else {
-			OutputTable(result)
+		// Follow the mirror domains the site advertises
+		if mirrorDepth > 0 {
+			if !batchMode {
+				fmt.Printf("Following mirror domains up to depth %d\n", mirrorDepth)
+			}
+			mirrors := analyzer.DiscoverMirrors(result, clientTimeout, profile, mirrorDepth, config.Get().Discovery.MaxDomains)
+			results = append(results, mirrors...)
 		}
 
-		if save {
-			// Save to local DB
-			SaveToDB(result)
+		for _, result := range results {
+			if jsonOutput {
+				OutputJSON(result)
+			} else if csvOutput {
+				OutputCSV(result)
+			} else if detailedOutput {
+				OutputDetailedReport(result)
+			} else {
+				OutputTable(result)
+			}
+
+			if save {
+				// Save to local DB
+				SaveToDB(result)
+			}
 		}
 	},
 }
@@ -310,6 +451,57 @@ func truncateString(str string, num int) string {
 	return str
 }
 
+// addressSummary lists the A and AAAA addresses of records with the CDN or
+// cloud whose ranges hold them
+func addressSummary(records []models.DNSRecord) []string {
+	var addresses []string
+	for _, record := range records {
+		if record.Type != "A" && record.Type != "AAAA" {
+			continue
+		}
+		switch {
+		case record.CDN:
+			addresses = append(addresses, fmt.Sprintf("%s (CDN: %s)", record.Value, record.Provider))
+		case record.Provider != "":
+			addresses = append(addresses, fmt.Sprintf("%s (%s)", record.Value, record.Provider))
+		default:
+			addresses = append(addresses, record.Value)
+		}
+	}
+	return addresses
+}
+
+// blockStatusSummary describes an ISP block status in one line
+func blockStatusSummary(b *models.BlockStatus) string {
+	summary := b.Status
+	if b.Method != "" {
+		summary += " (" + b.Method + ")"
+	}
+	if b.BlockedSince != nil {
+		summary += " since " + b.BlockedSince.Format("2006-01-02 15:04")
+	}
+	if b.Reachable {
+		summary += ", still reachable"
+	} else {
+		summary += ", unreachable"
+	}
+	if after := b.ReachableAfterBlock(); after > 0 {
+		summary += fmt.Sprintf(", reachable for %s after the block", after.Round(time.Minute))
+	}
+	return summary
+}
+
+// relationTargets returns the domains the result relates to by relationType
+func relationTargets(r *models.AnalysisResult, relationType string) []string {
+	var targets []string
+	for _, relation := range r.Domain.Relations {
+		if relation.Type == relationType {
+			targets = append(targets, relation.Target)
+		}
+	}
+	return targets
+}
+
 func calculateOverallConfidence(r *models.AnalysisResult) float64 {
 	// Calculate based on number of high-confidence signals
 	highConfidenceCount := 0
@@ -384,10 +576,24 @@ func getRecommendations(level string, signals []models.Signal) []string {
 	return recommendations
 }
 
-// SaveToDB saves the result to local database
+// SaveToDB saves the result to the local store
 func SaveToDB(r *models.AnalysisResult) {
-	// In a real implementation, this would save to a local SQLite database
-	fmt.Println("Saving to local database... (not implemented in this example)")
+	db, err := store.OpenDefault()
+	if err != nil {
+		fmt.Printf("Error opening local store: %v\n", err)
+		return
+	}
+
+	db.PutResult(r)
+	db.LinkSiblingRelations(r)
+	if r.Domain.Registration != nil {
+		db.PutRegistration(r.Domain.Registration)
+	}
+	if err := db.Save(); err != nil {
+		fmt.Printf("Error saving to local store: %v\n", err)
+		return
+	}
+	fmt.Printf("Saved %s to %s\n", r.Domain.Domain, db.Path)
 }
 
 func init() {
@@ -402,4 +608,10 @@ func init() {
 	scanCmd.Flags().Int("timeout", 10, "Network timeout (default: 10)")
 	scanCmd.Flags().String("profile", "standard", "Scoring profile (default: standard)")
 	scanCmd.Flags().Bool("save", false, "Persist result to local DB")
+	scanCmd.Flags().Int("mirror-depth", 0, "Scan advertised mirror domains up to this many hops (default: discovery.mirror_depth)")
+	scanCmd.Flags().StringSlice("modules", nil, "Run only these detection modules (comma-separated; default: all)")
+	scanCmd.Flags().StringSlice("skip-modules", nil, "Detection modules to skip (comma-separated)")
+	scanCmd.Flags().Bool("compromise-check", false, "Check for injected judol pages on any domain, not only compromise.suffixes")
+	scanCmd.Flags().Bool("block-check", false, "Check whether ISPs block the domain (Internet Positif / TrustPositif)")
+	scanCmd.Flags().String("resolver", "", "DNS resolver: system, host[:port], tcp://host, https://doh-url or tls://host (default: dns.server)")
 }
\ No newline at end of file
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+
+	"github.com/genesis410/fogger/internal/analyzer"
+	"github.com/genesis410/fogger/internal/store"
+)
+
+// similarCmd represents the similar command
+var similarCmd = &cobra.Command{
+	Use:   "similar <domain>",
+	Short: "Find stored domains serving near-duplicate pages",
+	Long: `Similar compares a domain's page against the results in the local store
+using MinHash/SimHash signatures of its visible text and DOM structure,
+and lists the domains above the similarity threshold.
+
+The domain is taken from the store if it was saved with --save, otherwise
+it is scanned first.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		domain := args[0]
+		threshold, _ := cmd.Flags().GetFloat64("threshold")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		timeout, _ := cmd.Flags().GetInt("timeout")
+
+		db, err := store.OpenDefault()
+		if err != nil {
+			fmt.Printf("Error opening local store: %v\n", err)
+			os.Exit(1)
+		}
+
+		result, ok := db.GetResult(domain)
+		if !ok || result.Domain.Signature == nil {
+			if !jsonOutput {
+				fmt.Printf("Scanning domain: %s\n", color.GreenString(domain))
+			}
+			result = analyzer.AnalyzeDomain(domain, time.Duration(timeout)*time.Second, "standard")
+		}
+		if result.Domain.Signature == nil {
+			fmt.Printf("No page content available for %s\n", domain)
+			os.Exit(1)
+		}
+
+		// The store keeps the LSH buckets of saved pages, so near-duplicate
+		// lookups only compare the domains sharing a bucket
+		index := analyzer.NewSimilarityIndex()
+		for _, stored := range db.SimilarCandidates(result.Domain.Signature, threshold) {
+			if stored.Domain.Domain != result.Domain.Domain {
+				index.Add(stored.Domain.Domain, stored.Domain.Signature)
+			}
+		}
+		matches := index.Query(result.Domain.Signature, threshold)
+
+		if jsonOutput {
+			jsonData, err := json.MarshalIndent(matches, "", "  ")
+			if err != nil {
+				fmt.Printf("Error marshaling JSON: %v\n", err)
+				return
+			}
+			fmt.Println(string(jsonData))
+			return
+		}
+
+		if len(matches) == 0 {
+			fmt.Printf("No stored domains above similarity %.2f\n", threshold)
+			return
+		}
+
+		similarTable := table.NewWriter()
+		similarTable.SetOutputMirror(color.Output)
+		similarTable.AppendHeader(table.Row{"Domain", "Similarity", "Text SimHash Distance", "DOM SimHash Distance"})
+		for _, match := range matches {
+			similarTable.AppendRow([]interface{}{
+				match.Domain,
+				fmt.Sprintf("%.2f", match.Similarity),
+				match.TextSimHashDist,
+				match.DOMSimHashDist,
+			})
+		}
+		similarTable.SetStyle(table.StyleLight)
+		similarTable.Render()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(similarCmd)
+
+	// Add flags for the similar command
+	similarCmd.Flags().Float64("threshold", 0.5, "Minimum estimated Jaccard similarity (0-1)")
+	similarCmd.Flags().Bool("json", false, "Output JSON")
+	similarCmd.Flags().Int("timeout", 10, "Network timeout when the domain has to be scanned (default: 10)")
+}
@@ -0,0 +1,129 @@
+// Command keyword-classifier is an example fogger detector plugin. It scores
+// a page by weighted gambling phrases and reports one signal when the score
+// passes a threshold.
+//
+// Build it and list it in ~/.fogger.yaml:
+//
+//	plugins:
+//	  - name: keyword-classifier
+//	    command: /usr/local/bin/keyword-classifier
+//	    category: UX
+//	    timeout: 5
+//
+// The plugin reads one JSON request from stdin and writes one JSON response
+// to stdout. It deliberately does not import fogger packages, so it shows
+// everything a plugin in another language has to implement.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const protocolVersion = 1
+
+type request struct {
+	ProtocolVersion int    `json:"protocol_version"`
+	Type            string `json:"type"`
+	Page            *struct {
+		Domain string `json:"domain"`
+		Body   string `json:"body"`
+	} `json:"page"`
+}
+
+type evidence struct {
+	Type      string    `json:"type"`
+	Reference string    `json:"reference"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type signal struct {
+	SignalID    string     `json:"signal_id"`
+	Category    string     `json:"category"`
+	Description string     `json:"description"`
+	Confidence  float64    `json:"confidence"`
+	Evidence    []evidence `json:"evidence"`
+}
+
+type response struct {
+	ProtocolVersion int      `json:"protocol_version"`
+	Name            string   `json:"name,omitempty"`
+	Version         string   `json:"version,omitempty"`
+	Description     string   `json:"description,omitempty"`
+	Signals         []signal `json:"signals,omitempty"`
+	Error           string   `json:"error,omitempty"`
+}
+
+// phraseWeights stands in for a trained model's feature weights
+var phraseWeights = map[string]float64{
+	"slot gacor": 0.35, "maxwin": 0.3, "rtp live": 0.3, "min depo": 0.25,
+	"bonus new member": 0.25, "link alternatif": 0.2, "daftar": 0.05, "deposit": 0.05,
+}
+
+func main() {
+	var req request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid request: %v\n", err)
+		os.Exit(1)
+	}
+
+	resp := response{ProtocolVersion: protocolVersion}
+	switch {
+	case req.ProtocolVersion != protocolVersion:
+		resp.Error = fmt.Sprintf("unsupported protocol version %d", req.ProtocolVersion)
+	case req.Type == "describe":
+		resp.Name = "keyword-classifier"
+		resp.Version = "0.1.0"
+		resp.Description = "Weighted gambling phrase classifier"
+	case req.Type == "analyze" && req.Page != nil:
+		resp.Signals = classify(req.Page.Body)
+	default:
+		resp.Error = "unsupported request type " + req.Type
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(resp); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write response: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// classify scores the page body and returns a signal if it looks like a gambling page
+func classify(body string) []signal {
+	text := strings.ToLower(body)
+
+	score := 0.0
+	var matched []string
+	for phrase, weight := range phraseWeights {
+		if strings.Contains(text, phrase) {
+			score += weight
+			matched = append(matched, phrase)
+		}
+	}
+	if score < 0.5 {
+		return nil
+	}
+	sort.Strings(matched)
+	if score > 1 {
+		score = 1
+	}
+
+	return []signal{
+		{
+			SignalID:    "KEYWORD_CLASSIFIER",
+			Category:    "UX",
+			Description: fmt.Sprintf("Keyword classifier score %.2f", score),
+			Confidence:  score,
+			Evidence: []evidence{
+				{
+					Type:      "plugin",
+					Reference: "Matched phrases: " + strings.Join(matched, ", "),
+					Timestamp: time.Now(),
+				},
+			},
+		},
+	}
+}
@@ -57,6 +57,7 @@ func TestAnalyzer(t *testing.T) {
 	`
 	
 	// Test content without needing mockScanResult
+	_ = testContent
 	
 	// We can't easily test the analyzer without a real domain scan,
 	// but we can test the configuration
@@ -327,8 +328,8 @@ func TestClusterEngine(t *testing.T) {
 	t.Logf("Created cluster %s with %d domains", clusterID, len(cluster.Domains))
 }
 
-// ExampleTest demonstrates how to run the fogger tool
-func ExampleTest() {
+// Example demonstrates how to run the fogger tool
+func Example() {
 	fmt.Println("fogger tool is ready to scan domains for gambling indicators")
 	
 	// Initialize config
@@ -3,11 +3,15 @@ package analyzer
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/detector"
 	"github.com/genesis410/fogger/internal/models"
 	"github.com/genesis410/fogger/internal/scanner"
 )
@@ -17,34 +21,66 @@ func AnalyzeDomain(domain string, timeout time.Duration, profile string) *models
 	// Get configuration
 	cfg := config.Get()
 
+	// A shortener is only a hop to the real site; scan its destination instead.
+	// Link-in-bio pages list several sites and are scanned as they are.
+	var redirectChains []models.RedirectChain
+	expander := scanner.NewShortlinkExpander(timeout)
+	if expander.IsShortlink(domain) && !expander.IsLinkPage(domain) {
+		chain, err := expander.Expand(domain)
+		if err != nil {
+			fmt.Printf("Error expanding shortlink %s: %v\n", domain, err)
+		} else if destination, err := url.Parse(chain.Destination); err == nil && destination.Hostname() != "" {
+			redirectChains = append(redirectChains, *chain)
+			domain = destination.Hostname()
+		}
+	}
+
 	// Perform scanning
 	scanResult := scanner.ScanDomain(domain, timeout)
+	redirectChains = append(redirectChains, scanResult.RedirectChains...)
 
-	// Perform behavioral analysis
-	behavioralAnalyzer := NewBehavioralAnalyzer()
-	behavioralSignals := behavioralAnalyzer.AnalyzeContent(scanResult.Body)
+	// Behavioral and DOM analysis run as modules during the scan
+	allSignals := scanResult.Signals
 
-	// Also analyze DOM structure
-	domSignals := behavioralAnalyzer.AnalyzeDOMStructure(scanResult.Body)
-
-	// Combine all signals
-	allSignals := append(scanResult.Signals, behavioralSignals...)
-	allSignals = append(allSignals, domSignals...)
+	// Gambling signals feed the JLI and lending/investment scam signals the
+	// FLI; shared payment and infrastructure signals count toward both
+	gamblingSignals, fraudSignals := splitThreatSignals(allSignals)
 
 	// Calculate JLI score
-	categoryScores := calculateCategoryScoresWithSignals(allSignals)
-	jliScore := calculateEnhancedJLIScore(categoryScores, cfg.Scoring, allSignals)
+	categoryScores := calculateCategoryScoresWithSignals(gamblingSignals)
+	jliScore := calculateEnhancedJLIScore(categoryScores, cfg.Scoring, gamblingSignals, scanResult.Registration)
 	jliLevel := classifyJLILevel(jliScore, cfg.Threshold)
 
+	// Calculate FLI score
+	fliScore := calculateFLIScore(fraudSignals, cfg.FraudScoring)
+	fliLevel := classifyJLILevel(fliScore, cfg.Threshold)
+
 	// Create domain model
 	domainModel := models.Domain{
-		Domain:      domain,
-		FirstSeen:   time.Now(),
-		LastSeen:    time.Now(),
-		CDNProvider: scanResult.CDNProvider,
-		JLIScore:    jliScore,
-		JLILevel:    jliLevel,
-		Signals:     allSignals,
+		Domain:         domain,
+		FirstSeen:      time.Now(),
+		LastSeen:       time.Now(),
+		CDNProvider:    scanResult.CDNProvider,
+		CDNMatches:     scanResult.CDNMatches,
+		CNAMEChains:    scanResult.CNAMEChains,
+		JLIScore:       jliScore,
+		JLILevel:       jliLevel,
+		Signals:        allSignals,
+		Resources:      scanResult.Resources,
+		GameProviders:  scanResult.GameProviders,
+		Platform:       scanResult.Platform,
+		Relations:      scanResult.Relations,
+		RedirectChains: redirectChains,
+		Signature:      scanResult.Signature,
+		Verticals:      classifyVerticals(allSignals),
+		InjectedURLs:   scanResult.InjectedURLs,
+		DNSRecords:     scanResult.DNSRecords,
+		BlockStatus:    scanResult.BlockStatus,
+		Registration:   scanResult.Registration,
+		Subdomains:     scanResult.Subdomains,
+	}
+	if len(domainModel.Verticals) > 0 {
+		domainModel.Vertical = domainModel.Verticals[0]
 	}
 
 	// Create category breakdown
@@ -72,16 +108,134 @@ func AnalyzeDomain(domain string, timeout time.Duration, profile string) *models
 
 	// Create analysis result
 	result := &models.AnalysisResult{
-		Domain:            domainModel,
-		JLIScore:          jliScore,
-		JLILevel:          jliLevel,
-		CategoryBreakdown: categoryBreakdown,
-		ProfileUsed:       profile,
+		Domain:             domainModel,
+		JLIScore:           jliScore,
+		JLILevel:           jliLevel,
+		CategoryBreakdown:  categoryBreakdown,
+		ProfileUsed:        profile,
+		Modules:            scanResult.Modules,
+		FLIScore:           fliScore,
+		FLILevel:           fliLevel,
+		ThreatType:         classifyThreat(jliScore, fliScore, fraudSignals, cfg.Threshold),
+		HostClassification: classifyHost(jliLevel, allSignals),
 	}
 
 	return result
 }
 
+// classifyHost tells a compromised legitimate site, whose gambling content was
+// injected, apart from a gambling operator. It returns "" for sites that are
+// neither.
+func classifyHost(jliLevel string, signals []models.Signal) string {
+	for _, signal := range signals {
+		if strings.HasPrefix(signal.SignalID, detector.CompromiseSignalPrefix) {
+			return models.HostCompromised
+		}
+	}
+	if jliLevel != "LOW" {
+		return models.HostOperator
+	}
+	return ""
+}
+
+// classifyVerticals returns the gambling verticals (slot, togel, ...) named by
+// the signals, strongest first
+func classifyVerticals(signals []models.Signal) []string {
+	scores := make(map[string]float64)
+	for _, signal := range signals {
+		if signal.Vertical == "" {
+			continue
+		}
+		// Several signals for one vertical make it more likely than a single strong one
+		scores[signal.Vertical] += signal.Confidence
+	}
+
+	var verticals []string
+	for vertical := range scores {
+		verticals = append(verticals, vertical)
+	}
+	sort.Slice(verticals, func(i, j int) bool {
+		if scores[verticals[i]] != scores[verticals[j]] {
+			return scores[verticals[i]] > scores[verticals[j]]
+		}
+		return verticals[i] < verticals[j]
+	})
+	return verticals
+}
+
+// splitThreatSignals separates the signals scored by the JLI from those
+// scored by the FLI. Untagged UX signals and gambling verticals are judol
+// evidence only; untagged payment, infrastructure, DNS and CDN signals
+// describe the hosting and payment rails both threats share.
+func splitThreatSignals(signals []models.Signal) ([]models.Signal, []models.Signal) {
+	var gamblingSignals, fraudSignals []models.Signal
+	for _, signal := range signals {
+		switch {
+		case signal.Threat != "":
+			fraudSignals = append(fraudSignals, signal)
+		case signal.Category == "UX" || signal.Vertical != "":
+			gamblingSignals = append(gamblingSignals, signal)
+		default:
+			gamblingSignals = append(gamblingSignals, signal)
+			fraudSignals = append(fraudSignals, signal)
+		}
+	}
+	return gamblingSignals, fraudSignals
+}
+
+// calculateFLIScore calculates the Fraud Likelihood Index score. Shared
+// signals alone do not make a site a lending or investment scam, so the
+// score is 0 without at least one threat-tagged signal.
+func calculateFLIScore(signals []models.Signal, weights config.FraudScoringConfig) float64 {
+	tagged := false
+	for _, signal := range signals {
+		if signal.Threat != "" {
+			tagged = true
+			break
+		}
+	}
+	if !tagged {
+		return 0.0
+	}
+
+	categoryScores := calculateCategoryScoresWithSignals(signals)
+	fliRaw := 0.0
+	fliRaw += categoryScores["UX"] * weights.Content
+	fliRaw += categoryScores["PAYMENT"] * weights.Payment
+	fliRaw += categoryScores["INFRA"] * weights.Infra
+	fliRaw += categoryScores["DNS"] * weights.DNS
+	fliRaw += categoryScores["CDN"] * weights.CDN
+
+	fliScore := fliRaw * calculateConfidenceFactor(categoryScores)
+	if fliScore > 1.0 {
+		fliScore = 1.0
+	}
+	return fliScore
+}
+
+// classifyThreat names the threat type of a site from the index that is
+// highest and reaches MEDIUM; for the FLI the sub-threat with the strongest
+// signals wins. It returns "" when neither index reaches MEDIUM.
+func classifyThreat(jliScore, fliScore float64, fraudSignals []models.Signal, thresholds config.ThresholdConfig) string {
+	if jliScore < thresholds.Medium && fliScore < thresholds.Medium {
+		return ""
+	}
+	if jliScore >= fliScore {
+		return models.ThreatJudol
+	}
+
+	scores := make(map[string]float64)
+	for _, signal := range fraudSignals {
+		if signal.Threat != "" {
+			scores[signal.Threat] += signal.Confidence
+		}
+	}
+	if scores[models.ThreatInvestment] > scores[models.ThreatPinjol] {
+		return models.ThreatInvestment
+	}
+	return models.ThreatPinjol
+}
+
 // calculateCategoryScores calculates scores for each category
 func calculateCategoryScores(scanResult *scanner.ScanResult) map[string]float64 {
 	categoryScores := make(map[string]float64)
@@ -144,7 +298,7 @@ func calculateJLIScore(categoryScores map[string]float64, weights config.Scoring
 }
 
 // Enhanced JLI calculation with additional factors
-func calculateEnhancedJLIScore(categoryScores map[string]float64, weights config.ScoringConfig, signals []models.Signal) float64 {
+func calculateEnhancedJLIScore(categoryScores map[string]float64, weights config.ScoringConfig, signals []models.Signal, registration *models.Registration) float64 {
 	// Start with basic calculation
 	jliBase := calculateJLIScore(categoryScores, weights)
 
@@ -152,7 +306,7 @@ func calculateEnhancedJLIScore(categoryScores map[string]float64, weights config
 	signalFactor := calculateSignalFactor(signals)
 
 	// Apply temporal factors if available
-	temporalFactor := calculateTemporalFactor()
+	temporalFactor := calculateTemporalFactor(registration, time.Now())
 
 	// Combine factors
 	enhancedScore := jliBase * signalFactor * temporalFactor
@@ -191,15 +345,24 @@ func calculateSignalFactor(signals []models.Signal) float64 {
 	return 1.0
 }
 
-// calculateTemporalFactor adjusts score based on time factors
-func calculateTemporalFactor() float64 {
-	// In a real implementation, this would consider:
-	// - How recently the domain was registered
-	// - How long similar patterns have been observed
-	// - Time-based trends in behavior
+// calculateTemporalFactor adjusts score based on time factors. Young
+// domains already score through the DOMAIN_NEWLY_REGISTERED and
+// DOMAIN_RECENTLY_REGISTERED signals, so only long-standing domains, which
+// judol operators rarely keep, are scored lower here.
+func calculateTemporalFactor(registration *models.Registration, now time.Time) float64 {
+	if registration == nil || registration.CreatedAt == nil {
+		return 1.0
+	}
 
-	// For now, return neutral factor
-	return 1.0
+	days := registration.Age(now).Hours() / 24
+	switch {
+	case days < 365:
+		return 1.0
+	case days < 3*365:
+		return 0.95
+	default:
+		return 0.9
+	}
 }
 
 // calculateConfidenceFactor calculates a factor based on number of categories with signals
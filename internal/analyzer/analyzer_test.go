@@ -1,7 +1,13 @@
 package analyzer
 
 import (
+	"math/rand"
 	"testing"
+	"time"
+
+	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/models"
 )
 
 // TestAnalyzerInitialization tests that the analyzer package initializes correctly
@@ -25,4 +31,374 @@ func TestAnalyzerInitialization(t *testing.T) {
 	}
 	
 	t.Log("Analyzer initialization test passed")
-}
\ No newline at end of file
+}
+
+// TestClusterBySharedTracker tests that a shared tracker ID alone clusters two domains
+func TestClusterBySharedTracker(t *testing.T) {
+	engine := NewClusterEngine()
+
+	first := &models.AnalysisResult{
+		Domain: models.Domain{
+			Domain:    "slot88a.com",
+			Resources: []models.Resource{{Type: detector.ResourceGoogleAnalytics, Value: "UA-1234567-1"}},
+		},
+	}
+	second := &models.AnalysisResult{
+		Domain: models.Domain{
+			Domain: "gacor77.xyz",
+			Resources: []models.Resource{
+				{Type: detector.ResourceGoogleAnalytics, Value: "UA-1234567-1"},
+				{Type: detector.ResourceWhatsApp, Value: "6281234567890"},
+			},
+		},
+	}
+
+	firstID := engine.AddDomainToCluster(first.Domain.Domain, first)
+	secondID := engine.AddDomainToCluster(second.Domain.Domain, second)
+
+	if firstID != secondID {
+		t.Errorf("Expected domains sharing a tracker ID to join the same cluster, got %s and %s", firstID, secondID)
+	}
+
+	clusters := engine.FindClustersByResource(detector.ResourceWhatsApp, "6281234567890")
+	if len(clusters) != 1 {
+		t.Errorf("Expected to find 1 cluster by WhatsApp resource, got %d", len(clusters))
+	}
+}
+
+// TestDiscoverMirrors tests that mirror discovery stops at the configured depth
+func TestDiscoverMirrors(t *testing.T) {
+	mirrors := map[string][]string{
+		"seed.com":  {"hop1a.com", "hop1b.com"},
+		"hop1a.com": {"seed.com", "hop2.com"},
+		"hop2.com":  {"hop3.com"},
+	}
+
+	original := analyzeFunc
+	defer func() { analyzeFunc = original }()
+
+	var analyzed []string
+	analyzeFunc = func(domain string, timeout time.Duration, profile string) *models.AnalysisResult {
+		analyzed = append(analyzed, domain)
+		return mirrorResult(domain, mirrors[domain])
+	}
+
+	results := DiscoverMirrors(mirrorResult("seed.com", mirrors["seed.com"]), time.Second, "standard", 2, 10)
+
+	expected := []string{"hop1a.com", "hop1b.com", "hop2.com"}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d discovered domains, got %v", len(expected), analyzed)
+	}
+	for i, domain := range expected {
+		if results[i].Domain.Domain != domain {
+			t.Errorf("Expected result %d to be %s, got %s", i, domain, results[i].Domain.Domain)
+		}
+	}
+
+	limited := DiscoverMirrors(mirrorResult("seed.com", mirrors["seed.com"]), time.Second, "standard", 2, 1)
+	if len(limited) != 1 {
+		t.Errorf("Expected max domains to cap discovery at 1, got %d", len(limited))
+	}
+}
+
+// TestClusterByMirrorLink tests that a mirror link between two domains
+// clusters them and that linking the same third domain does not
+func TestClusterByMirrorLink(t *testing.T) {
+	engine := NewClusterEngine()
+
+	seed := engine.AddDomainToCluster("gacor88.com", mirrorResult("gacor88.com", []string{"gacor88b.net", "kompas.com"}))
+	if id := engine.AddDomainToCluster("maxwin77.com", mirrorResult("maxwin77.com", []string{"kompas.com"})); id == seed {
+		t.Errorf("Expected sites linking the same third domain to stay apart")
+	}
+	if id := engine.AddDomainToCluster("gacor88b.net", mirrorResult("gacor88b.net", nil)); id != seed {
+		t.Errorf("Expected the advertised mirror to join the cluster of gacor88.com")
+	}
+	if id := engine.AddDomainToCluster("gacor88c.org", mirrorResult("gacor88c.org", []string{"gacor88b.net"})); id != seed {
+		t.Errorf("Expected a site advertising a member to join its cluster")
+	}
+}
+
+// mirrorResult builds an analysis result advertising the given mirrors
+func mirrorResult(domain string, mirrors []string) *models.AnalysisResult {
+	result := &models.AnalysisResult{Domain: models.Domain{Domain: domain}}
+	for _, mirror := range mirrors {
+		result.Domain.Relations = append(result.Domain.Relations, models.Relation{
+			Source: domain,
+			Target: mirror,
+			Type:   models.RelationMirrorOf,
+		})
+	}
+	return result
+}
+
+// TestSimilarityIndex tests LSH lookup and near-duplicate clustering
+func TestSimilarityIndex(t *testing.T) {
+	base := make([]uint32, detector.MinHashSize)
+	for i := range base {
+		base[i] = uint32(i * 7919)
+	}
+	near := append([]uint32{}, base...)
+	for i := 0; i < 4; i++ {
+		near[i*16] = 1 // differs in 4 of 64 values, one per band group
+	}
+	far := make([]uint32, detector.MinHashSize)
+	for i := range far {
+		far[i] = uint32(i*104729 + 3)
+	}
+
+	index := NewSimilarityIndex()
+	index.Add("gacor88.com", &models.PageSignature{MinHash: base})
+	index.Add("news.example", &models.PageSignature{MinHash: far})
+
+	matches := index.Query(&models.PageSignature{MinHash: near}, NearDuplicateThreshold)
+	if len(matches) != 1 || matches[0].Domain != "gacor88.com" {
+		t.Fatalf("Expected gacor88.com as the only near-duplicate, got %v", matches)
+	}
+	if matches[0].Similarity != float64(60)/64 {
+		t.Errorf("Expected estimated similarity 60/64, got %.3f", matches[0].Similarity)
+	}
+
+	engine := NewClusterEngine()
+	firstID := engine.AddDomainToCluster("gacor88.com", &models.AnalysisResult{
+		Domain: models.Domain{Domain: "gacor88.com", Signature: &models.PageSignature{MinHash: base}},
+	})
+	secondID := engine.AddDomainToCluster("gacor99.net", &models.AnalysisResult{
+		Domain: models.Domain{Domain: "gacor99.net", Signature: &models.PageSignature{MinHash: near}},
+	})
+
+	if firstID != secondID {
+		t.Errorf("Expected near-duplicate pages to share a cluster, got %s and %s", firstID, secondID)
+	}
+	if cluster, _ := engine.GetCluster(firstID); len(cluster.Evidence) != 1 {
+		t.Errorf("Expected near-duplicate evidence on the cluster, got %v", cluster.Evidence)
+	}
+}
+
+// TestSimilarityIndexRecall tests that near-duplicates are found as
+// candidates almost always and unrelated pages rarely, and that lower
+// thresholds still find every match
+func TestSimilarityIndexRecall(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	index := NewSimilarityIndex()
+
+	// candidateRate returns how often a page agreeing on a share of the
+	// MinHash values with a stored page shares one of its buckets
+	candidateRate := func(share float64) float64 {
+		const trials = 1000
+		found := 0
+		for trial := 0; trial < trials; trial++ {
+			stored, query := similarMinHashes(rng, share)
+			storedKeys := make(map[uint64]bool)
+			for _, key := range index.bandKeys(stored) {
+				storedKeys[key] = true
+			}
+			for _, key := range index.bandKeys(query) {
+				if storedKeys[key] {
+					found++
+					break
+				}
+			}
+		}
+		return float64(found) / trials
+	}
+
+	if rate := candidateRate(NearDuplicateThreshold); rate < 0.99 {
+		t.Errorf("Expected candidate recall of at least 0.99 at the near-duplicate threshold, got %.3f", rate)
+	}
+	if rate := candidateRate(0.3); rate > 0.2 {
+		t.Errorf("Expected few candidates at similarity 0.3, got %.3f", rate)
+	}
+	if rate := candidateRate(0.1); rate > 0.02 {
+		t.Errorf("Expected almost no candidates at similarity 0.1, got %.3f", rate)
+	}
+
+	stored, query := similarMinHashes(rng, 0.5)
+	index.Add("gacor88.com", &models.PageSignature{MinHash: stored})
+	if matches := index.Query(&models.PageSignature{MinHash: query}, 0.5); len(matches) != 1 {
+		t.Errorf("Expected a query below the LSH similarity to compare every page, got %v", matches)
+	}
+}
+
+// similarMinHashes returns two random MinHash signatures agreeing on share
+// of their values, as for pages of that Jaccard similarity
+func similarMinHashes(rng *rand.Rand, share float64) ([]uint32, []uint32) {
+	stored := make([]uint32, detector.MinHashSize)
+	for i := range stored {
+		stored[i] = rng.Uint32()
+	}
+	query := append([]uint32{}, stored...)
+	differing := detector.MinHashSize - int(share*detector.MinHashSize+0.5)
+	for _, i := range rng.Perm(detector.MinHashSize)[:differing] {
+		query[i] = rng.Uint32()
+	}
+	return stored, query
+}
+
+// TestClassifyVerticals tests that all tagged verticals are listed, strongest first
+func TestClassifyVerticals(t *testing.T) {
+	signals := []models.Signal{
+		{SignalID: "GAME_PROVIDER", Confidence: 0.9, Vertical: models.VerticalSlot},
+		{SignalID: "LIVE_CASINO_GAMES", Confidence: 0.75, Vertical: models.VerticalCasino},
+		{SignalID: "LIVE_CASINO_PROVIDERS", Confidence: 0.85, Vertical: models.VerticalCasino},
+		{SignalID: "SPORTSBOOK_BRANDS", Confidence: 0.8, Vertical: models.VerticalSportsbook},
+		{SignalID: "PAYMENT_METHODS", Confidence: 0.8},
+	}
+
+	verticals := classifyVerticals(signals)
+	expected := []string{models.VerticalCasino, models.VerticalSlot, models.VerticalSportsbook}
+	if len(verticals) != len(expected) {
+		t.Fatalf("Expected verticals %v, got %v", expected, verticals)
+	}
+	for i := range expected {
+		if verticals[i] != expected[i] {
+			t.Errorf("Expected verticals %v, got %v", expected, verticals)
+			break
+		}
+	}
+
+	summary := NewExporter().GenerateSummary([]*models.AnalysisResult{
+		{Domain: models.Domain{Domain: "a.example", Verticals: verticals}},
+		{Domain: models.Domain{Domain: "b.example", Verticals: []string{models.VerticalSlot}}},
+	})
+	distribution := summary["vertical_distribution"].(map[string]int)
+	if distribution[models.VerticalSlot] != 2 || distribution[models.VerticalCasino] != 1 {
+		t.Errorf("Expected slot on 2 domains and casino on 1, got %v", distribution)
+	}
+}
+
+// TestFraudLikelihoodIndex tests that lending signals feed the FLI and the threat type, not the JLI
+func TestFraudLikelihoodIndex(t *testing.T) {
+	cfg := config.Get()
+	evidence := []models.Evidence{{Type: "html", Reference: "test"}}
+
+	signals := []models.Signal{
+		{SignalID: "PINJOL_LOAN_OFFER", Category: "UX", Confidence: 0.7, Threat: models.ThreatPinjol, Evidence: evidence},
+		{SignalID: "PINJOL_KYC_UPLOAD", Category: "UX", Confidence: 0.8, Threat: models.ThreatPinjol, Evidence: evidence},
+		{SignalID: "FRAUD_APK_DOWNLOAD", Category: "INFRA", Confidence: 0.6, Threat: models.ThreatPinjol, Evidence: evidence},
+		{SignalID: "PAYMENT_METHODS", Category: "PAYMENT", Confidence: 0.9, Evidence: evidence},
+		{SignalID: "CDN_DETECTED", Category: "CDN", Confidence: 0.8, Evidence: evidence},
+		{SignalID: "GAMBLING_KEYWORDS", Category: "UX", Confidence: 0.3, Evidence: evidence},
+	}
+
+	gamblingSignals, fraudSignals := splitThreatSignals(signals)
+	if len(gamblingSignals) != 3 || len(fraudSignals) != 5 {
+		t.Fatalf("Expected 3 gambling and 5 fraud signals (2 shared), got %d and %d", len(gamblingSignals), len(fraudSignals))
+	}
+
+	fliScore := calculateFLIScore(fraudSignals, cfg.FraudScoring)
+	jliScore := calculateEnhancedJLIScore(calculateCategoryScoresWithSignals(gamblingSignals), cfg.Scoring, gamblingSignals, nil)
+	if fliScore < cfg.Threshold.Medium || fliScore <= jliScore {
+		t.Errorf("Expected the FLI (%.3f) to reach MEDIUM and exceed the JLI (%.3f)", fliScore, jliScore)
+	}
+	if threat := classifyThreat(jliScore, fliScore, fraudSignals, cfg.Threshold); threat != models.ThreatPinjol {
+		t.Errorf("Expected threat type pinjol, got %q", threat)
+	}
+
+	// Shared signals alone say nothing about lending or investment scams
+	_, sharedOnly := splitThreatSignals(signals[3:])
+	if score := calculateFLIScore(sharedOnly, cfg.FraudScoring); score != 0 {
+		t.Errorf("Expected FLI 0 without fraud signals, got %.3f", score)
+	}
+}
+
+// TestClassifyHost tests that injected gambling content marks a compromised host, not an operator
+func TestClassifyHost(t *testing.T) {
+	compromised := []models.Signal{
+		{SignalID: "ux_slot", Category: "UX", Confidence: 0.7},
+		{SignalID: "COMPROMISE_HIDDEN_LINKS", Category: "INFRA", Confidence: 0.85},
+	}
+	if host := classifyHost("HIGH", compromised); host != models.HostCompromised {
+		t.Errorf("Expected %s, got %q", models.HostCompromised, host)
+	}
+	if host := classifyHost("MEDIUM", compromised[:1]); host != models.HostOperator {
+		t.Errorf("Expected %s, got %q", models.HostOperator, host)
+	}
+	if host := classifyHost("LOW", compromised[:1]); host != "" {
+		t.Errorf("Expected no host classification for a LOW site, got %q", host)
+	}
+}
+
+// TestMonitorDetectsBlockStatusChange tests that the monitor records when a
+// domain gets blocked
+func TestMonitorDetectsBlockStatusChange(t *testing.T) {
+	monitor := NewMonitor()
+	before := &models.AnalysisResult{Domain: models.Domain{
+		BlockStatus: &models.BlockStatus{Status: models.BlockStatusNotBlocked, Reachable: true},
+	}}
+	after := &models.AnalysisResult{Domain: models.Domain{
+		BlockStatus: &models.BlockStatus{Status: models.BlockStatusBlocked, Method: models.BlockMethodSinkhole, Reachable: true},
+	}}
+
+	changes := monitor.detectChanges(before, after, before.Domain.BlockStatus)
+	if len(changes) != 1 || changes[0].SignalID != "block_status_change" {
+		t.Errorf("Expected a block status change, got %v", changes)
+	}
+	if changes := monitor.detectChanges(after, after, after.Domain.BlockStatus); len(changes) != 0 {
+		t.Errorf("Expected no changes, got %v", changes)
+	}
+
+	// A scan that could not check the status keeps the last one checked
+	unreachable := &models.AnalysisResult{}
+	last := CarryBlockStatus(unreachable, before.Domain.BlockStatus)
+	if last != before.Domain.BlockStatus || unreachable.Domain.BlockStatus != nil {
+		t.Errorf("Expected the last checked status to be kept, got %+v", last)
+	}
+	if changes := monitor.detectChanges(unreachable, after, last); len(changes) != 1 {
+		t.Errorf("Expected the block status change across an unchecked scan, got %v", changes)
+	}
+}
+
+// TestTemporalFactor tests that old domains score lower and that young ones,
+// scored by the registration signals, are not boosted again
+func TestTemporalFactor(t *testing.T) {
+	now := time.Date(2025, 6, 18, 0, 0, 0, 0, time.UTC)
+	registered := func(days int) *models.Registration {
+		created := now.AddDate(0, 0, -days)
+		return &models.Registration{CreatedAt: &created}
+	}
+
+	if factor := calculateTemporalFactor(nil, now); factor != 1.0 {
+		t.Errorf("Expected a neutral factor without registration data, got %.2f", factor)
+	}
+	if fresh, old := calculateTemporalFactor(registered(7), now), calculateTemporalFactor(registered(3650), now); fresh != 1.0 || old >= 1.0 {
+		t.Errorf("Expected a neutral factor for a fresh domain and one below 1.0 for an old one, got %.2f and %.2f", fresh, old)
+	}
+}
+
+// TestClusterSiblingNames tests that domains linked by sibling names share a cluster
+func TestClusterSiblingNames(t *testing.T) {
+	sibling := func(source, target string) *models.AnalysisResult {
+		return &models.AnalysisResult{Domain: models.Domain{
+			Domain:    source,
+			Relations: []models.Relation{{Source: source, Target: target, Type: models.RelationSiblingOf}},
+		}}
+	}
+
+	engine := NewClusterEngine()
+	firstID := engine.AddDomainToCluster("slot88a.com", sibling("slot88a.com", "slot88b.net"))
+	secondID := engine.AddDomainToCluster("slot88b.net", sibling("slot88b.net", "slot88a.com"))
+
+	if firstID != secondID {
+		t.Errorf("Expected sibling domains to share a cluster, got %s and %s", firstID, secondID)
+	}
+}
+
+// TestAnalyzeDomains tests that concurrent analyses keep the domain order
+func TestAnalyzeDomains(t *testing.T) {
+	original := analyzeFunc
+	defer func() { analyzeFunc = original }()
+	analyzeFunc = func(domain string, timeout time.Duration, profile string) *models.AnalysisResult {
+		return &models.AnalysisResult{Domain: models.Domain{Domain: domain}, ProfileUsed: profile}
+	}
+
+	domains := []string{"slot89a.com", "slot88b.com", "slot88a.xyz", "slot-88a.com", "s1ot88a.com"}
+	results := AnalyzeDomains(domains, time.Second, "standard", 3)
+	if len(results) != len(domains) {
+		t.Fatalf("Expected %d results, got %d", len(domains), len(results))
+	}
+	for i, domain := range domains {
+		if results[i].Domain.Domain != domain {
+			t.Errorf("Expected result %d to be %s, got %s", i, domain, results[i].Domain.Domain)
+		}
+	}
+}
@@ -9,42 +9,57 @@ import (
 	"strings"
 	"time"
 
+	"github.com/genesis410/fogger/internal/detector"
 	"github.com/genesis410/fogger/internal/models"
 )
 
 // ClusterEngine handles domain clustering and attribution
 type ClusterEngine struct {
 	Clusters map[string]*Cluster
+	Index    *SimilarityIndex
 }
 
 // Cluster represents a group of related domains
 type Cluster struct {
-	ID              string            `json:"cluster_id"`
-	Confidence      float64           `json:"confidence"`
-	Domains         []string          `json:"domains"`
-	SharedSignals   []string          `json:"shared_signals"`
-	FirstSeen       time.Time         `json:"first_seen"`
-	LastSeen        time.Time         `json:"last_seen"`
-	SharedResources map[string]string `json:"shared_resources"` // IPs, wallets, etc.
+	ID              string              `json:"cluster_id"`
+	Confidence      float64             `json:"confidence"`
+	Domains         []string            `json:"domains"`
+	SharedSignals   []string            `json:"shared_signals"`
+	FirstSeen       time.Time           `json:"first_seen"`
+	LastSeen        time.Time           `json:"last_seen"`
+	SharedResources map[string][]string `json:"shared_resources"` // IPs, wallets, contact handles, etc.
+	Evidence        []string            `json:"evidence,omitempty"`
 }
 
 // NewClusterEngine creates a new clustering engine
 func NewClusterEngine() *ClusterEngine {
 	return &ClusterEngine{
 		Clusters: make(map[string]*Cluster),
+		Index:    NewSimilarityIndex(),
 	}
 }
 
 // AddDomainToCluster adds a domain to an appropriate cluster based on similarities
 func (ce *ClusterEngine) AddDomainToCluster(domain string, analysis *models.AnalysisResult) string {
+	defer ce.Index.Add(domain, analysis.Domain.Signature)
+
 	// Calculate similarity with existing clusters
 	bestClusterID := ce.findBestCluster(analysis)
+
+	// A near-duplicate page joins the cluster of the page it copies
+	nearDuplicateID, evidence := ce.findNearDuplicateCluster(domain, analysis)
+	if nearDuplicateID != "" {
+		bestClusterID = nearDuplicateID
+	}
 	
 	if bestClusterID != "" {
 		// Add domain to existing cluster
 		cluster := ce.Clusters[bestClusterID]
 		cluster.Domains = append(cluster.Domains, domain)
 		cluster.LastSeen = time.Now()
+		if evidence != "" {
+			cluster.Evidence = append(cluster.Evidence, evidence)
+		}
 		
 		// Update shared resources if needed
 		ce.updateSharedResources(cluster, analysis)
@@ -88,6 +103,20 @@ func (ce *ClusterEngine) findBestCluster(analysis *models.AnalysisResult) string
 	return bestClusterID
 }
 
+// findNearDuplicateCluster returns the cluster of the most similar indexed
+// page, if it is a near-duplicate, along with a description of the match
+func (ce *ClusterEngine) findNearDuplicateCluster(domain string, analysis *models.AnalysisResult) (string, string) {
+	for _, match := range ce.Index.Query(analysis.Domain.Signature, NearDuplicateThreshold) {
+		if match.Domain == domain {
+			continue
+		}
+		if cluster, ok := ce.GetClusterForDomain(match.Domain); ok {
+			return cluster.ID, fmt.Sprintf("%s is a near-duplicate of %s (similarity %.2f)", domain, match.Domain, match.Similarity)
+		}
+	}
+	return "", ""
+}
+
 // calculateClusterSimilarity calculates similarity between a cluster and an analysis result
 func (ce *ClusterEngine) calculateClusterSimilarity(cluster *Cluster, analysis *models.AnalysisResult) float64 {
 	score := 0.0
@@ -109,25 +138,94 @@ func (ce *ClusterEngine) calculateClusterSimilarity(cluster *Cluster, analysis *
 		score += float64(sharedSignalCount) / float64(len(cluster.SharedSignals)) * 0.4
 	}
 	
-	// Check for shared resources
-	sharedResourceCount := 0
+	// Check for shared resources, weighted by how specific each type is to one operator
+	sharedResourceWeight := 0.0
+	totalResourceWeight := 0.0
+	sharedTracker := false
 	analysisResources := ce.extractSharedResources(analysis)
 	
-	for resType, resValue := range analysisResources {
-		if clusterRes, exists := cluster.SharedResources[resType]; exists {
-			if clusterRes == resValue {
-				sharedResourceCount++
+	for resType, resValues := range analysisResources {
+		// Mirrors are edges between two domains, not a resource both can link
+		if resType == "mirror" {
+			continue
+		}
+		weight := resourceWeight(resType)
+		for _, resValue := range resValues {
+			totalResourceWeight += weight
+			if resourceMatches(resType, cluster.SharedResources[resType], resValue) {
+				sharedResourceWeight += weight
+				if detector.IsTrackerResource(resType) {
+					sharedTracker = true
+				}
 			}
 		}
 	}
 	
-	if len(analysisResources) > 0 {
-		score += float64(sharedResourceCount) / float64(len(analysisResources)) * 0.6
+	if totalResourceWeight > 0 {
+		score += sharedResourceWeight / totalResourceWeight * 0.6
+	}
+
+	// A shared tracker account, or a member advertising the domain as its
+	// mirror, is close to conclusive attribution on its own
+	if sharedTracker || mirrorLinked(cluster, analysis) {
+		score += 0.5
+	}
+
+	if score > 1.0 {
+		score = 1.0
 	}
 	
 	return score
 }
 
+// mirrorLinked reports whether the domain and a member of the cluster
+// advertise one another as mirrors. Two sites linking the same third domain
+// are not linked: it may be a well-known site both mention.
+func mirrorLinked(cluster *Cluster, analysis *models.AnalysisResult) bool {
+	// The mirror resources of a cluster are its members and the domains they advertise
+	if containsString(cluster.SharedResources["mirror"], analysis.Domain.Domain) {
+		return true
+	}
+	for _, relation := range analysis.Domain.Relations {
+		if relation.Type == models.RelationMirrorOf && containsString(cluster.Domains, relation.Target) {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceWeight returns how strongly a shared resource of the given type
+// indicates a common operator
+func resourceWeight(resourceType string) float64 {
+	if detector.IsTrackerResource(resourceType) {
+		return 3.0
+	}
+
+	switch resourceType {
+	case "wallet", detector.ResourceLiveChat, detector.ResourceTawk, detector.ResourceJivoChat:
+		return 2.5
+	case detector.ResourceAssetSet:
+		return 2.5
+	case detector.ResourceImagePHash, detector.ResourceImageDHash:
+		return 2.0
+	case detector.ResourceFaviconPHash, detector.ResourceFaviconDHash, detector.ResourceFaviconMMH3:
+		return 1.5
+	case detector.ResourceWhatsApp, detector.ResourceTelegram, detector.ResourceLine, detector.ResourceDOMSkeleton:
+		return 2.0
+	case detector.ResourceRegistrationDay:
+		return 1.5
+	case "sibling":
+		return 2.0
+	case detector.ResourcePanel, detector.ResourceRegistrar:
+		// Commercial panels are rented, and registrars used, by many unrelated operators
+		return 0.5
+	case detector.ResourcePrivacyService:
+		return 0.3
+	default:
+		return 1.0
+	}
+}
+
 // extractSignalCategories extracts signal categories from analysis
 func (ce *ClusterEngine) extractSignalCategories(analysis *models.AnalysisResult) []string {
 	signalMap := make(map[string]bool)
@@ -160,8 +258,8 @@ func (ce *ClusterEngine) extractSharedSignals(analysis *models.AnalysisResult) [
 }
 
 // extractSharedResources extracts resources that might be shared across domains
-func (ce *ClusterEngine) extractSharedResources(analysis *models.AnalysisResult) map[string]string {
-	resources := make(map[string]string)
+func (ce *ClusterEngine) extractSharedResources(analysis *models.AnalysisResult) map[string][]string {
+	resources := make(map[string][]string)
 	
 	for _, signal := range analysis.Domain.Signals {
 		// Look for IP addresses, wallets, or other shared infrastructure
@@ -169,16 +267,34 @@ func (ce *ClusterEngine) extractSharedResources(analysis *models.AnalysisResult)
 			// Extract IP from description
 			ip := ce.extractIPFromDescription(signal.Description)
 			if ip != "" {
-				resources["ip"] = ip
+				resources["ip"] = appendUnique(resources["ip"], ip)
 			}
 		} else if signal.Category == "PAYMENT" && strings.Contains(signal.Description, "cryptocurrency address") {
 			// Extract wallet address
 			wallet := ce.extractWalletFromDescription(signal.Description)
 			if wallet != "" {
-				resources["wallet"] = wallet
+				resources["wallet"] = appendUnique(resources["wallet"], wallet)
 			}
 		}
 	}
+
+	// Typed resources extracted by the detectors (contact channels, etc.)
+	for _, resource := range analysis.Domain.Resources {
+		resources[resource.Type] = appendUnique(resources[resource.Type], resource.Value)
+	}
+
+	// A domain and the mirrors it advertises form one rotation set
+	for _, relation := range analysis.Domain.Relations {
+		if relation.Type == models.RelationMirrorOf {
+			resources["mirror"] = appendUnique(resources["mirror"], relation.Source)
+			resources["mirror"] = appendUnique(resources["mirror"], relation.Target)
+		}
+		// Sibling names are a rotation set inferred from naming alone
+		if relation.Type == models.RelationSiblingOf {
+			resources["sibling"] = appendUnique(resources["sibling"], relation.Source)
+			resources["sibling"] = appendUnique(resources["sibling"], relation.Target)
+		}
+	}
 	
 	return resources
 }
@@ -235,9 +351,9 @@ func (ce *ClusterEngine) generateClusterID(domain string, analysis *models.Analy
 func (ce *ClusterEngine) updateSharedResources(cluster *Cluster, analysis *models.AnalysisResult) {
 	newResources := ce.extractSharedResources(analysis)
 	
-	for resType, resValue := range newResources {
-		if _, exists := cluster.SharedResources[resType]; !exists {
-			cluster.SharedResources[resType] = resValue
+	for resType, resValues := range newResources {
+		for _, resValue := range resValues {
+			cluster.SharedResources[resType] = appendUnique(cluster.SharedResources[resType], resValue)
 		}
 	}
 }
@@ -293,7 +409,7 @@ func (ce *ClusterEngine) FindClustersByResource(resourceType, resourceValue stri
 	var matchingClusters []*Cluster
 	
 	for _, cluster := range ce.Clusters {
-		if res, exists := cluster.SharedResources[resourceType]; exists && res == resourceValue {
+		if resourceMatches(resourceType, cluster.SharedResources[resourceType], resourceValue) {
 			matchingClusters = append(matchingClusters, cluster)
 		}
 	}
@@ -316,7 +432,10 @@ func (ce *ClusterEngine) UpdateClusterConfidence(clusterID string) {
 	
 	domainCount := len(cluster.Domains)
 	signalCount := len(cluster.SharedSignals)
-	resourceCount := len(cluster.SharedResources)
+	resourceCount := 0
+	for _, resValues := range cluster.SharedResources {
+		resourceCount += len(resValues)
+	}
 	
 	// Base confidence on domain count (more domains = higher confidence)
 	confidence := float64(domainCount) * 0.3
@@ -374,9 +493,12 @@ func (ce *ClusterEngine) MergeClusters(clusterID1, clusterID2 string) error {
 	}
 	
 	// Merge shared resources
-	for resType, resValue := range cluster2.SharedResources {
-		cluster1.SharedResources[resType] = resValue
+	for resType, resValues := range cluster2.SharedResources {
+		for _, resValue := range resValues {
+			cluster1.SharedResources[resType] = appendUnique(cluster1.SharedResources[resType], resValue)
+		}
 	}
+	cluster1.Evidence = append(cluster1.Evidence, cluster2.Evidence...)
 	
 	// Update confidence and timestamps
 	cluster1.Confidence = (cluster1.Confidence + cluster2.Confidence) / 2
@@ -414,4 +536,37 @@ func (ce *ClusterEngine) GetClusterStatistics() map[string]interface{} {
 	}
 	
 	return stats
-}
\ No newline at end of file
+}
+
+// resourceMatches reports whether value matches one of values. Perceptual
+// hashes match when they are within the near-duplicate Hamming distance.
+func resourceMatches(resourceType string, values []string, value string) bool {
+	if !detector.IsPerceptualHashResource(resourceType) {
+		return containsString(values, value)
+	}
+
+	for _, v := range values {
+		if distance := detector.HammingDistance(v, value); distance >= 0 && distance <= detector.PerceptualHashThreshold {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// appendUnique appends value to values unless it is already present
+func appendUnique(values []string, value string) []string {
+	if containsString(values, value) {
+		return values
+	}
+	return append(values, value)
+}
@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// analyzeFunc analyzes a single domain; replaced in tests to avoid network access
+var analyzeFunc = AnalyzeDomain
+
+// DiscoverMirrors follows the MIRROR_OF relations of an analyzed seed domain
+// breadth-first and analyzes every mirror found, up to maxDepth hops from the
+// seed and maxDomains additional domains. The seed result is not included in
+// the returned results.
+func DiscoverMirrors(seed *models.AnalysisResult, timeout time.Duration, profile string, maxDepth, maxDomains int) []*models.AnalysisResult {
+	var results []*models.AnalysisResult
+
+	type queued struct {
+		domain string
+		depth  int
+	}
+
+	seen := map[string]bool{seed.Domain.Domain: true}
+	var queue []queued
+	enqueue := func(result *models.AnalysisResult, depth int) {
+		if depth > maxDepth {
+			return
+		}
+		for _, relation := range result.Domain.Relations {
+			if relation.Type != models.RelationMirrorOf || seen[relation.Target] {
+				continue
+			}
+			seen[relation.Target] = true
+			queue = append(queue, queued{domain: relation.Target, depth: depth})
+		}
+	}
+
+	enqueue(seed, 1)
+	for len(queue) > 0 && len(results) < maxDomains {
+		next := queue[0]
+		queue = queue[1:]
+
+		result := analyzeFunc(next.domain, timeout, profile)
+		results = append(results, result)
+		enqueue(result, next.depth+1)
+	}
+
+	return results
+}
+
+// AnalyzeDomains analyzes domains with up to concurrency analyses at once
+// and returns the results in domain order
+func AnalyzeDomains(domains []string, timeout time.Duration, profile string, concurrency int) []*models.AnalysisResult {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	results := make([]*models.AnalysisResult, len(domains))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = analyzeFunc(domains[i], timeout, profile)
+			}
+		}()
+	}
+	for i := range domains {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/genesis410/fogger/internal/models"
@@ -49,6 +50,7 @@ func (e *Exporter) ExportCSV(results []*models.AnalysisResult, filename string)
 		"domain", "jli_score", "jli_level", "cdn_provider", 
 		"first_seen", "last_seen", "cluster_id", "total_signals",
 		"ux_signals", "payment_signals", "infra_signals", "dns_signals", "cdn_signals",
+		"verticals", "fli_score", "threat_type",
 	}
 	
 	if err := writer.Write(header); err != nil {
@@ -76,6 +78,9 @@ func (e *Exporter) ExportCSV(results []*models.AnalysisResult, filename string)
 			fmt.Sprintf("%d", countSignalsByCategory(result.Domain.Signals, "INFRA")),
 			fmt.Sprintf("%d", countSignalsByCategory(result.Domain.Signals, "DNS")),
 			fmt.Sprintf("%d", countSignalsByCategory(result.Domain.Signals, "CDN")),
+			strings.Join(result.Domain.Verticals, ";"),
+			fmt.Sprintf("%.3f", result.FLIScore),
+			result.ThreatType,
 		}
 		
 		if err := writer.Write(row); err != nil {
@@ -198,6 +203,30 @@ func (e *Exporter) GenerateSummary(results []*models.AnalysisResult) map[string]
 		cdnCount[result.Domain.CDNProvider]++
 	}
 	
+	// Count game providers to show the provider mix across the ecosystem
+	providerCount := make(map[string]int)
+	for _, result := range results {
+		for _, provider := range result.Domain.GameProviders {
+			providerCount[provider]++
+		}
+	}
+	
+	// Count verticals so operators can be broken down by product line
+	verticalCount := make(map[string]int)
+	for _, result := range results {
+		for _, vertical := range result.Domain.Verticals {
+			verticalCount[vertical]++
+		}
+	}
+	
+	// Count threat types to split judol from lending and investment scams
+	threatCount := make(map[string]int)
+	for _, result := range results {
+		if result.ThreatType != "" {
+			threatCount[result.ThreatType]++
+		}
+	}
+	
 	// Count signal categories
 	categoryCount := make(map[string]int)
 	for _, result := range results {
@@ -214,6 +243,9 @@ func (e *Exporter) GenerateSummary(results []*models.AnalysisResult) map[string]
 	summary["average_jli_score"] = avgScore
 	summary["cdn_distribution"] = cdnCount
 	summary["signal_category_distribution"] = categoryCount
+	summary["game_provider_distribution"] = providerCount
+	summary["vertical_distribution"] = verticalCount
+	summary["threat_type_distribution"] = threatCount
 	
 	return summary
 }
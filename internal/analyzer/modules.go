@@ -0,0 +1,22 @@
+package analyzer
+
+import (
+	"context"
+
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// init registers the behavioral analysis modules; they run after the
+// scanner's built-in modules
+func init() {
+	body := []string{detector.InputBody}
+
+	detector.Register(detector.NewFuncDetector("behavioral", "UX", body, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		return NewBehavioralAnalyzer().AnalyzeContent(page.Body), nil
+	}))
+
+	detector.Register(detector.NewFuncDetector("dom_structure", "UX", body, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		return NewBehavioralAnalyzer().AnalyzeDOMStructure(page.Body), nil
+	}))
+}
@@ -23,6 +23,10 @@ type DomainMonitor struct {
 	Interval   time.Duration
 	Active     bool
 	StopChan   chan bool
+
+	// LastBlockStatus is the last block status checked. Scans that could
+	// not check it leave it in place.
+	LastBlockStatus *models.BlockStatus
 }
 
 // ChangeRecord records a change in domain analysis
@@ -64,6 +68,7 @@ func (m *Monitor) AddDomain(domain string, interval time.Duration) error {
 	// Perform initial scan
 	result := AnalyzeDomain(domain, 10*time.Second, "standard")
 	monitor.LastResult = result
+	monitor.LastBlockStatus = result.Domain.BlockStatus
 	
 	go m.runMonitor(monitor)
 	
@@ -102,10 +107,12 @@ func (m *Monitor) runMonitor(monitor *DomainMonitor) {
 			
 			// Perform analysis
 			result := AnalyzeDomain(monitor.Domain, 10*time.Second, "standard")
+			lastBlockStatus := monitor.LastBlockStatus
+			monitor.LastBlockStatus = CarryBlockStatus(result, lastBlockStatus)
 			
 			// Check for changes
 			if monitor.LastResult != nil {
-				changes := m.detectChanges(monitor.LastResult, result)
+				changes := m.detectChanges(monitor.LastResult, result, lastBlockStatus)
 				if len(changes) > 0 {
 					changeRecord := ChangeRecord{
 						Timestamp: time.Now(),
@@ -131,8 +138,21 @@ func (m *Monitor) runMonitor(monitor *DomainMonitor) {
 	}
 }
 
-// detectChanges detects changes between two analysis results
-func (m *Monitor) detectChanges(oldResult, newResult *models.AnalysisResult) []models.Signal {
+// CarryBlockStatus carries the history of the last checked block status
+// over to result and returns the status to keep for the next scan: the
+// result's, or last when the check did not run, as when the site could not
+// be reached
+func CarryBlockStatus(result *models.AnalysisResult, last *models.BlockStatus) *models.BlockStatus {
+	if result.Domain.BlockStatus == nil {
+		return last
+	}
+	result.Domain.BlockStatus.CarryHistory(last)
+	return result.Domain.BlockStatus
+}
+
+// detectChanges detects changes between two analysis results. oldBlock is
+// the last block status checked, which may be older than oldResult.
+func (m *Monitor) detectChanges(oldResult, newResult *models.AnalysisResult, oldBlock *models.BlockStatus) []models.Signal {
 	var changes []models.Signal
 	
 	// Compare signals
@@ -148,6 +168,17 @@ func (m *Monitor) detectChanges(oldResult, newResult *models.AnalysisResult) []m
 		}
 	}
 	
+	// Check for ISP block status changes
+	if newBlock := newResult.Domain.BlockStatus; oldBlock != nil && newBlock != nil &&
+		(oldBlock.Status != newBlock.Status || oldBlock.Reachable != newBlock.Reachable) {
+		changes = append(changes, models.Signal{
+			SignalID:    "block_status_change",
+			Category:    "MONITOR",
+			Description: fmt.Sprintf("Block status changed from %s to %s", blockState(oldBlock), blockState(newBlock)),
+			Confidence:  1.0,
+		})
+	}
+
 	// Check for significant score changes
 	scoreDiff := newResult.JLIScore - oldResult.JLIScore
 	if scoreDiff > 0.1 || scoreDiff < -0.1 { // 10% threshold
@@ -162,6 +193,14 @@ func (m *Monitor) detectChanges(oldResult, newResult *models.AnalysisResult) []m
 	return changes
 }
 
+// blockState describes whether a domain is blocked and reachable
+func blockState(b *models.BlockStatus) string {
+	if b.Reachable {
+		return b.Status + " (reachable)"
+	}
+	return b.Status + " (unreachable)"
+}
+
 // GetChanges returns changes for a domain
 func (m *Monitor) GetChanges(domain string) ([]ChangeRecord, error) {
 	m.mu.RLock()
@@ -0,0 +1,107 @@
+package analyzer
+
+import (
+	"sort"
+
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// NearDuplicateThreshold is the estimated Jaccard similarity above which two
+// pages are treated as copies of the same site
+const NearDuplicateThreshold = 0.8
+
+// SimilarityIndex finds near-duplicate pages with locality-sensitive hashing.
+// MinHash signatures are split into bands; domains sharing any band bucket
+// become candidates, so a query only compares against a few stored pages.
+type SimilarityIndex struct {
+	Bands      int
+	Rows       int
+	buckets    map[uint64][]string
+	signatures map[string]*models.PageSignature
+}
+
+// SimilarMatch is a stored domain similar to a queried page
+type SimilarMatch struct {
+	Domain          string  `json:"domain"`
+	Similarity      float64 `json:"similarity"`
+	TextSimHashDist int     `json:"text_simhash_distance"`
+	DOMSimHashDist  int     `json:"dom_simhash_distance"`
+}
+
+// NewSimilarityIndex creates a new LSH index with the bands of
+// detector.LSHBandKeys, which find near-duplicates as candidates with a
+// probability of about 0.9998
+func NewSimilarityIndex() *SimilarityIndex {
+	return &SimilarityIndex{
+		Bands:      detector.LSHBands,
+		Rows:       detector.LSHRows,
+		buckets:    make(map[uint64][]string),
+		signatures: make(map[string]*models.PageSignature),
+	}
+}
+
+// Add indexes the page signature of a domain
+func (si *SimilarityIndex) Add(domain string, signature *models.PageSignature) {
+	if signature == nil || len(signature.MinHash) != si.Bands*si.Rows {
+		return
+	}
+	if _, exists := si.signatures[domain]; exists {
+		return
+	}
+
+	si.signatures[domain] = signature
+	for _, key := range si.bandKeys(signature.MinHash) {
+		si.buckets[key] = append(si.buckets[key], domain)
+	}
+}
+
+// Query returns the indexed domains whose estimated similarity to signature
+// is at least threshold, most similar first. Below detector.LSHMinSimilarity
+// every indexed domain is compared, as the buckets would miss many matches.
+func (si *SimilarityIndex) Query(signature *models.PageSignature, threshold float64) []SimilarMatch {
+	var matches []SimilarMatch
+	if signature == nil || len(signature.MinHash) != si.Bands*si.Rows {
+		return matches
+	}
+
+	candidates := make(map[string]bool)
+	if threshold < detector.LSHMinSimilarity {
+		for domain := range si.signatures {
+			candidates[domain] = true
+		}
+	}
+	for _, key := range si.bandKeys(signature.MinHash) {
+		for _, domain := range si.buckets[key] {
+			candidates[domain] = true
+		}
+	}
+
+	for domain := range candidates {
+		stored := si.signatures[domain]
+		similarity := detector.MinHashSimilarity(signature.MinHash, stored.MinHash)
+		if similarity < threshold {
+			continue
+		}
+		matches = append(matches, SimilarMatch{
+			Domain:          domain,
+			Similarity:      similarity,
+			TextSimHashDist: detector.HammingDistance(signature.TextSimHash, stored.TextSimHash),
+			DOMSimHashDist:  detector.HammingDistance(signature.DOMSimHash, stored.DOMSimHash),
+		})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Similarity != matches[j].Similarity {
+			return matches[i].Similarity > matches[j].Similarity
+		}
+		return matches[i].Domain < matches[j].Domain
+	})
+
+	return matches
+}
+
+// bandKeys returns the bucket key of each band of a MinHash signature
+func (si *SimilarityIndex) bandKeys(minHash []uint32) []uint64 {
+	return detector.LSHBandKeys(minHash, si.Bands, si.Rows)
+}
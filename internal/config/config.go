@@ -17,16 +17,128 @@ type ScoringConfig struct {
 	CDNPattern       float64 `mapstructure:"cdn_pattern"`
 }
 
+// FraudScoringConfig holds the weights of the Fraud Likelihood Index (FLI)
+// for illegal lending and investment scam sites
+type FraudScoringConfig struct {
+	Content float64 `mapstructure:"content"`
+	Payment float64 `mapstructure:"payment"`
+	Infra   float64 `mapstructure:"infra"`
+	DNS     float64 `mapstructure:"dns"`
+	CDN     float64 `mapstructure:"cdn"`
+}
+
 // ThresholdConfig holds the thresholds for classification
 type ThresholdConfig struct {
 	High   float64 `mapstructure:"high"`
 	Medium float64 `mapstructure:"medium"`
 }
 
+// CatalogConfig holds paths to data files that replace the built-in catalogs
+type CatalogConfig struct {
+	GameProviders   string `mapstructure:"game_providers"`
+	Panels          string `mapstructure:"panels"`
+	FraudRules      string `mapstructure:"fraud_rules"`
+	Subdomains      string `mapstructure:"subdomains"`
+	CDNRanges       string `mapstructure:"cdn_ranges"`
+	CDNFingerprints string `mapstructure:"cdn_fingerprints"`
+}
+
+// DiscoveryConfig holds the limits for following discovered domains
+type DiscoveryConfig struct {
+	MirrorDepth int `mapstructure:"mirror_depth"`
+	MaxDomains  int `mapstructure:"max_domains"`
+}
+
+// ImageConfig holds the limits for fetching page images for visual hashing
+type ImageConfig struct {
+	MaxImages int `mapstructure:"max_images"`
+}
+
+// StoreConfig holds the location of the local result store
+type StoreConfig struct {
+	Path string `mapstructure:"path"`
+}
+
+// ModuleConfig selects the detection modules run on each scan
+type ModuleConfig struct {
+	Enabled []string `mapstructure:"enabled"`
+	Skip    []string `mapstructure:"skip"`
+}
+
+// CompromiseConfig controls the check for legitimate sites hosting injected
+// gambling pages
+type CompromiseConfig struct {
+	Enabled   bool     `mapstructure:"enabled"`
+	Suffixes  []string `mapstructure:"suffixes"`
+	MaxProbes int      `mapstructure:"max_probes"`
+}
+
+// DNSConfig selects the resolver for DNS lookups, as a resolver.New spec
+type DNSConfig struct {
+	Server string `mapstructure:"server"`
+}
+
+// BlockCheckConfig sets up the check of whether Indonesian ISPs block a
+// domain, comparing an ISP resolver with a neutral one
+type BlockCheckConfig struct {
+	Enabled         bool     `mapstructure:"enabled"`
+	ISPResolver     string   `mapstructure:"isp_resolver"`
+	NeutralResolver string   `mapstructure:"neutral_resolver"`
+	BlockIPs        []string `mapstructure:"block_ips"`
+}
+
+// RDAPConfig selects where domain registrations are looked up
+type RDAPConfig struct {
+	BaseURL         string   `mapstructure:"base_url"`
+	WhoisServer     string   `mapstructure:"whois_server"`
+	CacheHours      int      `mapstructure:"cache_hours"`
+	RiskyRegistrars []string `mapstructure:"risky_registrars"`
+}
+
+// SubdomainConfig controls subdomain enumeration
+type SubdomainConfig struct {
+	// CTURL is the crt.sh-compatible certificate transparency search; empty
+	// disables it
+	CTURL       string `mapstructure:"ct_url"`
+	Concurrency int    `mapstructure:"concurrency"`
+}
+
+// PermuteConfig controls the generation and checking of candidate mirror
+// domains by fogger permute
+type PermuteConfig struct {
+	TLDs          []string `mapstructure:"tlds"`
+	MaxCandidates int      `mapstructure:"max_candidates"`
+	Concurrency   int      `mapstructure:"concurrency"`
+	// SkipModules are skipped when quick-scanning live candidates
+	SkipModules []string `mapstructure:"skip_modules"`
+}
+
+// PluginConfig describes an external detector executable
+type PluginConfig struct {
+	Name     string   `mapstructure:"name"`
+	Command  string   `mapstructure:"command"`
+	Args     []string `mapstructure:"args"`
+	Category string   `mapstructure:"category"`
+	Timeout  int      `mapstructure:"timeout"`
+}
+
 // Config holds the complete configuration
 type Config struct {
-	Scoring   ScoringConfig   `mapstructure:"scoring"`
-	Threshold ThresholdConfig `mapstructure:"thresholds"`
+	Scoring      ScoringConfig      `mapstructure:"scoring"`
+	FraudScoring FraudScoringConfig `mapstructure:"fraud_scoring"`
+	Threshold    ThresholdConfig    `mapstructure:"thresholds"`
+	Catalogs     CatalogConfig      `mapstructure:"catalogs"`
+	Discovery    DiscoveryConfig    `mapstructure:"discovery"`
+	Images       ImageConfig        `mapstructure:"images"`
+	Store        StoreConfig        `mapstructure:"store"`
+	Modules      ModuleConfig       `mapstructure:"modules"`
+	Compromise   CompromiseConfig   `mapstructure:"compromise"`
+	DNS          DNSConfig          `mapstructure:"dns"`
+	BlockCheck   BlockCheckConfig   `mapstructure:"block_check"`
+	RDAP         RDAPConfig         `mapstructure:"rdap"`
+	Subdomains   SubdomainConfig    `mapstructure:"subdomains"`
+	Permute      PermuteConfig      `mapstructure:"permute"`
+	Plugins      []PluginConfig     `mapstructure:"plugins"`
 }
 
 var (
@@ -43,17 +155,67 @@ func Initialize() {
 		viper.SetDefault("scoring.domain_churn", 0.15)
 		viper.SetDefault("scoring.cdn_pattern", 0.10)
 
+		viper.SetDefault("fraud_scoring.content", 0.45)
+		viper.SetDefault("fraud_scoring.payment", 0.25)
+		viper.SetDefault("fraud_scoring.infra", 0.15)
+		viper.SetDefault("fraud_scoring.dns", 0.05)
+		viper.SetDefault("fraud_scoring.cdn", 0.10)
+
 		viper.SetDefault("thresholds.high", 0.75)
 		viper.SetDefault("thresholds.medium", 0.50)
 
-		// Read in configuration from file
-		viper.SetConfigName(".fogger")
-		viper.SetConfigType("yaml")
-		viper.AddConfigPath("$HOME")
-		viper.AddConfigPath(".")
+		viper.SetDefault("catalogs.game_providers", "")
+		viper.SetDefault("catalogs.panels", "")
+		viper.SetDefault("catalogs.fraud_rules", "")
+		viper.SetDefault("catalogs.subdomains", "")
+		viper.SetDefault("catalogs.cdn_ranges", "")
+		viper.SetDefault("catalogs.cdn_fingerprints", "")
+
+		viper.SetDefault("discovery.mirror_depth", 0)
+		viper.SetDefault("discovery.max_domains", 25)
+
+		viper.SetDefault("images.max_images", 5)
+
+		viper.SetDefault("store.path", "")
+
+		viper.SetDefault("modules.enabled", []string{})
+		viper.SetDefault("modules.skip", []string{})
+
+		viper.SetDefault("compromise.enabled", false)
+		viper.SetDefault("compromise.suffixes", []string{"go.id", "ac.id", "sch.id", "mil.id", "desa.id"})
+		viper.SetDefault("compromise.max_probes", 5)
+
+		viper.SetDefault("dns.server", "")
+
+		viper.SetDefault("block_check.enabled", false)
+		viper.SetDefault("block_check.isp_resolver", "system")
+		viper.SetDefault("block_check.neutral_resolver", "https://dns.google/dns-query")
+		viper.SetDefault("block_check.block_ips", []string{})
+
+		viper.SetDefault("rdap.base_url", "")
+		viper.SetDefault("rdap.whois_server", "")
+		viper.SetDefault("rdap.cache_hours", 168)
+		viper.SetDefault("rdap.risky_registrars", []string{})
+
+		viper.SetDefault("subdomains.ct_url", "https://crt.sh/")
+		viper.SetDefault("subdomains.concurrency", 20)
+
+		viper.SetDefault("permute.tlds", []string{"com", "net", "org", "xyz", "site", "online", "vip", "top", "live", "pro", "club", "info"})
+		viper.SetDefault("permute.max_candidates", 500)
+		viper.SetDefault("permute.concurrency", 20)
+		viper.SetDefault("permute.skip_modules", []string{"visual", "churn", "dns", "registration", "block_status", "compromise", "subdomains", "origin_ip"})
+
+		// Read in configuration from file, unless the command line already
+		// loaded one (--config)
+		if viper.ConfigFileUsed() == "" {
+			viper.SetConfigName(".fogger")
+			viper.SetConfigType("yaml")
+			viper.AddConfigPath("$HOME")
+			viper.AddConfigPath(".")
 
-		if err := viper.ReadInConfig(); err != nil {
-			fmt.Printf("Config file not found, using defaults: %v\n", err)
+			if err := viper.ReadInConfig(); err != nil {
+				fmt.Printf("Config file not found, using defaults: %v\n", err)
+			}
 		}
 
 		config = &Config{}
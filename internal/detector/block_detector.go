@@ -0,0 +1,193 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/resolver"
+)
+
+// BlockDetector checks whether Indonesian ISPs block a domain by comparing
+// the answers of an ISP resolver with those of a neutral resolver. ISPs
+// enforcing the Internet Positif / TrustPositif list answer blocked domains
+// with the address of a block page, or with no address at all.
+type BlockDetector struct {
+	ISP     resolver.Resolver
+	Neutral resolver.Resolver
+	// Client fetches pages from a resolved address; it does not follow
+	// redirects, since block pages often redirect to the block list site
+	Client *http.Client
+	// BlockIPs are addresses of ISP block pages
+	BlockIPs map[string]bool
+	// BlockHosts are names of block page hosts an ISP answer may CNAME to
+	BlockHosts []string
+	// BlockPageMarkers are lowercase phrases of block pages and their redirects
+	BlockPageMarkers []string
+}
+
+// NewBlockDetector creates a new block detector querying through isp and neutral
+func NewBlockDetector(isp, neutral resolver.Resolver, timeout time.Duration) *BlockDetector {
+	return &BlockDetector{
+		ISP:     isp,
+		Neutral: neutral,
+		Client: &http.Client{
+			Timeout: timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		// Block page addresses reported for Telkom (IndiHome); add others
+		// through block_check.block_ips
+		BlockIPs: map[string]bool{
+			"36.86.63.182": true,
+			"36.86.63.185": true,
+		},
+		BlockHosts: []string{"internetpositif.id", "trustpositif.kominfo.go.id", "trustpositif.komdigi.go.id", "aduankonten.id"},
+		BlockPageMarkers: []string{
+			"internetpositif", "internet positif", "trustpositif", "trust positif",
+			"aduankonten", "nawala", "situs ini diblokir", "situs yang anda tuju diblokir",
+			"konten negatif",
+		},
+	}
+}
+
+// CheckBlock resolves a domain through both resolvers and works out whether
+// the ISP blocks it and whether the site still answers at its real address
+func (bd *BlockDetector) CheckBlock(ctx context.Context, domain string) (*models.BlockStatus, error) {
+	status := &models.BlockStatus{
+		Status:          models.BlockStatusUnknown,
+		ISPResolver:     bd.ISP.Name(),
+		NeutralResolver: bd.Neutral.Name(),
+		CheckedAt:       time.Now(),
+	}
+
+	neutral, err := bd.Neutral.Lookup(ctx, domain, "A")
+	if err != nil {
+		return status, err
+	}
+	isp, err := bd.ISP.Lookup(ctx, domain, "A")
+	if err != nil {
+		return status, err
+	}
+	status.NeutralAnswers = blockAnswers(neutral)
+	status.ISPAnswers = blockAnswers(isp)
+	neutralIPs := DNSValues(neutral, "A")
+	ispIPs := DNSValues(isp, "A")
+
+	switch {
+	case len(neutralIPs) == 0:
+		// Nothing to compare with; the domain may simply be gone
+	case bd.sinkholed(isp) != "":
+		status.Status, status.Method = models.BlockStatusBlocked, models.BlockMethodSinkhole
+		status.Evidence = fmt.Sprintf("%s answers %s, a known block page", bd.ISP.Name(), bd.sinkholed(isp))
+	case len(ispIPs) == 0:
+		status.Status, status.Method = models.BlockStatusBlocked, models.BlockMethodNXDomain
+		status.Evidence = fmt.Sprintf("%s gives no address while %s resolves to %s",
+			bd.ISP.Name(), bd.Neutral.Name(), strings.Join(neutralIPs, ", "))
+	case overlaps(ispIPs, neutralIPs):
+		status.Status = models.BlockStatusNotBlocked
+	default:
+		// Different answers are common with CDNs; only the block page
+		// content tells a block apart from geo-routing
+		status.Status = models.BlockStatusNotBlocked
+		for _, ip := range ispIPs {
+			if _, marker := bd.probe(ctx, ip, domain); marker != "" {
+				status.Status, status.Method = models.BlockStatusBlocked, models.BlockMethodBlockPage
+				status.Evidence = fmt.Sprintf("%s answers %s, which serves a block page (%q)", bd.ISP.Name(), ip, marker)
+				break
+			}
+		}
+	}
+
+	// The site is reachable when its real address answers with anything but
+	// a block page
+	for _, ip := range neutralIPs {
+		if answered, marker := bd.probe(ctx, ip, domain); answered && marker == "" && !bd.BlockIPs[ip] {
+			status.Reachable = true
+			status.LastReachableAt = &status.CheckedAt
+			break
+		}
+	}
+	if status.Status == models.BlockStatusBlocked {
+		status.BlockedSince = &status.CheckedAt
+	}
+
+	return status, nil
+}
+
+// sinkholed returns the block page address or host in ISP answers, if any
+func (bd *BlockDetector) sinkholed(records []models.DNSRecord) string {
+	for _, record := range records {
+		value := strings.ToLower(record.Value)
+		switch record.Type {
+		case "A":
+			if bd.BlockIPs[value] {
+				return value
+			}
+		case "CNAME":
+			for _, host := range bd.BlockHosts {
+				if value == host || strings.HasSuffix(value, "."+host) {
+					return value
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// probe fetches a domain from ip, reporting whether it answered and the
+// block page marker found in the redirect target or body, if any
+func (bd *BlockDetector) probe(ctx context.Context, ip, domain string) (answered bool, marker string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+net.JoinHostPort(ip, "80")+"/", nil)
+	if err != nil {
+		return false, ""
+	}
+	req.Host = domain
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36")
+
+	resp, err := bd.Client.Do(req)
+	if err != nil {
+		return false, ""
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 256<<10))
+	content := strings.ToLower(resp.Header.Get("Location") + "\n" + string(body))
+	for _, marker := range bd.BlockPageMarkers {
+		if strings.Contains(content, marker) {
+			return true, marker
+		}
+	}
+	return true, ""
+}
+
+// blockAnswers formats address and CNAME answers as "TYPE value"
+func blockAnswers(records []models.DNSRecord) []string {
+	var answers []string
+	for _, record := range records {
+		if record.Type == "A" || record.Type == "CNAME" {
+			answers = appendUniqueString(answers, record.Type+" "+strings.ToLower(record.Value))
+		}
+	}
+	sort.Strings(answers)
+	return answers
+}
+
+// overlaps reports whether two lists share a value
+func overlaps(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package detector
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/resolver"
+	"github.com/genesis410/fogger/internal/resolver/resolvertest"
+)
+
+// TestCheckBlock tests sinkholed, block page, missing and normal ISP answers
+func TestCheckBlock(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><title>GACOR88 Slot Online</title></html>"))
+	}))
+	defer site.Close()
+	blockPage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body>Maaf, situs ini diblokir. Internet Positif</body></html>"))
+	}))
+	defer blockPage.Close()
+
+	// Route each resolved address to a local server
+	routes := map[string]string{
+		"203.0.113.10:80": site.Listener.Addr().String(),
+		"198.51.100.1:80": blockPage.Listener.Addr().String(),
+	}
+	client := &http.Client{
+		Timeout: time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, routes[addr])
+			},
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	neutral, err := resolvertest.NewServer([]models.DNSRecord{
+		{Name: "*.xyz", Type: "A", TTL: 60, Value: "203.0.113.10"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start DNS server: %v", err)
+	}
+	defer neutral.Close()
+	isp, err := resolvertest.NewServer([]models.DNSRecord{
+		{Name: "sinkholed.xyz", Type: "A", TTL: 60, Value: "36.86.63.185"},
+		{Name: "blockpage.xyz", Type: "A", TTL: 60, Value: "198.51.100.1"},
+		{Name: "open.xyz", Type: "A", TTL: 60, Value: "203.0.113.10"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start DNS server: %v", err)
+	}
+	defer isp.Close()
+
+	bd := NewBlockDetector(resolver.NewServer(isp.Addr, false, time.Second), resolver.NewServer(neutral.Addr, false, time.Second), time.Second)
+	bd.Client = client
+
+	for domain, expected := range map[string][2]string{
+		"sinkholed.xyz": {models.BlockStatusBlocked, models.BlockMethodSinkhole},
+		"blockpage.xyz": {models.BlockStatusBlocked, models.BlockMethodBlockPage},
+		"missing.xyz":   {models.BlockStatusBlocked, models.BlockMethodNXDomain},
+		"open.xyz":      {models.BlockStatusNotBlocked, ""},
+	} {
+		status, err := bd.CheckBlock(context.Background(), domain)
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %v", domain, err)
+			continue
+		}
+		if status.Status != expected[0] || status.Method != expected[1] {
+			t.Errorf("Expected %s to be %s (%s), got %s (%s)", domain, expected[0], expected[1], status.Status, status.Method)
+		}
+		if !status.Reachable || status.LastReachableAt == nil {
+			t.Errorf("Expected %s to be reachable at its real address, got %+v", domain, status)
+		}
+		if (status.Status == models.BlockStatusBlocked) != (status.BlockedSince != nil) {
+			t.Errorf("Expected a block time only for blocked %s, got %+v", domain, status)
+		}
+	}
+}
+
+// TestBlockStatusCarryHistory tests that block and reachability times carry
+// over between checks
+func TestBlockStatusCarryHistory(t *testing.T) {
+	blocked := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	reachable := blocked.Add(72 * time.Hour)
+	previous := &models.BlockStatus{Status: models.BlockStatusBlocked, BlockedSince: &blocked, Reachable: true, LastReachableAt: &reachable}
+
+	now := reachable.Add(24 * time.Hour)
+	current := &models.BlockStatus{Status: models.BlockStatusBlocked, BlockedSince: &now, CheckedAt: now}
+	current.CarryHistory(previous)
+
+	if !current.BlockedSince.Equal(blocked) {
+		t.Errorf("Expected the first block time to carry over, got %v", current.BlockedSince)
+	}
+	if after := current.ReachableAfterBlock(); after != 72*time.Hour {
+		t.Errorf("Expected the site to have stayed reachable 72h after the block, got %v", after)
+	}
+}
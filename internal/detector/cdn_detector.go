@@ -1,15 +1,18 @@
 package detector
 
 import (
-	"crypto/tls"
+	"io"
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/genesis410/fogger/internal/models"
 )
 
 // CDNDetector provides advanced CDN detection capabilities
 type CDNDetector struct {
 	Client *http.Client
+	Engine *CDNEngine
 }
 
 // NewCDNDetector creates a new instance of CDNDetector
@@ -20,6 +23,7 @@ func NewCDNDetector() *CDNDetector {
 	
 	return &CDNDetector{
 		Client: client,
+		Engine: DefaultCDNEngine(),
 	}
 }
 
@@ -28,6 +32,13 @@ type CDNInfo struct {
 	Name     string            `json:"name"`
 	Version  string            `json:"version"`
 	Features map[string]string `json:"features"`
+	// Confidence combines the confidence of the named CDN's matches
+	Confidence float64 `json:"confidence"`
+	// Matches are the fingerprints found, of every provider
+	Matches []models.CDNMatch `json:"matches,omitempty"`
+	// CNAMEChains are the CNAME chains of the host, matched against the
+	// cnames fingerprints
+	CNAMEChains []models.CNAMEChain `json:"cname_chains,omitempty"`
 }
 
 // DetectCDN identifies which CDN is being used by a domain
@@ -54,138 +65,13 @@ func (c *CDNDetector) DetectCDN(domain string) *CDNInfo {
 
 // analyzeResponse analyzes HTTP response to detect CDN
 func (c *CDNDetector) analyzeResponse(resp *http.Response) *CDNInfo {
-	headers := resp.Header
-	cdnInfo := &CDNInfo{
-		Name:     "none",
-		Features: make(map[string]string),
-	}
-
-	// Check for Cloudflare
-	if c.isCloudflare(headers, resp.TLS) {
-		cdnInfo.Name = "cloudflare"
-		cdnInfo.Features["server"] = headers.Get("server")
-		cdnInfo.Features["cf-ray"] = headers.Get("cf-ray")
-		cdnInfo.Features["cf-request-id"] = headers.Get("cf-request-id")
-		cdnInfo.Features["cf-cache-status"] = headers.Get("cf-cache-status")
-		return cdnInfo
-	}
-
-	// Check for CloudFront
-	if c.isCloudFront(headers) {
-		cdnInfo.Name = "cloudfront"
-		cdnInfo.Features["x-cache"] = headers.Get("x-cache")
-		cdnInfo.Features["x-amz-cf-pop"] = headers.Get("x-amz-cf-pop")
-		cdnInfo.Features["x-amz-cf-id"] = headers.Get("x-amz-cf-id")
-		return cdnInfo
-	}
-
-	// Check for Akamai
-	if c.isAkamai(headers) {
-		cdnInfo.Name = "akamai"
-		cdnInfo.Features["server"] = headers.Get("server")
-		cdnInfo.Features["x-akamai-transformed"] = headers.Get("x-akamai-transformed")
-		return cdnInfo
-	}
-
-	// Check for other common CDNs
-	if c.isFastly(headers) {
-		cdnInfo.Name = "fastly"
-		cdnInfo.Features["x-served-by"] = headers.Get("x-served-by")
-		cdnInfo.Features["x-cache"] = headers.Get("x-cache")
-		return cdnInfo
-	}
-
-	if c.isSquarespace(headers) {
-		cdnInfo.Name = "squarespace"
-		cdnInfo.Features["server"] = headers.Get("server")
-		cdnInfo.Features["x-served-by"] = headers.Get("x-served-by")
-		return cdnInfo
-	}
-
-	if c.isNetlify(headers) {
-		cdnInfo.Name = "netlify"
-		cdnInfo.Features["server"] = headers.Get("server")
-		cdnInfo.Features["x-nf-request-id"] = headers.Get("x-nf-request-id")
-		return cdnInfo
-	}
-
-	if c.isGithubPages(headers) {
-		cdnInfo.Name = "github-pages"
-		cdnInfo.Features["x-github-request-id"] = headers.Get("x-github-request-id")
-		cdnInfo.Features["x-proxy-response"] = headers.Get("x-proxy-response")
-		return cdnInfo
-	}
-
-	return cdnInfo
-}
-
-// isCloudflare checks for Cloudflare-specific indicators
-func (c *CDNDetector) isCloudflare(headers http.Header, tlsState *tls.ConnectionState) bool {
-	// Check headers
-	if headers.Get("server") == "cloudflare" ||
-		headers.Get("cf-ray") != "" ||
-		headers.Get("cf-request-id") != "" ||
-		strings.Contains(headers.Get("via"), "cloudflare") {
-		return true
-	}
-
-	// Check TLS certificate for Cloudflare indicators
-	if tlsState != nil {
-		for _, cert := range tlsState.PeerCertificates {
-			if strings.Contains(strings.ToLower(cert.Subject.CommonName), "cloudflaressl") ||
-				strings.Contains(strings.ToLower(cert.Subject.CommonName), "sni.cloudflaressl.com") {
-				return true
-			}
-			for _, name := range cert.DNSNames {
-				if strings.Contains(strings.ToLower(name), "cloudflare") {
-					return true
-				}
-			}
-		}
-	}
-
-	return false
-}
-
-// isCloudFront checks for CloudFront-specific indicators
-func (c *CDNDetector) isCloudFront(headers http.Header) bool {
-	return strings.Contains(headers.Get("x-cache"), "CloudFront") ||
-		headers.Get("x-amz-cf-pop") != "" ||
-		headers.Get("x-amz-cf-id") != "" ||
-		strings.Contains(headers.Get("via"), "CloudFront")
-}
-
-// isAkamai checks for Akamai-specific indicators
-func (c *CDNDetector) isAkamai(headers http.Header) bool {
-	return headers.Get("server") == "AkamaiGHost" ||
-		headers.Get("x-akamai-transformed") != "" ||
-		strings.Contains(headers.Get("via"), "akamai")
-}
-
-// isFastly checks for Fastly-specific indicators
-func (c *CDNDetector) isFastly(headers http.Header) bool {
-	return headers.Get("x-served-by") != "" ||
-		headers.Get("x-cache") != "" ||
-		strings.Contains(headers.Get("via"), "fastly")
-}
-
-// isSquarespace checks for Squarespace-specific indicators
-func (c *CDNDetector) isSquarespace(headers http.Header) bool {
-	return strings.Contains(headers.Get("server"), "Squarespace") ||
-		headers.Get("x-served-by") != ""
-}
-
-// isNetlify checks for Netlify-specific indicators
-func (c *CDNDetector) isNetlify(headers http.Header) bool {
-	return strings.Contains(headers.Get("server"), "Netlify") ||
-		headers.Get("x-nf-request-id") != ""
-}
-
-// isGithubPages checks for GitHub Pages-specific indicators
-func (c *CDNDetector) isGithubPages(headers http.Header) bool {
-	return strings.Contains(headers.Get("server"), "GitHub.com") ||
-		headers.Get("x-github-request-id") != "" ||
-		headers.Get("x-proxy-response") != ""
+	// Error and challenge pages are small; the start of the body is enough
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	return c.Engine.Identify(CDNObservation{
+		Headers: resp.Header,
+		TLS:     resp.TLS,
+		Body:    string(body),
+	})
 }
 
 // GetCDNFingerprint returns detailed fingerprint of CDN usage
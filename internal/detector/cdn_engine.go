@@ -0,0 +1,279 @@
+package detector
+
+import (
+	"context"
+	"crypto/tls"
+	_ "embed"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/resolver"
+)
+
+//go:embed data/cdn_fingerprints.yaml
+var defaultCDNFingerprints []byte
+
+// CDNRule is one fingerprint of a provider and the confidence a match gives
+type CDNRule struct {
+	// Name is a header name, a cookie name prefix or a range provider name
+	Name string `yaml:"name,omitempty"`
+	// Pattern is a case-insensitive regular expression for header values,
+	// certificate issuers and error pages
+	Pattern string `yaml:"pattern,omitempty"`
+	// Suffix is a CNAME target suffix
+	Suffix     string  `yaml:"suffix,omitempty"`
+	Confidence float64 `yaml:"confidence"`
+
+	re *regexp.Regexp
+}
+
+// CDNFingerprint holds the fingerprints of one CDN
+type CDNFingerprint struct {
+	Name       string    `yaml:"name"`
+	Headers    []CDNRule `yaml:"headers"`
+	Cookies    []CDNRule `yaml:"cookies"`
+	CNAMEs     []CDNRule `yaml:"cnames"`
+	IPRanges   []CDNRule `yaml:"ip_ranges"`
+	TLSIssuers []CDNRule `yaml:"tls_issuers"`
+	ErrorPages []CDNRule `yaml:"error_pages"`
+}
+
+// CDNFingerprintCatalog holds the known CDN fingerprints
+type CDNFingerprintCatalog struct {
+	Providers []CDNFingerprint `yaml:"providers"`
+}
+
+// LoadCDNFingerprints reads a CDN fingerprint catalog. If path is empty the
+// built-in catalog is used.
+func LoadCDNFingerprints(path string) (*CDNFingerprintCatalog, error) {
+	data := defaultCDNFingerprints
+	if path != "" {
+		fileData, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CDN fingerprints: %v", err)
+		}
+		data = fileData
+	}
+	return ParseCDNFingerprints(data)
+}
+
+// ParseCDNFingerprints parses a YAML CDN fingerprint catalog and compiles
+// its patterns
+func ParseCDNFingerprints(data []byte) (*CDNFingerprintCatalog, error) {
+	catalog := &CDNFingerprintCatalog{}
+	if err := yaml.Unmarshal(data, catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse CDN fingerprints: %v", err)
+	}
+	for i := range catalog.Providers {
+		provider := &catalog.Providers[i]
+		for _, rules := range [][]CDNRule{provider.Headers, provider.TLSIssuers, provider.ErrorPages} {
+			for j := range rules {
+				if rules[j].Pattern == "" {
+					continue
+				}
+				re, err := regexp.Compile("(?i)" + rules[j].Pattern)
+				if err != nil {
+					return nil, fmt.Errorf("invalid pattern %q of %s: %v", rules[j].Pattern, provider.Name, err)
+				}
+				rules[j].re = re
+			}
+		}
+	}
+	return catalog, nil
+}
+
+// maxCNAMEHops bounds the CNAME chains followed
+const maxCNAMEHops = 8
+
+// CDNObservation is what is known about how a host is served
+type CDNObservation struct {
+	Headers     http.Header
+	TLS         *tls.ConnectionState
+	Body        string
+	CNAMEChains []models.CNAMEChain
+	Addresses   []string
+}
+
+// FollowCNAMEChain follows the CNAME records of name to the last target.
+// The chain has no targets when name has no CNAME record.
+func FollowCNAMEChain(ctx context.Context, r resolver.Resolver, name string) (models.CNAMEChain, error) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	chain := models.CNAMEChain{Name: name}
+	seen := map[string]bool{name: true}
+	current := name
+	for len(chain.Targets) < maxCNAMEHops {
+		records, err := r.Lookup(ctx, current, "CNAME")
+		if err != nil {
+			return chain, err
+		}
+		next := ""
+		for _, record := range records {
+			if record.Type == "CNAME" && strings.EqualFold(record.Name, current) {
+				next = strings.ToLower(strings.TrimSuffix(record.Value, "."))
+				break
+			}
+		}
+		if next == "" {
+			return chain, nil
+		}
+		if seen[next] {
+			return chain, fmt.Errorf("CNAME loop at %s", next)
+		}
+		seen[next] = true
+		chain.Targets = append(chain.Targets, next)
+		current = next
+	}
+	return chain, nil
+}
+
+// CDNEngine identifies CDNs by evaluating a fingerprint catalog against
+// responses, DNS answers and addresses
+type CDNEngine struct {
+	Catalog *CDNFingerprintCatalog
+	// Ranges resolve the ip_ranges fingerprints
+	Ranges *IPRanges
+	// MinConfidence is the combined confidence a provider needs to be named
+	MinConfidence float64
+}
+
+// NewCDNEngine creates an engine evaluating catalog, with addresses looked
+// up in ranges
+func NewCDNEngine(catalog *CDNFingerprintCatalog, ranges *IPRanges) *CDNEngine {
+	return &CDNEngine{
+		Catalog:       catalog,
+		Ranges:        ranges,
+		MinConfidence: 0.5,
+	}
+}
+
+// DefaultCDNEngine creates an engine with the built-in fingerprints and ranges
+func DefaultCDNEngine() *CDNEngine {
+	catalog, _ := LoadCDNFingerprints("")
+	rangeCatalog, _ := LoadCDNRanges("")
+	ranges, _ := NewIPRanges(rangeCatalog)
+	return NewCDNEngine(catalog, ranges)
+}
+
+// Match returns every fingerprint the observation matches, in catalog order
+func (e *CDNEngine) Match(obs CDNObservation) []models.CDNMatch {
+	var cookies []*http.Cookie
+	if obs.Headers != nil {
+		cookies = (&http.Response{Header: obs.Headers}).Cookies()
+	}
+	var issuer string
+	if obs.TLS != nil && len(obs.TLS.PeerCertificates) > 0 {
+		issuer = obs.TLS.PeerCertificates[0].Issuer.String()
+	}
+
+	var matches []models.CDNMatch
+	for _, provider := range e.Catalog.Providers {
+		add := func(kind, reference string, confidence float64) {
+			matches = append(matches, models.CDNMatch{
+				Provider:   provider.Name,
+				Kind:       kind,
+				Reference:  reference,
+				Confidence: confidence,
+			})
+		}
+
+		for _, rule := range provider.Headers {
+			for _, value := range obs.Headers.Values(rule.Name) {
+				if rule.re == nil || rule.re.MatchString(value) {
+					add(models.CDNMatchHeader, fmt.Sprintf("%s: %s", strings.ToLower(rule.Name), value), rule.Confidence)
+					break
+				}
+			}
+		}
+		for _, rule := range provider.Cookies {
+			for _, cookie := range cookies {
+				if strings.HasPrefix(strings.ToLower(cookie.Name), strings.ToLower(rule.Name)) {
+					add(models.CDNMatchCookie, "cookie "+cookie.Name, rule.Confidence)
+					break
+				}
+			}
+		}
+		for _, rule := range provider.CNAMEs {
+			if chain, ok := matchCNAMEChain(obs.CNAMEChains, rule.Suffix); ok {
+				add(models.CDNMatchCNAME, "CNAME chain "+chain.String(), rule.Confidence)
+			}
+		}
+		for _, rule := range provider.IPRanges {
+			for _, address := range obs.Addresses {
+				if rangeProvider := e.Ranges.Lookup(address); rangeProvider != nil && rangeProvider.Name == rule.Name {
+					add(models.CDNMatchIPRange, fmt.Sprintf("address %s in %s ranges", address, rule.Name), rule.Confidence)
+					break
+				}
+			}
+		}
+		for _, rule := range provider.TLSIssuers {
+			if issuer != "" && rule.re != nil && rule.re.MatchString(issuer) {
+				add(models.CDNMatchTLSIssuer, "certificate issuer "+issuer, rule.Confidence)
+			}
+		}
+		for _, rule := range provider.ErrorPages {
+			if obs.Body != "" && rule.re != nil {
+				if found := rule.re.FindString(obs.Body); found != "" {
+					add(models.CDNMatchErrorPage, fmt.Sprintf("page contains %q", found), rule.Confidence)
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// matchCNAMEChain returns the first chain with a target under suffix
+func matchCNAMEChain(chains []models.CNAMEChain, suffix string) (models.CNAMEChain, bool) {
+	suffix = strings.ToLower(strings.Trim(suffix, "."))
+	for _, chain := range chains {
+		for _, target := range chain.Targets {
+			target = strings.ToLower(strings.TrimSuffix(target, "."))
+			if target == suffix || strings.HasSuffix(target, "."+suffix) {
+				return chain, true
+			}
+		}
+	}
+	return models.CNAMEChain{}, false
+}
+
+// Identify names the CDN serving a host: the provider whose matches give
+// the highest combined confidence, or "none" when no provider reaches
+// MinConfidence. The CNAME chains observed are kept with the result.
+func (e *CDNEngine) Identify(obs CDNObservation) *CDNInfo {
+	info := &CDNInfo{
+		Name:        "none",
+		Features:    make(map[string]string),
+		Matches:     e.Match(obs),
+		CNAMEChains: obs.CNAMEChains,
+	}
+
+	// Independent matches combine: 1 - (1-c1)(1-c2)...
+	combined := make(map[string]float64)
+	var order []string
+	for _, match := range info.Matches {
+		if _, ok := combined[match.Provider]; !ok {
+			combined[match.Provider] = 0
+			order = append(order, match.Provider)
+		}
+		combined[match.Provider] = 1 - (1-combined[match.Provider])*(1-match.Confidence)
+	}
+	for _, provider := range order {
+		if combined[provider] >= e.MinConfidence && combined[provider] > info.Confidence {
+			info.Name = provider
+			info.Confidence = combined[provider]
+		}
+	}
+
+	for _, match := range info.Matches {
+		if match.Provider == info.Name && match.Kind == models.CDNMatchHeader {
+			name, value, _ := strings.Cut(match.Reference, ": ")
+			info.Features[name] = value
+		}
+	}
+	return info
+}
@@ -0,0 +1,144 @@
+package detector
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/resolver"
+	"github.com/genesis410/fogger/internal/resolver/resolvertest"
+)
+
+// TestCDNEngineIdentify tests the fingerprint kinds of the built-in catalog
+func TestCDNEngineIdentify(t *testing.T) {
+	engine := DefaultCDNEngine()
+
+	header := func(kv ...string) http.Header {
+		h := http.Header{}
+		for i := 0; i+1 < len(kv); i += 2 {
+			h.Add(kv[i], kv[i+1])
+		}
+		return h
+	}
+	cloudflareCA := &tls.ConnectionState{PeerCertificates: []*x509.Certificate{
+		{Issuer: pkix.Name{Organization: []string{"Cloudflare, Inc."}, CommonName: "Cloudflare Inc ECC CA-3"}},
+	}}
+
+	tests := []struct {
+		name string
+		obs  CDNObservation
+		cdn  string
+		kind string
+	}{
+		{"cloudfront lowercase", CDNObservation{Headers: header("X-Cache", "Miss from cloudfront")}, "cloudfront", models.CDNMatchHeader},
+		{"cloudfront capitalized", CDNObservation{Headers: header("X-Cache", "Hit from CloudFront")}, "cloudfront", models.CDNMatchHeader},
+		{"bare x-cache", CDNObservation{Headers: header("X-Cache", "HIT")}, "none", ""},
+		{"varnish only", CDNObservation{Headers: header("Via", "1.1 varnish")}, "none", models.CDNMatchHeader},
+		{"fastly", CDNObservation{Headers: header("X-Served-By", "cache-sin18021-SIN")}, "fastly", models.CDNMatchHeader},
+		{"imperva cookie", CDNObservation{Headers: header("Set-Cookie", "visid_incap_2817=abc; path=/")}, "imperva", models.CDNMatchCookie},
+		{"ddos-guard cookie", CDNObservation{Headers: header("Set-Cookie", "__ddg1_=x; path=/")}, "ddos-guard", models.CDNMatchCookie},
+		{"bunnycdn", CDNObservation{Headers: header("Server", "BunnyCDN-SG1-1048")}, "bunnycdn", models.CDNMatchHeader},
+		{"vercel", CDNObservation{Headers: header("X-Vercel-Id", "sin1::abcd")}, "vercel", models.CDNMatchHeader},
+		{"quic.cloud", CDNObservation{Headers: header("X-QC-Pop", "AS-SG-SIN-45")}, "quic.cloud", models.CDNMatchHeader},
+		{"sucuri", CDNObservation{Headers: header("X-Sucuri-ID", "18015")}, "sucuri", models.CDNMatchHeader},
+		{"gcore cname", CDNObservation{CNAMEChains: []models.CNAMEChain{{Name: "gacor88.xyz", Targets: []string{"cl-abcdef.gcdn.co."}}}}, "gcore", models.CDNMatchCNAME},
+		{"bunny cname", CDNObservation{CNAMEChains: []models.CNAMEChain{{Name: "www.gacor88.xyz", Targets: []string{"gacor88.b-cdn.net"}}}}, "bunnycdn", models.CDNMatchCNAME},
+		{"cname lookalike", CDNObservation{CNAMEChains: []models.CNAMEChain{{Name: "gacor88.xyz", Targets: []string{"notcloudfront.net"}}}}, "none", ""},
+		{"cloudflare range", CDNObservation{Addresses: []string{"104.16.1.1"}}, "cloudflare", models.CDNMatchIPRange},
+		{"cloudflare issuer only", CDNObservation{TLS: cloudflareCA}, "cloudflare", models.CDNMatchTLSIssuer},
+		{"imperva error page", CDNObservation{Body: "<html>Request unsuccessful. Incapsula incident ID: 123</html>"}, "imperva", models.CDNMatchErrorPage},
+		{"origin", CDNObservation{Headers: header("Server", "nginx"), Addresses: []string{"198.51.100.5"}}, "none", ""},
+	}
+	for _, tt := range tests {
+		info := engine.Identify(tt.obs)
+		if info.Name != tt.cdn {
+			t.Errorf("%s: expected %s, got %s (%+v)", tt.name, tt.cdn, info.Name, info.Matches)
+		}
+		if tt.kind == "" && len(info.Matches) > 0 {
+			t.Errorf("%s: expected no matches, got %+v", tt.name, info.Matches)
+		}
+		if tt.kind != "" && (len(info.Matches) == 0 || info.Matches[0].Kind != tt.kind) {
+			t.Errorf("%s: expected a %s match, got %+v", tt.name, tt.kind, info.Matches)
+		}
+	}
+}
+
+// TestCDNEngineConfidence tests that matches of a provider combine and that
+// the strongest provider wins
+func TestCDNEngineConfidence(t *testing.T) {
+	engine := DefaultCDNEngine()
+
+	single := engine.Identify(CDNObservation{Headers: http.Header{"Cf-Cache-Status": {"HIT"}}})
+	both := engine.Identify(CDNObservation{
+		Headers:   http.Header{"Cf-Cache-Status": {"HIT"}, "Via": {"1.1 varnish"}},
+		Addresses: []string{"104.16.1.1"},
+	})
+	if single.Name != "cloudflare" || both.Name != "cloudflare" {
+		t.Fatalf("Expected cloudflare, got %s and %s", single.Name, both.Name)
+	}
+	if single.Confidence != 0.8 || both.Confidence <= single.Confidence || both.Confidence >= 1 {
+		t.Errorf("Expected combined confidence above 0.8, got %.3f and %.3f", single.Confidence, both.Confidence)
+	}
+	if len(both.Matches) != 3 || both.Features["cf-cache-status"] != "HIT" {
+		t.Errorf("Expected all matches and the Cloudflare headers as features, got %+v", both)
+	}
+
+	if _, err := ParseCDNFingerprints([]byte("providers:\n  - name: bad\n    headers:\n      - {name: server, pattern: \"(\", confidence: 1}\n")); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
+
+// TestCDNDetectorUsesEngine tests that CDNDetector identifies a CDN from
+// the fingerprints of a live response
+func TestCDNDetectorUsesEngine(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "ddos-guard")
+		w.Write([]byte("<html>slot gacor</html>"))
+	}))
+	defer server.Close()
+
+	info := NewCDNDetector().DetectCDN(server.URL)
+	if info.Name != "ddos-guard" || info.Features["server"] != "ddos-guard" {
+		t.Errorf("Expected ddos-guard, got %+v", info)
+	}
+}
+
+// TestFollowCNAMEChain tests following chains to the last target and
+// stopping at loops
+func TestFollowCNAMEChain(t *testing.T) {
+	server, err := resolvertest.NewServer([]models.DNSRecord{
+		{Name: "www.gacor88.xyz", Type: "CNAME", TTL: 300, Value: "gacor88.xyz.cdn.cloudflare.net"},
+		{Name: "gacor88.xyz.cdn.cloudflare.net", Type: "A", TTL: 300, Value: "104.16.1.1"},
+		{Name: "a.slot88.xyz", Type: "CNAME", TTL: 300, Value: "b.slot88.xyz"},
+		{Name: "b.slot88.xyz", Type: "CNAME", TTL: 300, Value: "a.slot88.xyz"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start DNS server: %v", err)
+	}
+	defer server.Close()
+	r := resolver.NewServer(server.Addr, false, time.Second)
+
+	chain, err := FollowCNAMEChain(context.Background(), r, "WWW.gacor88.xyz.")
+	if err != nil || chain.String() != "www.gacor88.xyz -> gacor88.xyz.cdn.cloudflare.net" {
+		t.Errorf("Expected the Cloudflare chain, got %s (%v)", chain, err)
+	}
+	if info := DefaultCDNEngine().Identify(CDNObservation{CNAMEChains: []models.CNAMEChain{chain}}); info.Name != "cloudflare" || len(info.CNAMEChains) != 1 {
+		t.Errorf("Expected cloudflare with the chain recorded, got %+v", info)
+	}
+
+	chain, err = FollowCNAMEChain(context.Background(), r, "a.slot88.xyz")
+	if err == nil || len(chain.Targets) != 1 {
+		t.Errorf("Expected a loop error after one hop, got %s (%v)", chain, err)
+	}
+
+	chain, err = FollowCNAMEChain(context.Background(), r, "gacor88.xyz.cdn.cloudflare.net")
+	if err != nil || len(chain.Targets) != 0 {
+		t.Errorf("Expected no targets for a name without CNAME, got %s (%v)", chain, err)
+	}
+}
@@ -0,0 +1,320 @@
+package detector
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+//go:embed data/cdn_ranges.yaml
+var defaultCDNRanges []byte
+
+// Range provider kinds
+const (
+	// RangeKindCDN marks edge networks proxying an origin
+	RangeKindCDN = "cdn"
+	// RangeKindCloud marks hosting networks, which can serve the origin itself
+	RangeKindCloud = "cloud"
+)
+
+// Range source formats
+const (
+	RangeFormatText   = "text"
+	RangeFormatFastly = "fastly"
+	RangeFormatAWS    = "aws"
+	RangeFormatGoogle = "google"
+)
+
+// CDNRangeSource is a published list of a provider's prefixes
+type CDNRangeSource struct {
+	URL    string `yaml:"url"`
+	Format string `yaml:"format"`
+	// Service selects the prefixes of one service in aws lists
+	Service string `yaml:"service,omitempty"`
+}
+
+// CDNRangeProvider is a CDN or cloud network and its IP prefixes
+type CDNRangeProvider struct {
+	Name     string           `yaml:"name"`
+	Kind     string           `yaml:"kind"`
+	Sources  []CDNRangeSource `yaml:"sources,omitempty"`
+	Prefixes []string         `yaml:"prefixes"`
+}
+
+// CDNRangeCatalog holds the known CDN and cloud IP ranges
+type CDNRangeCatalog struct {
+	Providers []CDNRangeProvider `yaml:"providers"`
+}
+
+// DefaultCDNRangesPath returns ~/.fogger/cdn_ranges.yaml, where
+// `fogger cdn-ranges update` saves the ranges unless catalogs.cdn_ranges is set
+func DefaultCDNRangesPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %v", err)
+	}
+	return filepath.Join(home, ".fogger", "cdn_ranges.yaml"), nil
+}
+
+// LoadCDNRanges reads a CDN range catalog. If path is empty the built-in
+// catalog is used.
+func LoadCDNRanges(path string) (*CDNRangeCatalog, error) {
+	data := defaultCDNRanges
+	if path != "" {
+		fileData, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CDN ranges: %v", err)
+		}
+		data = fileData
+	}
+	return ParseCDNRanges(data)
+}
+
+// ParseCDNRanges parses a YAML CDN range catalog
+func ParseCDNRanges(data []byte) (*CDNRangeCatalog, error) {
+	catalog := &CDNRangeCatalog{}
+	if err := yaml.Unmarshal(data, catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse CDN ranges: %v", err)
+	}
+	return catalog, nil
+}
+
+// rangeNode is a node of a binary prefix trie; provider is set on nodes
+// ending a prefix
+type rangeNode struct {
+	children [2]*rangeNode
+	provider *CDNRangeProvider
+}
+
+// IPRanges looks up which provider's published ranges hold an address
+type IPRanges struct {
+	v4 rangeNode
+	v6 rangeNode
+}
+
+// NewIPRanges builds the prefix tries of a catalog
+func NewIPRanges(catalog *CDNRangeCatalog) (*IPRanges, error) {
+	ranges := &IPRanges{}
+	for i := range catalog.Providers {
+		provider := &catalog.Providers[i]
+		for _, prefix := range provider.Prefixes {
+			if err := ranges.Insert(prefix, provider); err != nil {
+				return nil, fmt.Errorf("invalid prefix %q of %s: %v", prefix, provider.Name, err)
+			}
+		}
+	}
+	return ranges, nil
+}
+
+// Insert adds a CIDR prefix of provider
+func (r *IPRanges) Insert(prefix string, provider *CDNRangeProvider) error {
+	p, err := netip.ParsePrefix(strings.TrimSpace(prefix))
+	if err != nil {
+		return err
+	}
+	p = p.Masked()
+	node := r.root(p.Addr())
+	addr := p.Addr().AsSlice()
+	for i := 0; i < p.Bits(); i++ {
+		bit := addressBit(addr, i)
+		if node.children[bit] == nil {
+			node.children[bit] = &rangeNode{}
+		}
+		node = node.children[bit]
+	}
+	node.provider = provider
+	return nil
+}
+
+// Lookup returns the provider of the longest prefix holding ip, or nil
+func (r *IPRanges) Lookup(ip string) *CDNRangeProvider {
+	if r == nil {
+		return nil
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil
+	}
+	addr = addr.Unmap()
+
+	node := r.root(addr)
+	match := node.provider
+	bytes := addr.AsSlice()
+	for i := 0; i < addr.BitLen() && node != nil; i++ {
+		node = node.children[addressBit(bytes, i)]
+		if node != nil && node.provider != nil {
+			match = node.provider
+		}
+	}
+	return match
+}
+
+// CDN returns the CDN whose ranges hold ip, or "" when ip is not a CDN
+// edge address
+func (r *IPRanges) CDN(ip string) string {
+	if provider := r.Lookup(ip); provider != nil && provider.Kind == RangeKindCDN {
+		return provider.Name
+	}
+	return ""
+}
+
+// ClassifyRecords sets the provider of each A and AAAA record whose address
+// is in a known range
+func (r *IPRanges) ClassifyRecords(records []models.DNSRecord) {
+	for i := range records {
+		if records[i].Type != "A" && records[i].Type != "AAAA" {
+			continue
+		}
+		if provider := r.Lookup(records[i].Value); provider != nil {
+			records[i].Provider = provider.Name
+			records[i].CDN = provider.Kind == RangeKindCDN
+		}
+	}
+}
+
+func (r *IPRanges) root(addr netip.Addr) *rangeNode {
+	if addr.Is4() {
+		return &r.v4
+	}
+	return &r.v6
+}
+
+// addressBit returns bit i of an address, counting from the most significant
+func addressBit(addr []byte, i int) int {
+	return int(addr[i/8]>>(7-uint(i%8))) & 1
+}
+
+// FetchCDNRanges refreshes the prefixes of each provider with sources from
+// its published lists. A provider whose lists fail keeps its prefixes; the
+// failures are returned with the refreshed catalog.
+func FetchCDNRanges(ctx context.Context, client *http.Client, catalog *CDNRangeCatalog) (*CDNRangeCatalog, []error) {
+	updated := &CDNRangeCatalog{}
+	var errs []error
+	for _, provider := range catalog.Providers {
+		if len(provider.Sources) > 0 {
+			var prefixes []string
+			var err error
+			for _, source := range provider.Sources {
+				var fetched []string
+				fetched, err = fetchRangeSource(ctx, client, source)
+				if err != nil {
+					break
+				}
+				prefixes = append(prefixes, fetched...)
+			}
+			switch {
+			case err != nil:
+				errs = append(errs, fmt.Errorf("%s: %v", provider.Name, err))
+			case len(prefixes) == 0:
+				errs = append(errs, fmt.Errorf("%s: no prefixes published", provider.Name))
+			default:
+				provider.Prefixes = prefixes
+			}
+		}
+		updated.Providers = append(updated.Providers, provider)
+	}
+	return updated, errs
+}
+
+// fetchRangeSource downloads and parses one published list
+func fetchRangeSource(ctx context.Context, client *http.Client, source CDNRangeSource) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, source.URL)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	var prefixes []string
+	switch source.Format {
+	case RangeFormatText:
+		scanner := bufio.NewScanner(strings.NewReader(string(data)))
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+				prefixes = append(prefixes, line)
+			}
+		}
+	case RangeFormatFastly:
+		var list struct {
+			Addresses     []string `json:"addresses"`
+			IPv6Addresses []string `json:"ipv6_addresses"`
+		}
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("invalid fastly list: %v", err)
+		}
+		prefixes = append(list.Addresses, list.IPv6Addresses...)
+	case RangeFormatAWS:
+		var list struct {
+			Prefixes []struct {
+				IPPrefix string `json:"ip_prefix"`
+				Service  string `json:"service"`
+			} `json:"prefixes"`
+			IPv6Prefixes []struct {
+				IPv6Prefix string `json:"ipv6_prefix"`
+				Service    string `json:"service"`
+			} `json:"ipv6_prefixes"`
+		}
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("invalid aws list: %v", err)
+		}
+		for _, p := range list.Prefixes {
+			if source.Service == "" || p.Service == source.Service {
+				prefixes = appendUniqueString(prefixes, p.IPPrefix)
+			}
+		}
+		for _, p := range list.IPv6Prefixes {
+			if source.Service == "" || p.Service == source.Service {
+				prefixes = appendUniqueString(prefixes, p.IPv6Prefix)
+			}
+		}
+	case RangeFormatGoogle:
+		var list struct {
+			Prefixes []struct {
+				IPv4Prefix string `json:"ipv4Prefix"`
+				IPv6Prefix string `json:"ipv6Prefix"`
+			} `json:"prefixes"`
+		}
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("invalid google list: %v", err)
+		}
+		for _, p := range list.Prefixes {
+			if p.IPv4Prefix != "" {
+				prefixes = append(prefixes, p.IPv4Prefix)
+			}
+			if p.IPv6Prefix != "" {
+				prefixes = append(prefixes, p.IPv6Prefix)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q", source.Format)
+	}
+
+	for _, prefix := range prefixes {
+		if _, err := netip.ParsePrefix(prefix); err != nil {
+			return nil, fmt.Errorf("invalid prefix %q from %s", prefix, source.URL)
+		}
+	}
+	return prefixes, nil
+}
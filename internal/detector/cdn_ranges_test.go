@@ -0,0 +1,187 @@
+package detector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/resolver"
+	"github.com/genesis410/fogger/internal/resolver/resolvertest"
+)
+
+// TestIPRangesLookup tests longest-prefix lookups of IPv4 and IPv6 addresses
+func TestIPRangesLookup(t *testing.T) {
+	ranges, err := NewIPRanges(&CDNRangeCatalog{Providers: []CDNRangeProvider{
+		{Name: "cloud", Kind: RangeKindCloud, Prefixes: []string{"10.0.0.0/8"}},
+		{Name: "edge", Kind: RangeKindCDN, Prefixes: []string{"10.1.0.0/16", "2001:db8::/32"}},
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error building ranges: %v", err)
+	}
+
+	tests := []struct{ ip, provider, cdn string }{
+		{"10.1.2.3", "edge", "edge"},
+		{"10.2.0.1", "cloud", ""},
+		{"::ffff:10.1.0.1", "edge", "edge"},
+		{"2001:db8::1", "edge", "edge"},
+		{"2001:db9::1", "", ""},
+		{"192.0.2.1", "", ""},
+		{"not-an-ip", "", ""},
+	}
+	for _, tt := range tests {
+		name := ""
+		if provider := ranges.Lookup(tt.ip); provider != nil {
+			name = provider.Name
+		}
+		if name != tt.provider || ranges.CDN(tt.ip) != tt.cdn {
+			t.Errorf("Expected %s in %q (CDN %q), got %q (CDN %q)", tt.ip, tt.provider, tt.cdn, name, ranges.CDN(tt.ip))
+		}
+	}
+
+	if _, err := NewIPRanges(&CDNRangeCatalog{Providers: []CDNRangeProvider{{Name: "bad", Prefixes: []string{"10.0.0.0/33"}}}}); err == nil {
+		t.Error("Expected an error for an invalid prefix")
+	}
+}
+
+// TestBuiltInCDNRanges tests that the built-in dataset parses and classifies
+// well-known edge addresses
+func TestBuiltInCDNRanges(t *testing.T) {
+	catalog, err := LoadCDNRanges("")
+	if err != nil {
+		t.Fatalf("Unexpected error loading built-in ranges: %v", err)
+	}
+	ranges, err := NewIPRanges(catalog)
+	if err != nil {
+		t.Fatalf("Invalid built-in ranges: %v", err)
+	}
+
+	for ip, cdn := range map[string]string{
+		"104.16.1.1":        "cloudflare",
+		"2606:4700::6810:1": "cloudflare",
+		"151.101.1.69":      "fastly",
+		"13.224.10.1":       "cloudfront",
+		"23.45.67.89":       "akamai",
+		"142.250.4.100":     "google",
+		"35.190.0.1":        "",
+		"198.51.100.5":      "",
+		"2001:db8::1":       "",
+	} {
+		if got := ranges.CDN(ip); got != cdn {
+			t.Errorf("Expected CDN %q for %s, got %q", cdn, ip, got)
+		}
+	}
+
+	records := []models.DNSRecord{
+		{Name: "gacor88.xyz", Type: "A", Value: "104.16.1.1"},
+		{Name: "gacor88.xyz", Type: "A", Value: "35.200.1.1"},
+		{Name: "gacor88.xyz", Type: "A", Value: "198.51.100.5"},
+		{Name: "gacor88.xyz", Type: "TXT", Value: "104.16.1.1"},
+	}
+	ranges.ClassifyRecords(records)
+	if !records[0].CDN || records[0].Provider != "cloudflare" {
+		t.Errorf("Expected a Cloudflare edge address, got %+v", records[0])
+	}
+	if records[1].CDN || records[1].Provider != "google-cloud" {
+		t.Errorf("Expected a Google Cloud address, got %+v", records[1])
+	}
+	if records[2].CDN || records[2].Provider != "" || records[3].Provider != "" {
+		t.Errorf("Expected unclassified records, got %+v", records[2:])
+	}
+}
+
+// TestFetchCDNRanges tests refreshing from text and aws lists, and keeping
+// the ranges of a provider whose list fails
+func TestFetchCDNRanges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ips-v4":
+			w.Write([]byte("173.245.48.0/20\n103.21.244.0/22\n"))
+		case "/ip-ranges.json":
+			w.Write([]byte(`{"prefixes":[{"ip_prefix":"13.32.0.0/15","service":"CLOUDFRONT"},{"ip_prefix":"3.5.140.0/22","service":"S3"}],
+				"ipv6_prefixes":[{"ipv6_prefix":"2600:9000::/28","service":"CLOUDFRONT"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	catalog := &CDNRangeCatalog{Providers: []CDNRangeProvider{
+		{Name: "cloudflare", Kind: RangeKindCDN, Prefixes: []string{"104.16.0.0/13"},
+			Sources: []CDNRangeSource{{URL: server.URL + "/ips-v4", Format: RangeFormatText}}},
+		{Name: "cloudfront", Kind: RangeKindCDN,
+			Sources: []CDNRangeSource{{URL: server.URL + "/ip-ranges.json", Format: RangeFormatAWS, Service: "CLOUDFRONT"}}},
+		{Name: "fastly", Kind: RangeKindCDN, Prefixes: []string{"151.101.0.0/16"},
+			Sources: []CDNRangeSource{{URL: server.URL + "/missing", Format: RangeFormatFastly}}},
+		{Name: "akamai", Kind: RangeKindCDN, Prefixes: []string{"23.0.0.0/12"}},
+	}}
+
+	updated, errs := FetchCDNRanges(context.Background(), server.Client(), catalog)
+	if len(errs) != 1 {
+		t.Errorf("Expected only the fastly list to fail, got %v", errs)
+	}
+	expected := map[string][]string{
+		"cloudflare": {"173.245.48.0/20", "103.21.244.0/22"},
+		"cloudfront": {"13.32.0.0/15", "2600:9000::/28"},
+		"fastly":     {"151.101.0.0/16"},
+		"akamai":     {"23.0.0.0/12"},
+	}
+	for _, provider := range updated.Providers {
+		want := expected[provider.Name]
+		if len(provider.Prefixes) != len(want) {
+			t.Errorf("Expected %s prefixes %v, got %v", provider.Name, want, provider.Prefixes)
+			continue
+		}
+		for i := range want {
+			if provider.Prefixes[i] != want[i] {
+				t.Errorf("Expected %s prefixes %v, got %v", provider.Name, want, provider.Prefixes)
+			}
+		}
+	}
+}
+
+// TestCDNStatusOf tests that addresses in CDN ranges name the CDN and that
+// a host that does not answer is not taken for a CDN
+func TestCDNStatusOf(t *testing.T) {
+	od := NewOriginIPDetector()
+	od.Client.Timeout = time.Second
+
+	if status := od.CDNStatusOf("rtp.gacor88.invalid", []string{"198.51.100.9", "104.16.1.1"}); status != "cloudflare" {
+		t.Errorf("Expected cloudflare, got %s", status)
+	}
+	if status := od.CDNStatusOf("old.gacor88.invalid", []string{"198.51.100.9"}); status != "none" {
+		t.Errorf("Expected an unreachable host outside CDN ranges to be none, got %s", status)
+	}
+	if status := od.CDNStatusOf("gone.gacor88.invalid", nil); status != "unknown" {
+		t.Errorf("Expected an unreachable host without addresses to be unknown, got %s", status)
+	}
+}
+
+// TestOriginIPSkipsCDNAddresses tests that CDN edge addresses are not
+// reported as origin candidates
+func TestOriginIPSkipsCDNAddresses(t *testing.T) {
+	server, err := resolvertest.NewServer([]models.DNSRecord{
+		{Name: "gacor88.xyz", Type: "A", TTL: 300, Value: "104.16.1.1"},
+		{Name: "gacor88.xyz", Type: "MX", TTL: 3600, Value: "10 mail.gacor88.xyz"},
+		{Name: "gacor88.xyz", Type: "TXT", TTL: 3600, Value: "v=spf1 ip4:172.67.1.1 ip4:198.51.100.7 -all"},
+		{Name: "mail.gacor88.xyz", Type: "A", TTL: 3600, Value: "198.51.100.5"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start DNS server: %v", err)
+	}
+	defer server.Close()
+
+	od := NewOriginIPDetector()
+	od.Resolver = resolver.NewServer(server.Addr, false, time.Second)
+	od.Subdomains = []models.Subdomain{}
+
+	ips, _, err := od.DetectOriginIPs("gacor88.xyz")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(ips) != 2 || ips[0] != "198.51.100.5" || ips[1] != "198.51.100.7" {
+		t.Errorf("Expected only the mail server and SPF addresses, got %v", ips)
+	}
+}
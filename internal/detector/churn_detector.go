@@ -0,0 +1,184 @@
+package detector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// DomainName is a registrable domain split into the parts operators vary
+// when rotating domains: slot88a.com is brand "slot", number "88" and
+// suffix "a" under the public suffix "com"
+type DomainName struct {
+	Domain string
+	Label  string
+	TLD    string
+	Brand  string
+	Number string
+	Suffix string
+}
+
+// Family returns the brand and number shared by a rotation set, or "" when
+// the label has no number
+func (n DomainName) Family() string {
+	if n.Number == "" {
+		return ""
+	}
+	return n.Brand + n.Number
+}
+
+// ParseDomainName tokenizes the registrable domain of a host
+// (www.gacor77-1.co.id -> brand "gacor", number "77", suffix "1", TLD "co.id")
+func ParseDomainName(host string) (DomainName, error) {
+	host = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(host), "."))
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return DomainName{}, err
+	}
+	label, tld, _ := strings.Cut(domain, ".")
+
+	name := DomainName{Domain: domain, Label: label, TLD: tld}
+	i := strings.IndexAny(label, "0123456789")
+	if i < 0 {
+		name.Brand = strings.Trim(label, "-")
+		return name, nil
+	}
+	name.Brand = strings.Trim(label[:i], "-")
+	j := i
+	for j < len(label) && label[j] >= '0' && label[j] <= '9' {
+		j++
+	}
+	name.Number = label[i:j]
+	name.Suffix = strings.Trim(label[j:], "-")
+	return name, nil
+}
+
+// ChurnDetector detects domain churn from naming patterns. Operators replace
+// blocked domains with siblings that keep the brand and vary a number,
+// suffix or TLD (slot88a.com, slot88b.net, slot88c.xyz).
+type ChurnDetector struct {
+	// MinBrandLength is the shortest brand that links domains without a
+	// shared number
+	MinBrandLength int
+	// GenericBrands are gambling words too common to link domains on their own
+	GenericBrands []string
+	// MaxRotationSuffix is the longest suffix read as a rotation counter
+	MaxRotationSuffix int
+}
+
+// NewChurnDetector creates a new domain churn detector
+func NewChurnDetector() *ChurnDetector {
+	return &ChurnDetector{
+		MinBrandLength: 4,
+		GenericBrands: []string{
+			"slot", "togel", "judi", "casino", "bet", "toto", "gacor", "maxwin", "poker",
+			"bola", "sbo", "situs", "link", "daftar", "login", "game", "win", "jp",
+		},
+		MaxRotationSuffix: 2,
+	}
+}
+
+// IsSibling reports whether two domain names look like one rotation set:
+// the same brand and number, or the same distinctive brand with another
+// number or suffix
+func (cd *ChurnDetector) IsSibling(a, b DomainName) bool {
+	if a.Domain == b.Domain || a.Brand == "" || a.Brand != b.Brand {
+		return false
+	}
+	if a.Family() != "" && a.Family() == b.Family() {
+		return true
+	}
+	if len(a.Brand) < cd.MinBrandLength || cd.isGenericBrand(a.Brand) {
+		return false
+	}
+	// Only the varying parts may differ, and at least one must be present
+	return a.Number+a.Suffix != "" && b.Number+b.Suffix != ""
+}
+
+// IsRotationName reports whether a label ends in a rotation counter after
+// its number (slot88a, gacor77-1)
+func (cd *ChurnDetector) IsRotationName(name DomainName) bool {
+	return name.Brand != "" && name.Number != "" && name.Suffix != "" && len(name.Suffix) <= cd.MaxRotationSuffix
+}
+
+// DetectChurn compares a domain name against known domains and returns the
+// churn signals and the registrable domains of its siblings
+func (cd *ChurnDetector) DetectChurn(name DomainName, known []string) ([]models.Signal, []string) {
+	var signals []models.Signal
+
+	if cd.IsRotationName(name) {
+		signals = append(signals, churnSignal("DOMAIN_ROTATION_NAME",
+			fmt.Sprintf("Domain name ends in a rotation counter: %s", name.Label), 0.3,
+			fmt.Sprintf("%s: brand %q, number %q, suffix %q", name.Domain, name.Brand, name.Number, name.Suffix)))
+	}
+
+	seen := map[string]bool{name.Domain: true}
+	var siblings []string
+	for _, host := range known {
+		other, err := ParseDomainName(host)
+		if err != nil || seen[other.Domain] {
+			continue
+		}
+		seen[other.Domain] = true
+		if cd.IsSibling(name, other) {
+			siblings = append(siblings, other.Domain)
+		}
+	}
+	sort.Strings(siblings)
+
+	if len(siblings) > 0 {
+		// Each further sibling makes a coincidental name match less likely
+		confidence := 0.5 + 0.1*float64(len(siblings))
+		if confidence > 0.8 {
+			confidence = 0.8
+		}
+		var evidence []models.Evidence
+		for _, sibling := range siblings {
+			evidence = append(evidence, models.Evidence{
+				Type:      "domain_name",
+				Reference: name.Domain + " ~ " + sibling,
+				Timestamp: time.Now(),
+			})
+		}
+		signals = append(signals, models.Signal{
+			SignalID:    "DOMAIN_SIBLING_NAMES",
+			Category:    "DNS",
+			Description: fmt.Sprintf("Domain name matches %d known domains of a rotation set: %s", len(siblings), strings.Join(siblings, ", ")),
+			Confidence:  confidence,
+			Evidence:    evidence,
+		})
+	}
+
+	return signals, siblings
+}
+
+func (cd *ChurnDetector) isGenericBrand(brand string) bool {
+	for _, generic := range cd.GenericBrands {
+		if brand == generic {
+			return true
+		}
+	}
+	return false
+}
+
+// churnSignal builds a domain churn signal with one piece of evidence
+func churnSignal(signalID, description string, confidence float64, reference string) models.Signal {
+	return models.Signal{
+		SignalID:    signalID,
+		Category:    "DNS",
+		Description: description,
+		Confidence:  confidence,
+		Evidence: []models.Evidence{
+			{
+				Type:      "domain_name",
+				Reference: reference,
+				Timestamp: time.Now(),
+			},
+		},
+	}
+}
@@ -0,0 +1,65 @@
+package detector
+
+import (
+	"testing"
+)
+
+// TestParseDomainName tests tokenizing registrable domains into brand,
+// number and suffix
+func TestParseDomainName(t *testing.T) {
+	tests := []struct {
+		host                               string
+		domain, brand, number, suffix, tld string
+	}{
+		{"slot88a.com", "slot88a.com", "slot", "88", "a", "com"},
+		{"www.gacor77-1.xyz", "gacor77-1.xyz", "gacor", "77", "1", "xyz"},
+		{"login.hokibet.co.id", "hokibet.co.id", "hokibet", "", "", "co.id"},
+		{"MAXWIN-99.NET.", "maxwin-99.net", "maxwin", "99", "", "net"},
+	}
+
+	for _, test := range tests {
+		name, err := ParseDomainName(test.host)
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %v", test.host, err)
+			continue
+		}
+		if name.Domain != test.domain || name.Brand != test.brand || name.Number != test.number ||
+			name.Suffix != test.suffix || name.TLD != test.tld {
+			t.Errorf("Unexpected parse of %s: %+v", test.host, name)
+		}
+	}
+}
+
+// TestDetectChurn tests rotation names and sibling matching
+func TestDetectChurn(t *testing.T) {
+	cd := NewChurnDetector()
+	name, _ := ParseDomainName("slot88b.net")
+
+	signals, siblings := cd.DetectChurn(name, []string{
+		"www.slot88a.com", "slot88c.xyz", "slot99.com", "slot88b.net", "news.example",
+	})
+	if len(siblings) != 2 || siblings[0] != "slot88a.com" || siblings[1] != "slot88c.xyz" {
+		t.Errorf("Expected slot88a.com and slot88c.xyz as siblings, got %v", siblings)
+	}
+
+	found := make(map[string]int)
+	for _, signal := range signals {
+		if signal.Category != "DNS" {
+			t.Errorf("Expected DNS category, got %s", signal.Category)
+		}
+		found[signal.SignalID] = len(signal.Evidence)
+	}
+	if found["DOMAIN_ROTATION_NAME"] != 1 || found["DOMAIN_SIBLING_NAMES"] != 2 {
+		t.Errorf("Expected rotation and sibling signals with evidence per sibling, got %v", found)
+	}
+
+	// A generic brand links domains only through a shared number, a
+	// distinctive one also across numbers
+	hoki, _ := ParseDomainName("hokibet77.com")
+	if _, siblings := cd.DetectChurn(hoki, []string{"hokibet99.net", "hokibet.org"}); len(siblings) != 1 || siblings[0] != "hokibet99.net" {
+		t.Errorf("Expected hokibet99.net as the only sibling, got %v", siblings)
+	}
+	if signals, siblings := cd.DetectChurn(hoki, nil); len(signals) != 0 || len(siblings) != 0 {
+		t.Errorf("Expected no signals for a plain name without known domains, got %v", signals)
+	}
+}
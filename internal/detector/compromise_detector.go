@@ -0,0 +1,346 @@
+package detector
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// CompromiseSignalPrefix starts the ID of every compromised host signal
+const CompromiseSignalPrefix = "COMPROMISE_"
+
+// Sources of listed URLs in a compromise report
+const (
+	ListedInSitemap = "sitemap"
+	ListedInRobots  = "robots"
+)
+
+// CompromiseDetector detects legitimate sites (typically .go.id, .ac.id and
+// .sch.id) that were hacked to host judol SEO pages: injected slot subpaths,
+// hidden link farms and off-topic outbound links on otherwise unrelated content
+type CompromiseDetector struct {
+	// URLTerms are URL tokens, digits removed, that mark a gambling URL
+	URLTerms []string
+	// WeakURLTerms are URL tokens that are also ordinary words (a parking
+	// slot, a Zeus product page) and only mark a gambling URL in pairs
+	WeakURLTerms []string
+	// URLPrefixes mark a gambling URL token by prefix (gacorx500, slotgacor88)
+	URLPrefixes []string
+	// ContentPhrases mark gambling text
+	ContentPhrases []string
+	// MinContentPhrases is how many distinct phrases make text gambling content
+	MinContentPhrases int
+}
+
+// CompromiseLink is a gambling link found on a compromised page
+type CompromiseLink struct {
+	URL       string
+	Text      string
+	Technique string
+}
+
+// ListedURL is a gambling URL listed in the sitemap or robots.txt
+type ListedURL struct {
+	URL    string
+	Source string
+}
+
+// CompromiseReport holds what a page and its crawl files reveal about injection
+type CompromiseReport struct {
+	// MainContentGambling is set when the visible content itself is gambling,
+	// meaning the site is an operator rather than a compromised host
+	MainContentGambling bool
+	HiddenLinks         []CompromiseLink
+	OffTopicLinks       []CompromiseLink
+	InjectedPaths       []string
+	ListedURLs          []ListedURL
+	// ConfirmedPages are injected URLs fetched and found serving gambling content
+	ConfirmedPages []string
+}
+
+// NewCompromiseDetector creates a new compromised host detector
+func NewCompromiseDetector() *CompromiseDetector {
+	return &CompromiseDetector{
+		URLTerms: []string{"gacor", "maxwin", "togel", "judi", "casino", "sbobet"},
+		WeakURLTerms: []string{
+			"slot", "slots", "demo", "scatter", "rtp", "zeus", "olympus", "toto", "pragmatic", "mahjong",
+		},
+		URLPrefixes: []string{
+			"gacor", "maxwin", "togel", "sbobet", "slotgacor", "slotonline", "slotdemo",
+			"slotmaxwin", "situsslot", "judislot", "agenslot",
+		},
+		ContentPhrases: []string{
+			"slot gacor", "slot online", "situs slot", "judi online", "judi slot", "maxwin",
+			"gacor", "togel", "rtp live", "rtp slot", "scatter", "bandar togel", "bandar slot", "agen slot",
+			"deposit pulsa", "link alternatif", "sbobet", "casino online", "bocoran slot",
+		},
+		MinContentPhrases: 3,
+	}
+}
+
+// IsGamblingURL reports whether the host, path or query of a URL is made of
+// gambling terms: one gambling term or prefix, or two distinct weak terms
+func (cd *CompromiseDetector) IsGamblingURL(rawURL string) bool {
+	var weak []string
+	for _, token := range strings.FieldsFunc(strings.ToLower(rawURL), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z')
+	}) {
+		for _, term := range cd.URLTerms {
+			if token == term {
+				return true
+			}
+		}
+		for _, prefix := range cd.URLPrefixes {
+			if strings.HasPrefix(token, prefix) {
+				return true
+			}
+		}
+		for _, term := range cd.WeakURLTerms {
+			if token == term {
+				weak = appendUniqueString(weak, term)
+			}
+		}
+	}
+	return len(weak) >= 2
+}
+
+// gamblingPhrases returns the content phrases found in text
+func (cd *CompromiseDetector) gamblingPhrases(text string) []string {
+	text = strings.ToLower(text)
+	var found []string
+	for _, phrase := range cd.ContentPhrases {
+		if containsWord(text, phrase) {
+			found = append(found, phrase)
+		}
+	}
+	return found
+}
+
+// IsGamblingContent reports whether the visible text of a page is gambling content
+func (cd *CompromiseDetector) IsGamblingContent(content string) bool {
+	return len(cd.gamblingPhrases(visibleText(parseHTML(content)))) >= cd.MinContentPhrases
+}
+
+// AnalyzePage compares the visible main content of a page with its hidden
+// elements and links. pageURL resolves relative links and decides which
+// links stay on the site.
+func (cd *CompromiseDetector) AnalyzePage(content, pageURL string) *CompromiseReport {
+	report := &CompromiseReport{}
+	doc := parseHTML(content)
+
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		base = &url.URL{}
+	}
+	pageHost := stripWWW(strings.ToLower(base.Hostname()))
+	hiddenClasses := stylesheetHiddenClasses(doc)
+
+	var mainText strings.Builder
+	var walk func(n *html.Node, technique string)
+	walk = func(n *html.Node, technique string) {
+		if n.Type == html.ElementNode {
+			if signatureSkipTags[n.Data] {
+				return
+			}
+			if technique == "" {
+				technique = hidingTechnique(n, hiddenClasses)
+			}
+			if n.Data == "a" {
+				cd.recordLink(report, n, base, pageHost, technique)
+			}
+		}
+		if n.Type == html.TextNode && technique == "" {
+			mainText.WriteString(n.Data)
+			mainText.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, technique)
+		}
+	}
+	walk(doc, "")
+
+	report.MainContentGambling = len(cd.gamblingPhrases(mainText.String())) >= cd.MinContentPhrases
+	return report
+}
+
+// recordLink classifies a link as hidden, injected on the site or off-topic outbound
+func (cd *CompromiseDetector) recordLink(report *CompromiseReport, n *html.Node, base *url.URL, pageHost, technique string) {
+	href := strings.TrimSpace(nodeAttr(n, "href"))
+	ref, err := url.Parse(href)
+	if href == "" || err != nil {
+		return
+	}
+	link := base.ResolveReference(ref)
+	if link.Scheme != "http" && link.Scheme != "https" {
+		return
+	}
+
+	text := nodeText(n)
+	linkURL := link.String()
+	gambling := cd.IsGamblingURL(linkURL) || len(cd.gamblingPhrases(text)) > 0
+	if !gambling {
+		return
+	}
+
+	sameHost := stripWWW(strings.ToLower(link.Hostname())) == pageHost
+	switch {
+	case technique != "":
+		report.HiddenLinks = append(report.HiddenLinks, CompromiseLink{URL: linkURL, Text: truncateContext(text), Technique: technique})
+	case !sameHost:
+		report.OffTopicLinks = append(report.OffTopicLinks, CompromiseLink{URL: linkURL, Text: truncateContext(text)})
+	}
+	if sameHost && cd.IsGamblingURL(link.Path+"?"+link.RawQuery) {
+		report.InjectedPaths = appendUniqueString(report.InjectedPaths, linkURL)
+	}
+}
+
+// sitemapDocument covers both sitemap url sets and sitemap indexes
+type sitemapDocument struct {
+	URLs     []string `xml:"url>loc"`
+	Sitemaps []string `xml:"sitemap>loc"`
+}
+
+// SitemapURLs returns the page URLs and nested sitemap URLs of a sitemap
+func SitemapURLs(data []byte) ([]string, []string, error) {
+	var sitemap sitemapDocument
+	if err := xml.Unmarshal(data, &sitemap); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse sitemap: %v", err)
+	}
+	var pages, sitemaps []string
+	for _, loc := range sitemap.URLs {
+		if loc = strings.TrimSpace(loc); loc != "" {
+			pages = append(pages, loc)
+		}
+	}
+	for _, loc := range sitemap.Sitemaps {
+		if loc = strings.TrimSpace(loc); loc != "" {
+			sitemaps = append(sitemaps, loc)
+		}
+	}
+	return pages, sitemaps, nil
+}
+
+// RobotsEntries returns the Allow and Disallow paths and the Sitemap URLs of a robots.txt
+func RobotsEntries(data []byte) ([]string, []string) {
+	var paths, sitemaps []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		key, value, found := strings.Cut(line, ":")
+		value = strings.TrimSpace(value)
+		if !found || value == "" {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "allow", "disallow":
+			paths = append(paths, value)
+		case "sitemap":
+			sitemaps = append(sitemaps, value)
+		}
+	}
+	return paths, sitemaps
+}
+
+// InjectedURLs returns every injected URL in the report without duplicates
+func (r *CompromiseReport) InjectedURLs() []string {
+	var urls []string
+	for _, u := range r.ConfirmedPages {
+		urls = appendUniqueString(urls, u)
+	}
+	for _, u := range r.InjectedPaths {
+		urls = appendUniqueString(urls, u)
+	}
+	for _, listed := range r.ListedURLs {
+		urls = appendUniqueString(urls, listed.URL)
+	}
+	for _, link := range r.HiddenLinks {
+		urls = appendUniqueString(urls, link.URL)
+	}
+	return urls
+}
+
+// DetectCompromise produces compromised host signals from a report. A site
+// whose own content is gambling is an operator, so it produces none.
+func (cd *CompromiseDetector) DetectCompromise(report *CompromiseReport) []models.Signal {
+	if report.MainContentGambling {
+		return nil
+	}
+
+	var signals []models.Signal
+
+	if len(report.HiddenLinks) > 0 {
+		var evidence []models.Evidence
+		for _, link := range report.HiddenLinks {
+			evidence = append(evidence, compromiseEvidence("html",
+				fmt.Sprintf("Hidden link (%s) to %s '%s'", link.Technique, link.URL, link.Text)))
+		}
+		signals = append(signals, compromiseSignal("COMPROMISE_HIDDEN_LINKS",
+			fmt.Sprintf("Found %d gambling links hidden from visitors", len(report.HiddenLinks)), 0.85, evidence))
+	}
+
+	if len(report.InjectedPaths) > 0 {
+		var evidence []models.Evidence
+		for _, u := range report.InjectedPaths {
+			evidence = append(evidence, compromiseEvidence("html", "Link to injected path "+u))
+		}
+		signals = append(signals, compromiseSignal("COMPROMISE_INJECTED_PATHS",
+			fmt.Sprintf("Found %d gambling paths on the site", len(report.InjectedPaths)), 0.8, evidence))
+	}
+
+	if len(report.ListedURLs) > 0 {
+		var evidence []models.Evidence
+		for _, listed := range report.ListedURLs {
+			evidence = append(evidence, compromiseEvidence(listed.Source, "Listed gambling URL "+listed.URL))
+		}
+		signals = append(signals, compromiseSignal("COMPROMISE_SITEMAP_ENTRIES",
+			fmt.Sprintf("Found %d gambling URLs in sitemap or robots.txt", len(report.ListedURLs)), 0.8, evidence))
+	}
+
+	if len(report.ConfirmedPages) > 0 {
+		var evidence []models.Evidence
+		for _, u := range report.ConfirmedPages {
+			evidence = append(evidence, compromiseEvidence("http", "Injected page serves gambling content: "+u))
+		}
+		signals = append(signals, compromiseSignal("COMPROMISE_INJECTED_PAGES",
+			fmt.Sprintf("Found %d injected gambling pages", len(report.ConfirmedPages)), 0.9, evidence))
+	}
+
+	if len(report.OffTopicLinks) > 0 {
+		var evidence []models.Evidence
+		for _, link := range report.OffTopicLinks {
+			evidence = append(evidence, compromiseEvidence("html",
+				fmt.Sprintf("Outbound link to %s '%s'", link.URL, link.Text)))
+		}
+		signals = append(signals, compromiseSignal("COMPROMISE_OFFTOPIC_LINKS",
+			fmt.Sprintf("Found %d off-topic gambling links on unrelated content", len(report.OffTopicLinks)), 0.6, evidence))
+	}
+
+	return signals
+}
+
+// compromiseSignal builds an INFRA signal for a compromised host
+func compromiseSignal(signalID, description string, confidence float64, evidence []models.Evidence) models.Signal {
+	return models.Signal{
+		SignalID:    signalID,
+		Category:    "INFRA",
+		Description: description,
+		Confidence:  confidence,
+		Evidence:    evidence,
+	}
+}
+
+// compromiseEvidence builds one evidence entry
+func compromiseEvidence(evidenceType, reference string) models.Evidence {
+	return models.Evidence{
+		Type:      evidenceType,
+		Reference: reference,
+		Timestamp: time.Now(),
+	}
+}
@@ -0,0 +1,156 @@
+package detector
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDetectCompromiseInjectedSite tests hidden link farms and injected paths on a campus page
+func TestDetectCompromiseInjectedSite(t *testing.T) {
+	cd := NewCompromiseDetector()
+
+	testContent := `
+	<html>
+	<body>
+		<h1>Fakultas Teknik Universitas Contoh</h1>
+		<p>Penerimaan mahasiswa baru tahun akademik 2025/2026 telah dibuka.</p>
+		<a href="/akademik/jadwal">Jadwal Kuliah</a>
+		<a href="https://www.kemdikbud.go.id/">Kemdikbud</a>
+		<div style="display: none">
+			<a href="https://gacor88.xyz/">slot gacor</a>
+			<a href="https://maxwin99.top/daftar">situs slot maxwin</a>
+		</div>
+		<p style="position:absolute; left:-9999px">
+			<a href="/wp-content/uploads/slot-demo/">bocoran slot</a>
+		</p>
+		<a href="https://sbobet-88.com/">link</a>
+	</body>
+	</html>
+	`
+
+	report := cd.AnalyzePage(testContent, "https://ft.contoh.ac.id/")
+	if report.MainContentGambling {
+		t.Fatal("Did not expect the campus page content to be classified as gambling")
+	}
+	if len(report.HiddenLinks) != 3 {
+		t.Fatalf("Expected 3 hidden links, got %+v", report.HiddenLinks)
+	}
+	if report.HiddenLinks[0].Technique != "display:none" || report.HiddenLinks[2].Technique != "off-screen position" {
+		t.Errorf("Unexpected hiding techniques: %+v", report.HiddenLinks)
+	}
+	if len(report.InjectedPaths) != 1 || report.InjectedPaths[0] != "https://ft.contoh.ac.id/wp-content/uploads/slot-demo/" {
+		t.Errorf("Expected the injected slot path, got %v", report.InjectedPaths)
+	}
+	if len(report.OffTopicLinks) != 1 || report.OffTopicLinks[0].URL != "https://sbobet-88.com/" {
+		t.Errorf("Expected the visible sbobet link as off-topic, got %+v", report.OffTopicLinks)
+	}
+
+	found := make(map[string]bool)
+	for _, signal := range cd.DetectCompromise(report) {
+		if !strings.HasPrefix(signal.SignalID, CompromiseSignalPrefix) || len(signal.Evidence) == 0 {
+			t.Errorf("Unexpected signal %+v", signal)
+		}
+		found[signal.SignalID] = true
+	}
+	for _, id := range []string{"COMPROMISE_HIDDEN_LINKS", "COMPROMISE_INJECTED_PATHS", "COMPROMISE_OFFTOPIC_LINKS"} {
+		if !found[id] {
+			t.Errorf("Expected %s signal, got %v", id, found)
+		}
+	}
+
+	if urls := report.InjectedURLs(); len(urls) != 3 {
+		t.Errorf("Expected the injected path and two hidden links, got %v", urls)
+	}
+}
+
+// TestDetectCompromiseOperator tests that a gambling operator's own page is not a compromised host
+func TestDetectCompromiseOperator(t *testing.T) {
+	cd := NewCompromiseDetector()
+
+	testContent := `
+	<html>
+	<body>
+		<h1>Situs Slot Gacor Maxwin Hari Ini</h1>
+		<p>Agen slot online terpercaya, deposit pulsa tanpa potongan. Cek RTP live!</p>
+		<a href="/slot-gacor">Main Slot</a>
+		<div style="display:none"><a href="https://gacor99.xyz/">link alternatif</a></div>
+	</body>
+	</html>
+	`
+
+	report := cd.AnalyzePage(testContent, "https://gacor88.xyz/")
+	if !report.MainContentGambling {
+		t.Fatal("Expected the operator page content to be classified as gambling")
+	}
+	if signals := cd.DetectCompromise(report); len(signals) != 0 {
+		t.Errorf("Expected no compromise signals on an operator site, got %v", signals)
+	}
+}
+
+// TestDetectCompromiseOrdinaryWords tests that a government page using words
+// that are also gambling terms stays unclassified
+func TestDetectCompromiseOrdinaryWords(t *testing.T) {
+	cd := NewCompromiseDetector()
+
+	testContent := `
+	<html>
+	<body>
+		<h1>Pemerintah Kota Bandar Lampung</h1>
+		<p>Informasi layanan publik Kota Bandar Lampung dan Rencana Tata Ruang (RTP) kawasan pesisir.</p>
+		<a href="/berita/bandar-lampung-raih-penghargaan">Bandar Lampung raih penghargaan</a>
+		<a href="/layanan/slot-antrian">Ambil slot antrian</a>
+		<a href="/dokumen/rtp/">Dokumen RTP</a>
+		<a href="https://toto.co.id/">Mitra sanitasi</a>
+		<ul style="display:none">
+			<li><a href="https://lampungprov.go.id/bandar-lampung">Provinsi Lampung</a></li>
+		</ul>
+	</body>
+	</html>
+	`
+
+	report := cd.AnalyzePage(testContent, "https://bandarlampungkota.go.id/")
+	if signals := cd.DetectCompromise(report); len(signals) != 0 {
+		t.Errorf("Expected no compromise signals on a government page, got %v", signals)
+	}
+	for _, path := range []string{"/layanan/slot-antrian", "/dokumen/rtp/", "/bandar-lampung/zeus/"} {
+		if cd.IsGamblingURL(path) {
+			t.Errorf("Did not expect %s to be a gambling URL", path)
+		}
+	}
+}
+
+// TestCompromiseCrawlFiles tests gambling URL matching and robots.txt and sitemap parsing
+func TestCompromiseCrawlFiles(t *testing.T) {
+	cd := NewCompromiseDetector()
+
+	for rawURL, expected := range map[string]bool{
+		"https://dinas.go.id/page/slot-gacor-hari-ini": true,
+		"https://dinas.go.id/?s=slotgacor777":          true,
+		"https://dinas.go.id/berita/pengumuman-2024":   false,
+		"https://dinas.go.id/images/slotted-bar.png":   false,
+		"https://dinas.go.id/rtp/":                     false,
+		"https://dinas.go.id/rtp-slot/":                true,
+		"https://dinas.go.id/artikel/gotong-royong":    false,
+	} {
+		if got := cd.IsGamblingURL(rawURL); got != expected {
+			t.Errorf("IsGamblingURL(%s) = %v, expected %v", rawURL, got, expected)
+		}
+	}
+
+	paths, sitemaps := RobotsEntries([]byte("User-agent: *\nDisallow: /wp-admin/ # admin\nAllow: /slot-gacor/\nSitemap: https://dinas.go.id/sitemap_index.xml\n"))
+	if len(paths) != 2 || paths[1] != "/slot-gacor/" {
+		t.Errorf("Unexpected robots.txt paths %v", paths)
+	}
+	if len(sitemaps) != 1 || sitemaps[0] != "https://dinas.go.id/sitemap_index.xml" {
+		t.Errorf("Unexpected robots.txt sitemaps %v", sitemaps)
+	}
+
+	pages, nested, err := SitemapURLs([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://dinas.go.id/</loc></url>
+  <url><loc> https://dinas.go.id/slot-maxwin/ </loc></url>
+</urlset>`))
+	if err != nil || len(pages) != 2 || pages[1] != "https://dinas.go.id/slot-maxwin/" || len(nested) != 0 {
+		t.Errorf("Unexpected sitemap pages %v, nested %v, err %v", pages, nested, err)
+	}
+}
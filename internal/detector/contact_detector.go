@@ -0,0 +1,219 @@
+package detector
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// Contact channel resource types
+const (
+	ResourceWhatsApp = "whatsapp"
+	ResourceTelegram = "telegram"
+	ResourceLine     = "line"
+	ResourceLiveChat = "livechat"
+	ResourceTawk     = "tawkto"
+	ResourceJivoChat = "jivochat"
+)
+
+// ContactDetector extracts customer-service contact channels that operators
+// reuse across their mirror domains
+type ContactDetector struct {
+	Patterns map[string][]*regexp.Regexp
+}
+
+// NewContactDetector creates a new contact channel detector
+func NewContactDetector() *ContactDetector {
+	cd := &ContactDetector{
+		Patterns: make(map[string][]*regexp.Regexp),
+	}
+
+	cd.compileContactPatterns()
+
+	return cd
+}
+
+// compileContactPatterns compiles the link and widget patterns for each channel.
+// Every pattern captures the identifier in its first group.
+func (cd *ContactDetector) compileContactPatterns() {
+	cd.Patterns[ResourceWhatsApp] = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\bwa\.me/(?:%2B|\+)?([0-9][0-9\-]{7,18})`),
+		regexp.MustCompile(`(?i)(?:api|web)\.whatsapp\.com/send/?\?(?:[^"'\s<>]*?&(?:amp;)?)?phone=(?:%2B|\+)?([0-9][0-9\-]{7,18})`),
+	}
+	cd.Patterns[ResourceTelegram] = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\b(?:t|telegram)\.me/(?:s/)?((?:joinchat/|\+)?[A-Za-z0-9_\-]{5,32})`),
+		regexp.MustCompile(`(?i)tg://resolve\?domain=([A-Za-z0-9_]{5,32})`),
+	}
+	cd.Patterns[ResourceLine] = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\bline\.me/(?:R/)?ti/p/([~@]?[A-Za-z0-9_\-.%]{3,40})`),
+		regexp.MustCompile(`(?i)\blin\.ee/([A-Za-z0-9]{5,16})`),
+	}
+	cd.Patterns[ResourceLiveChat] = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)__lc\.license\s*=\s*["']?([0-9]{5,10})`),
+		regexp.MustCompile(`(?i)(?:direct\.lc\.chat|secure\.livechatinc\.com/licence)/([0-9]{5,10})`),
+	}
+	cd.Patterns[ResourceTawk] = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)(?:embed\.tawk\.to|tawk\.to/chat)/([0-9a-f]{24})`),
+	}
+	cd.Patterns[ResourceJivoChat] = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)code\.jivo(?:site\.com|\.ru)/(?:script/)?widget/([A-Za-z0-9]{6,16})`),
+	}
+}
+
+// ExtractContacts returns the normalized contact channels found in content
+func (cd *ContactDetector) ExtractContacts(content string) []models.Resource {
+	return extractPatternResources(cd.Patterns, content, normalizeContact)
+}
+
+// DetectContactChannels produces one signal per contact channel found in content
+func (cd *ContactDetector) DetectContactChannels(content string) []models.Signal {
+	var signals []models.Signal
+
+	for _, resource := range cd.ExtractContacts(content) {
+		signal := models.Signal{
+			SignalID:    "contact_" + resource.Type,
+			Category:    "INFRA",
+			Description: "Found " + contactLabel(resource.Type) + " contact: " + resource.Value,
+			Confidence:  cd.getContactConfidence(resource.Type),
+			Evidence: []models.Evidence{
+				{
+					Type:      "html",
+					Reference: "Found " + contactLabel(resource.Type) + " identifier '" + resource.Value + "' in links or scripts",
+					Timestamp: time.Now(),
+				},
+			},
+		}
+		signals = append(signals, signal)
+	}
+
+	return signals
+}
+
+// getContactConfidence returns confidence level for different contact channels
+func (cd *ContactDetector) getContactConfidence(resourceType string) float64 {
+	switch resourceType {
+	case ResourceLiveChat, ResourceTawk, ResourceJivoChat:
+		// Widget accounts are registered by the operator and rarely shared
+		return 0.6
+	default:
+		return 0.5
+	}
+}
+
+// telegramReservedPaths are t.me paths that are not user or channel handles
+var telegramReservedPaths = map[string]bool{
+	"share": true, "addstickers": true, "proxy": true, "socks": true,
+	"setlanguage": true, "addtheme": true, "iv": true, "login": true,
+}
+
+// normalizeContact converts a raw identifier into its canonical form so the
+// same channel matches across pages. It returns "" for identifiers that are
+// not usable as a shared resource.
+func normalizeContact(resourceType, raw string) string {
+	// PathUnescape keeps the '+' of t.me/+HASH invite links
+	if decoded, err := url.PathUnescape(raw); err == nil {
+		raw = decoded
+	}
+	raw = strings.TrimSpace(raw)
+
+	switch resourceType {
+	case ResourceWhatsApp:
+		return normalizePhoneNumber(raw)
+	case ResourceTelegram:
+		// Invite links are case-sensitive hashes, handles are not
+		if strings.HasPrefix(raw, "+") {
+			return raw
+		}
+		if strings.HasPrefix(strings.ToLower(raw), "joinchat/") {
+			return "+" + raw[len("joinchat/"):]
+		}
+		handle := strings.ToLower(strings.TrimPrefix(raw, "@"))
+		if telegramReservedPaths[handle] {
+			return ""
+		}
+		return handle
+	case ResourceLine:
+		return strings.ToLower(strings.TrimLeft(raw, "~"))
+	default:
+		return strings.ToLower(raw)
+	}
+}
+
+// normalizePhoneNumber strips separators from a phone number. wa.me and
+// phone= links already carry international numbers, so only a domestic
+// Indonesian trunk prefix is rewritten (e.g. 0812-3456 -> 628123456).
+func normalizePhoneNumber(raw string) string {
+	var digits strings.Builder
+	for _, r := range raw {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+
+	number := digits.String()
+	if strings.HasPrefix(number, "0") {
+		number = "62" + number[1:]
+	}
+
+	if len(number) < 9 {
+		return ""
+	}
+	return number
+}
+
+// contactLabel returns a human-readable name for a contact channel
+func contactLabel(resourceType string) string {
+	switch resourceType {
+	case ResourceWhatsApp:
+		return "WhatsApp"
+	case ResourceTelegram:
+		return "Telegram"
+	case ResourceLine:
+		return "LINE"
+	case ResourceLiveChat:
+		return "LiveChat"
+	case ResourceTawk:
+		return "Tawk.to"
+	case ResourceJivoChat:
+		return "JivoChat"
+	default:
+		return resourceType
+	}
+}
+
+// extractPatternResources runs typed patterns over content and returns the
+// unique, normalized identifiers captured by their first group
+func extractPatternResources(patterns map[string][]*regexp.Regexp, content string, normalize func(resourceType, raw string) string) []models.Resource {
+	var resources []models.Resource
+	seen := make(map[models.Resource]bool)
+
+	// Iterate types in a stable order so output is deterministic
+	types := make([]string, 0, len(patterns))
+	for resourceType := range patterns {
+		types = append(types, resourceType)
+	}
+	sort.Strings(types)
+
+	for _, resourceType := range types {
+		for _, pattern := range patterns[resourceType] {
+			for _, match := range pattern.FindAllStringSubmatch(content, -1) {
+				value := normalize(resourceType, match[1])
+				if value == "" {
+					continue
+				}
+
+				resource := models.Resource{Type: resourceType, Value: value}
+				if !seen[resource] {
+					seen[resource] = true
+					resources = append(resources, resource)
+				}
+			}
+		}
+	}
+
+	return resources
+}
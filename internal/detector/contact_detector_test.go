@@ -0,0 +1,63 @@
+package detector
+
+import (
+	"testing"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// TestExtractContacts tests contact channel extraction and normalization
+func TestExtractContacts(t *testing.T) {
+	cd := NewContactDetector()
+
+	testContent := `
+	<html>
+	<body>
+		<a href="https://wa.me/+62812-3456-7890">WhatsApp CS</a>
+		<a href="https://api.whatsapp.com/send?text=halo&amp;phone=081234567890">Chat</a>
+		<a href="https://t.me/CS_Gacor88">Telegram</a>
+		<a href="https://t.me/+AbCdEfGh12345">Grup VIP</a>
+		<a href="https://wa.me/85512345678">CS Kamboja</a>
+		<a href="https://t.me/share/url?url=x">Share</a>
+		<a href="https://line.me/R/ti/p/~gacor88cs">LINE</a>
+		<p>Visit start.me/ for more</p>
+		<script>window.__lc = window.__lc || {}; window.__lc.license = 12345678;</script>
+		<script src="https://embed.tawk.to/5f1a2b3c4d5e6f7a8b9c0d1e/default"></script>
+		<script src="//code.jivosite.com/widget/AbCdEf1234"></script>
+	</body>
+	</html>
+	`
+
+	resources := cd.ExtractContacts(testContent)
+
+	expected := []models.Resource{
+		{Type: ResourceWhatsApp, Value: "6281234567890"},
+		{Type: ResourceTelegram, Value: "cs_gacor88"},
+		{Type: ResourceTelegram, Value: "+AbCdEfGh12345"},
+		{Type: ResourceWhatsApp, Value: "85512345678"},
+		{Type: ResourceLine, Value: "gacor88cs"},
+		{Type: ResourceLiveChat, Value: "12345678"},
+		{Type: ResourceTawk, Value: "5f1a2b3c4d5e6f7a8b9c0d1e"},
+		{Type: ResourceJivoChat, Value: "abcdef1234"},
+	}
+
+	found := make(map[models.Resource]bool)
+	for _, resource := range resources {
+		found[resource] = true
+	}
+
+	for _, resource := range expected {
+		if !found[resource] {
+			t.Errorf("Expected to extract %s resource %s, got %v", resource.Type, resource.Value, resources)
+		}
+	}
+
+	if len(resources) != len(expected) {
+		t.Errorf("Expected %d unique resources, got %d: %v", len(expected), len(resources), resources)
+	}
+
+	signals := cd.DetectContactChannels(testContent)
+	if len(signals) != len(expected) {
+		t.Errorf("Expected one signal per contact channel, got %d", len(signals))
+	}
+}
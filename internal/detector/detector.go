@@ -1,8 +1,9 @@
 package detector
 
 import (
-	"crypto/tls"
+	"context"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"regexp"
@@ -10,11 +11,24 @@ import (
 	"time"
 
 	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/resolver"
 )
 
 // OriginIPDetector detects potential origin IPs behind CDNs
 type OriginIPDetector struct {
 	Client *http.Client
+	// Resolver answers the DNS lookups; ISP resolvers in Indonesia rewrite
+	// blocked domains, so scans can query an explicit, DoH or DoT resolver
+	Resolver resolver.Resolver
+	// Subdomains are the enumerated subdomains of the scanned domain; when
+	// nil they are enumerated from Wordlist
+	Subdomains []models.Subdomain
+	Wordlist   []string
+	// Ranges are the published CDN and cloud ranges; an address outside the
+	// CDN ranges is an origin candidate
+	Ranges *IPRanges
+	// Engine identifies CDNs from HTTP responses
+	Engine *CDNEngine
 }
 
 // NewOriginIPDetector creates a new instance of OriginIPDetector
@@ -23,8 +37,17 @@ func NewOriginIPDetector() *OriginIPDetector {
 		Timeout: 10 * time.Second,
 	}
 	
+	wordlist, _ := LoadSubdomainWordlist("")
+	catalog, _ := LoadCDNRanges("")
+	ranges, _ := NewIPRanges(catalog)
+	fingerprints, _ := LoadCDNFingerprints("")
+
 	return &OriginIPDetector{
-		Client: client,
+		Client:   client,
+		Resolver: resolver.NewSystem(resolver.DefaultTimeout),
+		Wordlist: wordlist,
+		Ranges:   ranges,
+		Engine:   NewCDNEngine(fingerprints, ranges),
 	}
 }
 
@@ -59,43 +82,29 @@ func (d *OriginIPDetector) DetectOriginIPs(domain string) ([]string, []models.Ev
 	return uniqueIPs, evidence, nil
 }
 
-// checkSubdomains checks common subdomains that might not be CDN-protected
+// checkSubdomains returns the addresses of subdomains served directly,
+// not through a CDN
 func (d *OriginIPDetector) checkSubdomains(domain string) ([]string, []models.Evidence) {
 	var ips []string
 	var evidence []models.Evidence
-	
-	subdomains := []string{
-		"mail", "webmail", "autodiscover", "autoconfig", 
-		"cpanel", "whm", "ftp", "smtp", "pop", "imap",
-		"ns1", "ns2", "ns3", "ns4", "dns1", "dns2",
-		"dev", "staging", "test", "admin", "api",
-		"shop", "blog", "m", "mobile", "api", "cdn",
-		"img", "images", "static", "media", "video",
+
+	subdomains := d.Subdomains
+	if subdomains == nil {
+		enumerator := NewSubdomainEnumerator(d.Resolver, d.Wordlist)
+		enumerator.CDNStatus = d.CDNStatusOf
+		subdomains = enumerator.Enumerate(context.Background(), domain, nil)
 	}
 
 	for _, subdomain := range subdomains {
-		fullDomain := fmt.Sprintf("%s.%s", subdomain, domain)
-		
-		// Resolve the subdomain to IP
-		ip, err := net.ResolveIPAddr("ip4", fullDomain)
-		if err != nil {
+		if subdomain.CDN != "none" {
 			continue
 		}
-		
-		// Check if this subdomain is behind the same CDN
-		isBehindCDN := d.isBehindCDN(fullDomain)
-		
-		// If not behind CDN, this might be the origin IP
-		if !isBehindCDN {
-			ips = append(ips, ip.String())
-			evidence = append(evidence, models.Evidence{
-				Type:      "dns",
-				Reference: fmt.Sprintf("Subdomain %s resolves to IP %s (not behind CDN)", fullDomain, ip.String()),
-				Timestamp: time.Now(),
-			})
+		for _, ip := range subdomain.Addresses {
+			ips = append(ips, ip)
+			evidence = append(evidence, d.dnsEvidence(fmt.Sprintf("Subdomain %s resolves to IP %s (not behind CDN)", subdomain.Name, ip)))
 		}
 	}
-	
+
 	return ips, evidence
 }
 
@@ -115,7 +124,7 @@ func (d *OriginIPDetector) checkHistoricalDNS(domain string) ([]string, []models
 	// This is a simplified approach
 	
 	// Resolve current domain
-	currentIPs, err := net.LookupIP(domain)
+	currentIPs, err := resolver.LookupIPv4(context.Background(), d.Resolver, domain)
 	if err != nil {
 		return ips, evidence
 	}
@@ -124,14 +133,11 @@ func (d *OriginIPDetector) checkHistoricalDNS(domain string) ([]string, []models
 	// to find when the domain was not behind CDN
 	
 	for _, ip := range currentIPs {
-		if ip.To4() != nil { // IPv4 only
-			ips = append(ips, ip.String())
-			evidence = append(evidence, models.Evidence{
-				Type:      "dns",
-				Reference: fmt.Sprintf("Current DNS record for %s points to IP %s", domain, ip.String()),
-				Timestamp: time.Now(),
-			})
+		if d.Ranges.CDN(ip) != "" {
+			continue
 		}
+		ips = append(ips, ip)
+		evidence = append(evidence, d.dnsEvidence(fmt.Sprintf("Current DNS record for %s points to IP %s", domain, ip)))
 	}
 
 	return ips, evidence
@@ -142,27 +148,30 @@ func (d *OriginIPDetector) checkMXRecords(domain string) ([]string, []models.Evi
 	var ips []string
 	var evidence []models.Evidence
 
-	mxRecords, err := net.LookupMX(domain)
+	mxRecords, err := d.Resolver.Lookup(context.Background(), domain, "MX")
 	if err != nil {
 		return ips, evidence
 	}
 
 	for _, mx := range mxRecords {
+		fields := strings.Fields(mx.Value)
+		if mx.Type != "MX" || len(fields) != 2 {
+			continue
+		}
+		host := fields[1]
+
 		// Resolve the MX host to IP
-		mxIPs, err := net.LookupIP(mx.Host)
+		mxIPs, err := resolver.LookupIPv4(context.Background(), d.Resolver, host)
 		if err != nil {
 			continue
 		}
 
 		for _, ip := range mxIPs {
-			if ip.To4() != nil { // IPv4 only
-				ips = append(ips, ip.String())
-				evidence = append(evidence, models.Evidence{
-					Type:      "dns",
-					Reference: fmt.Sprintf("MX record %s for %s resolves to IP %s", mx.Host, domain, ip.String()),
-					Timestamp: time.Now(),
-				})
+			if d.Ranges.CDN(ip) != "" {
+				continue
 			}
+			ips = append(ips, ip)
+			evidence = append(evidence, d.dnsEvidence(fmt.Sprintf("MX record %s for %s resolves to IP %s", host, domain, ip)))
 		}
 	}
 
@@ -187,42 +196,44 @@ func (d *OriginIPDetector) checkOtherDNSRecords(domain string) ([]string, []mode
 
 	for _, service := range serviceNames {
 		serviceDomain := fmt.Sprintf("%s.%s", service, domain)
-		_, addrs, err := net.LookupSRV("", "", serviceDomain)
+		addrs, err := d.Resolver.Lookup(context.Background(), serviceDomain, "SRV")
 		if err != nil {
 			continue
 		}
 
 		for _, addr := range addrs {
+			// SRV values are "priority weight port target"
+			fields := strings.Fields(addr.Value)
+			if addr.Type != "SRV" || len(fields) != 4 {
+				continue
+			}
+			target := fields[3]
+
 			// Resolve the target to IP
-			ip, err := net.ResolveIPAddr("ip4", strings.TrimSuffix(addr.Target, "."))
-			if err != nil {
+			ip, err := d.firstIPv4(target)
+			if err != nil || d.Ranges.CDN(ip) != "" {
 				continue
 			}
-			
-			ips = append(ips, ip.String())
-			evidence = append(evidence, models.Evidence{
-				Type:      "dns",
-				Reference: fmt.Sprintf("SRV record %s points to %s which resolves to IP %s", serviceDomain, addr.Target, ip.String()),
-				Timestamp: time.Now(),
-			})
+
+			ips = append(ips, ip)
+			evidence = append(evidence, d.dnsEvidence(fmt.Sprintf("SRV record %s points to %s which resolves to IP %s", serviceDomain, target, ip)))
 		}
 	}
 
 	// Check for TXT records that might contain IP addresses
-	txtRecords, err := net.LookupTXT(domain)
+	txtRecords, err := d.Resolver.Lookup(context.Background(), domain, "TXT")
 	if err == nil {
 		ipRegex := regexp.MustCompile(`\b(?:[0-9]{1,3}\.){3}[0-9]{1,3}\b`)
 		for _, txt := range txtRecords {
-			matches := ipRegex.FindAllString(txt, -1)
+			if txt.Type != "TXT" {
+				continue
+			}
+			matches := ipRegex.FindAllString(txt.Value, -1)
 			for _, ip := range matches {
 				// Validate that it's a real IP
-				if net.ParseIP(ip) != nil {
+				if net.ParseIP(ip) != nil && d.Ranges.CDN(ip) == "" {
 					ips = append(ips, ip)
-					evidence = append(evidence, models.Evidence{
-						Type:      "dns",
-						Reference: fmt.Sprintf("TXT record contains potential IP: %s", ip),
-						Timestamp: time.Now(),
-					})
+					evidence = append(evidence, d.dnsEvidence(fmt.Sprintf("TXT record contains potential IP: %s", ip)))
 				}
 			}
 		}
@@ -231,78 +242,35 @@ func (d *OriginIPDetector) checkOtherDNSRecords(domain string) ([]string, []mode
 	return ips, evidence
 }
 
-// isBehindCDN checks if a domain is behind a CDN
-func (d *OriginIPDetector) isBehindCDN(domain string) bool {
-	// Make a request to the domain
-	url := fmt.Sprintf("http://%s", domain)
-	resp, err := d.Client.Get(url)
+// firstIPv4 returns the first IPv4 address of host
+func (d *OriginIPDetector) firstIPv4(host string) (string, error) {
+	ips, err := resolver.LookupIPv4(context.Background(), d.Resolver, host)
 	if err != nil {
-		// Try HTTPS
-		url = fmt.Sprintf("https://%s", domain)
-		resp, err = d.Client.Get(url)
-		if err != nil {
-			return true // Assume CDN if we can't connect
-		}
+		return "", err
 	}
-	defer resp.Body.Close()
-
-	// Check for CDN-specific headers
-	return d.checkCDNHeaders(resp.Header) || d.checkCDNCertificates(resp.TLS)
-}
-
-// checkCDNHeaders checks response headers for CDN indicators
-func (d *OriginIPDetector) checkCDNHeaders(headers http.Header) bool {
-	// Cloudflare headers
-	if headers.Get("server") == "cloudflare" ||
-		headers.Get("cf-ray") != "" ||
-		headers.Get("cf-request-id") != "" {
-		return true
-	}
-
-	// CloudFront headers
-	if strings.Contains(headers.Get("x-cache"), "cloudfront") ||
-		headers.Get("x-amz-cf-pop") != "" {
-		return true
+	if len(ips) == 0 {
+		return "", fmt.Errorf("no IPv4 address for %s", host)
 	}
-
-	// Akamai headers
-	if headers.Get("x-akamai-transformed") != "" ||
-		headers.Get("server") == "AkamaiGHost" {
-		return true
-	}
-
-	// Other common CDN headers
-	if strings.Contains(headers.Get("via"), "cloudflare") ||
-		strings.Contains(headers.Get("via"), "amazon") ||
-		strings.Contains(headers.Get("via"), "akamai") {
-		return true
-	}
-
-	return false
+	return ips[0], nil
 }
 
-// checkCDNCertificates checks if the TLS certificate indicates CDN usage
-func (d *OriginIPDetector) checkCDNCertificates(connState *tls.ConnectionState) bool {
-	if connState == nil {
-		return false
+// dnsEvidence builds DNS evidence naming the resolver that answered
+func (d *OriginIPDetector) dnsEvidence(reference string) models.Evidence {
+	return models.Evidence{
+		Type:      "dns",
+		Reference: reference,
+		Source:    d.Resolver.Name(),
+		Timestamp: time.Now(),
 	}
+}
 
-	for _, cert := range connState.PeerCertificates {
-		// Check if certificate contains CDN-related strings
-		if strings.Contains(strings.ToLower(cert.Subject.CommonName), "cloudflaressl") ||
-			strings.Contains(strings.ToLower(cert.Subject.CommonName), "cloudflare") {
-			return true
-		}
-
-		for _, name := range cert.DNSNames {
-			if strings.Contains(strings.ToLower(name), "cloudflaressl") ||
-				strings.Contains(strings.ToLower(name), "cloudflare") {
-				return true
-			}
-		}
+// fetch requests a host over HTTP, then HTTPS
+func (d *OriginIPDetector) fetch(host string) (*http.Response, error) {
+	resp, err := d.Client.Get(fmt.Sprintf("http://%s", host))
+	if err != nil {
+		return d.Client.Get(fmt.Sprintf("https://%s", host))
 	}
-
-	return false
+	return resp, nil
 }
 
 // removeDuplicates removes duplicate IPs from a slice
@@ -322,28 +290,38 @@ func removeDuplicates(ipList []string) []string {
 
 // CheckDomainCDNStatus checks if a domain is protected by CDN
 func (d *OriginIPDetector) CheckDomainCDNStatus(domain string) string {
-	if d.isBehindCDN(domain) {
-		// Try to identify which CDN
-		url := fmt.Sprintf("https://%s", domain)
-		resp, err := d.Client.Get(url)
-		if err != nil {
-			return "unknown"
+	addresses, _ := resolver.LookupIPv4(context.Background(), d.Resolver, domain)
+	return d.CDNStatusOf(domain, addresses)
+}
+
+// CDNStatusOf names the CDN serving host at addresses: the CDN whose
+// published ranges hold an address, else the CDN the fingerprints of its
+// responses show. It is
+// "none" when host is served directly, and "unknown" when host has no
+// address and does not answer. A host that does not answer is not assumed
+// to be behind a CDN.
+func (d *OriginIPDetector) CDNStatusOf(host string, addresses []string) string {
+	for _, address := range addresses {
+		if cdn := d.Ranges.CDN(address); cdn != "" {
+			return cdn
 		}
-		defer resp.Body.Close()
+	}
 
-		if d.checkCDNHeaders(resp.Header) {
-			if resp.Header.Get("server") == "cloudflare" || 
-				resp.Header.Get("cf-ray") != "" {
-				return "cloudflare"
-			} else if strings.Contains(resp.Header.Get("x-cache"), "cloudfront") {
-				return "cloudfront"
-			} else if resp.Header.Get("server") == "AkamaiGHost" {
-				return "akamai"
-			}
+	resp, err := d.fetch(host)
+	if err != nil {
+		if len(addresses) == 0 {
+			return "unknown"
 		}
+		return "none"
 	}
+	defer resp.Body.Close()
 
-	return "none"
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	return d.Engine.Identify(CDNObservation{
+		Headers: resp.Header,
+		TLS:     resp.TLS,
+		Body:    string(body),
+	}).Name
 }
 
 // GetCDNProviderDetails returns detailed information about CDN usage
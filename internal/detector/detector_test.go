@@ -0,0 +1,45 @@
+package detector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/resolver"
+	"github.com/genesis410/fogger/internal/resolver/resolvertest"
+)
+
+// TestOriginIPRecordsUseResolver tests that MX, SRV and TXT lookups go to the
+// configured resolver and that the evidence names it
+func TestOriginIPRecordsUseResolver(t *testing.T) {
+	server, err := resolvertest.NewServer([]models.DNSRecord{
+		{Name: "gacor88.xyz", Type: "MX", TTL: 3600, Value: "10 mail.gacor88.xyz"},
+		{Name: "gacor88.xyz", Type: "TXT", TTL: 3600, Value: "v=spf1 ip4:198.51.100.7 -all"},
+		{Name: "mail.gacor88.xyz", Type: "A", TTL: 3600, Value: "198.51.100.5"},
+		{Name: "_sip._tcp.gacor88.xyz", Type: "SRV", TTL: 3600, Value: "10 5 5060 voip.gacor88.xyz"},
+		{Name: "voip.gacor88.xyz", Type: "A", TTL: 3600, Value: "198.51.100.6"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start DNS server: %v", err)
+	}
+	defer server.Close()
+
+	od := NewOriginIPDetector()
+	od.Resolver = resolver.NewServer(server.Addr, false, time.Second)
+
+	mxIPs, mxEvidence := od.checkMXRecords("gacor88.xyz")
+	otherIPs, otherEvidence := od.checkOtherDNSRecords("gacor88.xyz")
+	ips := strings.Join(append(mxIPs, otherIPs...), " ")
+	for _, expected := range []string{"198.51.100.5", "198.51.100.6", "198.51.100.7"} {
+		if !strings.Contains(ips, expected) {
+			t.Errorf("Expected origin IP %s, got %s", expected, ips)
+		}
+	}
+
+	for _, evidence := range append(mxEvidence, otherEvidence...) {
+		if evidence.Source != od.Resolver.Name() {
+			t.Errorf("Expected evidence from %s, got %+v", od.Resolver.Name(), evidence)
+		}
+	}
+}
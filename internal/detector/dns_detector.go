@@ -0,0 +1,277 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/resolver"
+)
+
+// DNSRecordTypes are the record types collected for each domain
+var DNSRecordTypes = []string{"A", "AAAA", "CNAME", "NS", "SOA", "MX", "TXT", "CAA"}
+
+// DNSDetector collects the DNS records of a domain and detects setups typical
+// of judol sites: fresh nameserver changes, free DNS hosting, short TTLs that
+// allow fast IP rotation and wildcard records that serve any subdomain
+type DNSDetector struct {
+	Resolver resolver.Resolver
+	// FreeDNSProviders maps nameserver suffixes of free DNS hosts to a provider name
+	FreeDNSProviders map[string]string
+	// ShortTTL is the largest address record TTL considered short, in seconds
+	ShortTTL uint32
+	// FreshZoneDays is how recent a date-based SOA serial must be to count as a fresh change
+	FreshZoneDays int
+}
+
+// DNSSnapshot holds the DNS records collected for a domain
+type DNSSnapshot struct {
+	Domain string
+	// Zone is the name the NS and SOA records were found at
+	Zone    string
+	Records []models.DNSRecord
+	// Wildcard holds the answers for a random subdomain, present when the
+	// zone has wildcard records
+	Wildcard []models.DNSRecord
+	// PreviousNS are the nameservers recorded by an earlier scan, if any
+	PreviousNS []string
+	// Resolver names the resolver that answered
+	Resolver    string
+	CollectedAt time.Time
+}
+
+// NewDNSDetector creates a new DNS detector querying through r
+func NewDNSDetector(r resolver.Resolver) *DNSDetector {
+	return &DNSDetector{
+		Resolver: r,
+		FreeDNSProviders: map[string]string{
+			"ns.cloudflare.com":     "Cloudflare",
+			"afraid.org":            "FreeDNS (afraid.org)",
+			"he.net":                "Hurricane Electric Free DNS",
+			"cloudns.net":           "ClouDNS",
+			"dynu.com":              "Dynu",
+			"1984.is":               "1984 Hosting",
+			"1984hosting.com":       "1984 Hosting",
+			"desec.io":              "deSEC",
+			"desec.org":             "deSEC",
+			"zoneedit.com":          "ZoneEdit",
+			"dnsexit.com":           "DNSExit",
+			"freenom.com":           "Freenom",
+			"dns-parking.com":       "Hostinger parking",
+			"nsone.net":             "NS1 (free tier)",
+			"registrar-servers.com": "Namecheap FreeDNS",
+		},
+		ShortTTL:      120,
+		FreshZoneDays: 7,
+	}
+}
+
+// CollectDNS queries every collected record type for a domain. NS and SOA
+// records are looked up at the closest parent that has them, since a
+// scanned host (www.example.com) is often not the zone apex.
+func (dd *DNSDetector) CollectDNS(ctx context.Context, domain string) (*DNSSnapshot, error) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	snapshot := &DNSSnapshot{Domain: domain, Resolver: dd.Resolver.Name(), CollectedAt: time.Now()}
+
+	var errs []string
+	for _, recordType := range DNSRecordTypes {
+		if recordType == "NS" || recordType == "SOA" {
+			continue
+		}
+		records, err := dd.Resolver.Lookup(ctx, domain, recordType)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		snapshot.Records = appendDNSRecords(snapshot.Records, records)
+	}
+
+	for zone := domain; strings.Count(zone, ".") >= 1; zone = zone[strings.Index(zone, ".")+1:] {
+		ns, err := dd.Resolver.Lookup(ctx, zone, "NS")
+		if err != nil {
+			errs = append(errs, err.Error())
+			break
+		}
+		ns = recordsOfType(ns, "NS")
+		if len(ns) == 0 {
+			continue
+		}
+		snapshot.Zone = zone
+		snapshot.Records = appendDNSRecords(snapshot.Records, ns)
+		if soa, err := dd.Resolver.Lookup(ctx, zone, "SOA"); err == nil {
+			snapshot.Records = appendDNSRecords(snapshot.Records, recordsOfType(soa, "SOA"))
+		}
+		break
+	}
+
+	// A random label only resolves when the zone has a wildcard
+	probe := fmt.Sprintf("fogger-%08x.%s", rand.Uint32(), domain)
+	if wildcard, err := dd.Resolver.Lookup(ctx, probe, "A"); err == nil {
+		snapshot.Wildcard = wildcard
+	}
+
+	if len(snapshot.Records) == 0 && len(errs) > 0 {
+		return nil, fmt.Errorf("DNS lookups failed: %s", strings.Join(errs, "; "))
+	}
+	return snapshot, nil
+}
+
+// DetectDNS produces DNS signals from a snapshot
+func (dd *DNSDetector) DetectDNS(snapshot *DNSSnapshot) []models.Signal {
+	var signals []models.Signal
+
+	nameservers := DNSValues(snapshot.Records, "NS")
+
+	// Nameservers changed since the last scan
+	if len(snapshot.PreviousNS) > 0 && len(nameservers) > 0 && !sameStrings(nameservers, snapshot.PreviousNS) {
+		signals = append(signals, dnsSignal(snapshot, "DNS_NS_CHANGED",
+			"Nameservers changed since the last scan", 0.75,
+			fmt.Sprintf("Nameservers changed from %s to %s",
+				strings.Join(snapshot.PreviousNS, ", "), strings.Join(nameservers, ", "))))
+	}
+
+	// Date-based SOA serials show when the zone was last edited
+	for _, soa := range recordsOfType(snapshot.Records, "SOA") {
+		changed, ok := soaSerialDate(soa.Value)
+		if !ok {
+			continue
+		}
+		age := snapshot.CollectedAt.Sub(changed)
+		if age >= -24*time.Hour && age <= time.Duration(dd.FreshZoneDays)*24*time.Hour {
+			signals = append(signals, dnsSignal(snapshot, "DNS_ZONE_RECENTLY_CHANGED",
+				"DNS zone changed in the last "+strconv.Itoa(dd.FreshZoneDays)+" days", 0.4,
+				"SOA serial of "+snapshot.Zone+" dates the last change to "+changed.Format("2006-01-02")))
+		}
+	}
+
+	// Free DNS hosting
+	providers := make(map[string]bool)
+	var matched []string
+	for _, ns := range nameservers {
+		for suffix, provider := range dd.FreeDNSProviders {
+			if ns == suffix || strings.HasSuffix(ns, "."+suffix) {
+				providers[provider] = true
+				matched = append(matched, ns)
+			}
+		}
+	}
+	if len(providers) > 0 {
+		signals = append(signals, dnsSignal(snapshot, "DNS_FREE_HOSTING",
+			"DNS hosted on free provider: "+strings.Join(sortedKeys(providers), ", "), 0.5,
+			"Nameservers "+strings.Join(matched, ", ")))
+	}
+
+	// Short TTLs on address records allow rotating IPs when one gets blocked
+	var short []string
+	for _, record := range snapshot.Records {
+		if (record.Type == "A" || record.Type == "AAAA" || record.Type == "CNAME") && record.TTL <= dd.ShortTTL {
+			short = append(short, fmt.Sprintf("%s %s %s (TTL %d)", record.Name, record.Type, record.Value, record.TTL))
+		}
+	}
+	if len(short) > 0 {
+		signals = append(signals, dnsSignal(snapshot, "DNS_SHORT_TTL",
+			fmt.Sprintf("Found address records with TTL of %d seconds or less", dd.ShortTTL), 0.5,
+			strings.Join(short, "; ")))
+	}
+
+	// Wildcard records serve any generated subdomain
+	if len(snapshot.Wildcard) > 0 {
+		var answers []string
+		for _, record := range snapshot.Wildcard {
+			answers = append(answers, record.Type+" "+record.Value)
+		}
+		signals = append(signals, dnsSignal(snapshot, "DNS_WILDCARD",
+			"Found wildcard DNS records for *."+snapshot.Domain, 0.6,
+			"Random subdomain "+snapshot.Wildcard[0].Name+" resolves to "+strings.Join(answers, ", ")))
+	}
+
+	return signals
+}
+
+// dnsSignal builds a DNS signal with one piece of evidence naming the
+// resolver of the snapshot
+func dnsSignal(snapshot *DNSSnapshot, signalID, description string, confidence float64, reference string) models.Signal {
+	return models.Signal{
+		SignalID:    signalID,
+		Category:    "DNS",
+		Description: description,
+		Confidence:  confidence,
+		Evidence: []models.Evidence{
+			{
+				Type:      "dns",
+				Reference: reference,
+				Source:    snapshot.Resolver,
+				Timestamp: time.Now(),
+			},
+		},
+	}
+}
+
+// soaSerialDate parses a YYYYMMDDnn SOA serial into the date it encodes
+func soaSerialDate(soa string) (time.Time, bool) {
+	fields := strings.Fields(soa)
+	if len(fields) < 3 || len(fields[2]) != 10 {
+		return time.Time{}, false
+	}
+	changed, err := time.Parse("20060102", fields[2][:8])
+	if err != nil || changed.Year() < 2000 {
+		return time.Time{}, false
+	}
+	return changed, true
+}
+
+// DNSValues returns the sorted unique values of the records of one type
+func DNSValues(records []models.DNSRecord, recordType string) []string {
+	var values []string
+	for _, record := range recordsOfType(records, recordType) {
+		values = appendUniqueString(values, strings.ToLower(record.Value))
+	}
+	sort.Strings(values)
+	return values
+}
+
+// recordsOfType returns the records of one type
+func recordsOfType(records []models.DNSRecord, recordType string) []models.DNSRecord {
+	var matching []models.DNSRecord
+	for _, record := range records {
+		if record.Type == recordType {
+			matching = append(matching, record)
+		}
+	}
+	return matching
+}
+
+// appendDNSRecords appends the records not already present
+func appendDNSRecords(records, more []models.DNSRecord) []models.DNSRecord {
+	for _, record := range more {
+		duplicate := false
+		for _, existing := range records {
+			if existing == record {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// sameStrings reports whether two sorted lists hold the same values
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package detector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// TestDetectDNS tests nameserver changes, fresh zones, free DNS hosting, short TTLs and wildcards
+func TestDetectDNS(t *testing.T) {
+	dd := NewDNSDetector(nil)
+
+	snapshot := &DNSSnapshot{
+		Domain: "gacor88.xyz",
+		Zone:   "gacor88.xyz",
+		Records: []models.DNSRecord{
+			{Name: "gacor88.xyz", Type: "A", TTL: 60, Value: "203.0.113.10"},
+			{Name: "gacor88.xyz", Type: "NS", TTL: 86400, Value: "ada.ns.cloudflare.com"},
+			{Name: "gacor88.xyz", Type: "NS", TTL: 86400, Value: "bob.ns.cloudflare.com"},
+			{Name: "gacor88.xyz", Type: "SOA", TTL: 3600, Value: "ada.ns.cloudflare.com dns.cloudflare.com 2025061502 10000 2400 604800 1800"},
+		},
+		Wildcard: []models.DNSRecord{
+			{Name: "fogger-1a2b3c4d.gacor88.xyz", Type: "A", TTL: 60, Value: "203.0.113.10"},
+		},
+		PreviousNS:  []string{"ns1.domainesia.net", "ns2.domainesia.net"},
+		CollectedAt: time.Date(2025, 6, 18, 0, 0, 0, 0, time.UTC),
+	}
+
+	found := make(map[string]models.Signal)
+	for _, signal := range dd.DetectDNS(snapshot) {
+		if signal.Category != "DNS" || len(signal.Evidence) == 0 {
+			t.Errorf("Expected a DNS signal with evidence, got %+v", signal)
+		}
+		found[signal.SignalID] = signal
+	}
+
+	for _, id := range []string{"DNS_NS_CHANGED", "DNS_ZONE_RECENTLY_CHANGED", "DNS_FREE_HOSTING", "DNS_SHORT_TTL", "DNS_WILDCARD"} {
+		if _, ok := found[id]; !ok {
+			t.Errorf("Expected %s signal, got %v", id, found)
+		}
+	}
+	if reference := found["DNS_NS_CHANGED"].Evidence[0].Reference; !strings.Contains(reference, "ns1.domainesia.net") {
+		t.Errorf("Expected the previous nameservers in the evidence, got %s", reference)
+	}
+}
+
+// TestDetectDNSQuietZone tests that a long-standing self-hosted zone produces no signals
+func TestDetectDNSQuietZone(t *testing.T) {
+	dd := NewDNSDetector(nil)
+
+	snapshot := &DNSSnapshot{
+		Domain: "kemdikbud.go.id",
+		Zone:   "kemdikbud.go.id",
+		Records: []models.DNSRecord{
+			{Name: "kemdikbud.go.id", Type: "A", TTL: 3600, Value: "198.51.100.7"},
+			{Name: "kemdikbud.go.id", Type: "NS", TTL: 86400, Value: "ns1.kemdikbud.go.id"},
+			{Name: "kemdikbud.go.id", Type: "SOA", TTL: 3600, Value: "ns1.kemdikbud.go.id admin.kemdikbud.go.id 2023010101 10800 3600 604800 3600"},
+		},
+		PreviousNS:  []string{"ns1.kemdikbud.go.id"},
+		CollectedAt: time.Date(2025, 6, 18, 0, 0, 0, 0, time.UTC),
+	}
+
+	if signals := dd.DetectDNS(snapshot); len(signals) != 0 {
+		t.Errorf("Expected no DNS signals, got %v", signals)
+	}
+}
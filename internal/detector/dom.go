@@ -0,0 +1,251 @@
+package detector
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// parseHTML parses a page body into a DOM tree. The HTML5 parser recovers
+// from malformed markup, so an error only occurs on read failures.
+func parseHTML(body string) *html.Node {
+	doc, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		return &html.Node{Type: html.DocumentNode}
+	}
+	return doc
+}
+
+// walkElements calls fn for every element node below n in document order.
+// Returning false from fn skips the element's children.
+func walkElements(n *html.Node, fn func(*html.Node) bool) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && !fn(c) {
+			continue
+		}
+		walkElements(c, fn)
+	}
+}
+
+// nodeAttr returns the value of an attribute or "" if it is not set
+func nodeAttr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// nodeText returns the concatenated text content of a node
+func nodeText(n *html.Node) string {
+	var sb strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+			sb.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(n)
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// embeddedURLs returns the lowercase sources of a page's images, scripts and
+// frames, and the hosts of its links. Platform names are only trusted there:
+// in text they are ordinary words or appear in news and reviews.
+func embeddedURLs(doc *html.Node) []string {
+	var urls []string
+	walkElements(doc, func(n *html.Node) bool {
+		switch n.Data {
+		case "img", "script", "iframe", "source", "embed":
+			for _, key := range []string{"src", "data-src", "data-original"} {
+				if value := nodeAttr(n, key); value != "" {
+					urls = append(urls, strings.ToLower(value))
+				}
+			}
+		case "a":
+			if host := urlHost(normalizeLinkURL(nodeAttr(n, "href"))); host != "" {
+				urls = append(urls, host)
+			}
+		}
+		return true
+	})
+	return urls
+}
+
+// containsNameInURLs reports whether a multi-word name appears in one of
+// urls, written with no separator, '-' or '_' between its words
+func containsNameInURLs(urls []string, name string) bool {
+	variants := []string{
+		strings.ReplaceAll(name, " ", ""),
+		strings.ReplaceAll(name, " ", "-"),
+		strings.ReplaceAll(name, " ", "_"),
+	}
+	for _, u := range urls {
+		for _, variant := range variants {
+			if containsWord(u, variant) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// visibleText returns the text content of a node, leaving out scripts,
+// styles and other elements that are not rendered as text
+func visibleText(n *html.Node) string {
+	var sb strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.ElementNode && signatureSkipTags[n.Data] {
+			return
+		}
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+			sb.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(n)
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// offscreenRegex matches large negative offsets used to push content off-screen
+var offscreenRegex = regexp.MustCompile(`(?:^|;)\s*(?:left|top|text-indent|margin-left|margin-top)\s*:\s*-\d{3,}`)
+
+// zeroFontSizeRegex matches a font size of zero in any unit
+var zeroFontSizeRegex = regexp.MustCompile(`;font-size:0(?:\.0+)?(?:px|em|rem|pt|%)?(?:;|!|$)`)
+
+// classRuleRegex matches a single-class rule of a <style> block
+var classRuleRegex = regexp.MustCompile(`\.([a-zA-Z][\w-]*)\s*\{([^}]*)\}`)
+
+// textRun is the text of one text node and how it is hidden from visitors,
+// "" when it is visible
+type textRun struct {
+	Text      string
+	Technique string
+}
+
+// hidingTechnique returns how an element hides its content from visitors
+// through its tag, attributes, inline style or a class hidden by the page's
+// stylesheets, or "" if it does not
+func hidingTechnique(n *html.Node, hiddenClasses map[string]string) string {
+	if n.Data == "marquee" {
+		return "marquee"
+	}
+	if _, hidden := attrValue(n, "hidden"); hidden {
+		return "hidden attribute"
+	}
+	if technique := styleHidingTechnique(nodeAttr(n, "style")); technique != "" {
+		return technique
+	}
+	for _, class := range strings.Fields(nodeAttr(n, "class")) {
+		if technique, ok := hiddenClasses[class]; ok {
+			return technique + " (class ." + class + ")"
+		}
+	}
+	return ""
+}
+
+// styleHidingTechnique returns how a list of CSS declarations hides an
+// element, or "" if it does not
+func styleHidingTechnique(style string) string {
+	style = strings.ToLower(strings.Join(strings.Fields(style), ""))
+	if style == "" {
+		return ""
+	}
+	declarations := ";" + style
+	switch {
+	case strings.Contains(declarations, ";display:none"):
+		return "display:none"
+	case strings.Contains(declarations, ";visibility:hidden"):
+		return "visibility:hidden"
+	case strings.Contains(declarations, ";opacity:0;") || strings.HasSuffix(declarations, ";opacity:0"):
+		return "opacity:0"
+	case zeroFontSizeRegex.MatchString(declarations):
+		return "font-size:0"
+	case offscreenRegex.MatchString(style):
+		return "off-screen position"
+	case (strings.Contains(declarations, ";height:0") || strings.Contains(declarations, ";width:0")) &&
+		strings.Contains(declarations, ";overflow:hidden"):
+		return "zero size"
+	}
+	return ""
+}
+
+// stylesheetHiddenClasses returns the classes the page's <style> blocks hide,
+// with the technique they use
+func stylesheetHiddenClasses(doc *html.Node) map[string]string {
+	classes := make(map[string]string)
+	walkElements(doc, func(n *html.Node) bool {
+		if n.Data != "style" {
+			return true
+		}
+		for _, rule := range classRuleRegex.FindAllStringSubmatch(nodeText(n), -1) {
+			if technique := styleHidingTechnique(rule[2]); technique != "" {
+				classes[rule[1]] = technique
+			}
+		}
+		return false
+	})
+	return classes
+}
+
+// textRuns classifies every text node of a page as visible or hidden. Text
+// in <noscript> only shows with scripts disabled and counts as hidden.
+func textRuns(doc *html.Node) []textRun {
+	hiddenClasses := stylesheetHiddenClasses(doc)
+
+	var runs []textRun
+	var walk func(n *html.Node, technique string)
+	walk = func(n *html.Node, technique string) {
+		switch n.Type {
+		case html.ElementNode:
+			if n.Data == "noscript" {
+				// With scripting enabled the parser keeps noscript content as raw markup
+				for c := n.FirstChild; c != nil; c = c.NextSibling {
+					if c.Type == html.TextNode {
+						if text := nodeText(parseHTML(c.Data)); text != "" {
+							runs = append(runs, textRun{Text: text, Technique: "noscript"})
+						}
+					} else {
+						walk(c, "noscript")
+					}
+				}
+				return
+			}
+			if signatureSkipTags[n.Data] {
+				return
+			}
+			if technique == "" {
+				technique = hidingTechnique(n, hiddenClasses)
+			}
+		case html.TextNode:
+			if text := strings.Join(strings.Fields(n.Data), " "); text != "" {
+				runs = append(runs, textRun{Text: text, Technique: technique})
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, technique)
+		}
+	}
+	walk(doc, "")
+	return runs
+}
+
+// attrValue returns the value of an attribute and whether it is set
+func attrValue(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,213 @@
+package detector
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"gopkg.in/yaml.v3"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+//go:embed data/fraud_rules.yaml
+var defaultFraudRulePack []byte
+
+// FraudRule is one rule of the lending and investment scam rule pack
+type FraudRule struct {
+	ID           string   `yaml:"id"`
+	Threat       string   `yaml:"threat"`
+	Category     string   `yaml:"category"`
+	Description  string   `yaml:"description"`
+	Confidence   float64  `yaml:"confidence"`
+	MinMatches   int      `yaml:"min_matches"`
+	Phrases      []string `yaml:"phrases"`
+	Patterns     []string `yaml:"patterns"`
+	UploadFields []string `yaml:"upload_fields"`
+	APKLinks     bool     `yaml:"apk_links"`
+
+	patterns []*regexp.Regexp
+}
+
+// FraudRulePack holds the rules of the fraud module
+type FraudRulePack struct {
+	Rules []FraudRule `yaml:"rules"`
+}
+
+// FraudDetector detects illegal online lending (pinjol) and investment scam
+// sites from a rule pack of phrases, upload forms and APK links
+type FraudDetector struct {
+	RulePack *FraudRulePack
+}
+
+// NewFraudDetector creates a new fraud detector. If rulePackPath is empty the
+// built-in rule pack is used.
+func NewFraudDetector(rulePackPath string) (*FraudDetector, error) {
+	data := defaultFraudRulePack
+	if rulePackPath != "" {
+		fileData, err := os.ReadFile(rulePackPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fraud rule pack: %v", err)
+		}
+		data = fileData
+	}
+
+	rulePack, err := ParseFraudRulePack(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FraudDetector{RulePack: rulePack}, nil
+}
+
+// ParseFraudRulePack parses and validates a YAML fraud rule pack
+func ParseFraudRulePack(data []byte) (*FraudRulePack, error) {
+	rulePack := &FraudRulePack{}
+	if err := yaml.Unmarshal(data, rulePack); err != nil {
+		return nil, fmt.Errorf("failed to parse fraud rule pack: %v", err)
+	}
+
+	for i := range rulePack.Rules {
+		rule := &rulePack.Rules[i]
+		if rule.Threat != models.ThreatPinjol && rule.Threat != models.ThreatInvestment {
+			return nil, fmt.Errorf("fraud rule %s has unknown threat %q", rule.ID, rule.Threat)
+		}
+		if !models.SignalCategories[rule.Category] {
+			return nil, fmt.Errorf("fraud rule %s has unknown category %q", rule.ID, rule.Category)
+		}
+		if rule.MinMatches < 1 {
+			rule.MinMatches = 1
+		}
+		for _, pattern := range rule.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("fraud rule %s has invalid pattern: %v", rule.ID, err)
+			}
+			rule.patterns = append(rule.patterns, re)
+		}
+	}
+
+	return rulePack, nil
+}
+
+// DetectFraud produces a signal for each rule that matches the page content
+func (fd *FraudDetector) DetectFraud(content string) []models.Signal {
+	var signals []models.Signal
+
+	doc := parseHTML(content)
+	text := strings.ToLower(visibleText(doc))
+	uploads := uploadFieldTexts(doc)
+	apkLinks := apkLinks(doc)
+
+	for _, rule := range fd.RulePack.Rules {
+		var matches []string
+		for _, phrase := range rule.Phrases {
+			if containsWord(text, strings.ToLower(phrase)) {
+				matches = append(matches, phrase)
+			}
+		}
+		for _, re := range rule.patterns {
+			if match := re.FindString(text); match != "" {
+				matches = append(matches, match)
+			}
+		}
+
+		var references []string
+		switch {
+		case len(rule.UploadFields) > 0:
+			fields := matchingUploadFields(uploads, rule.UploadFields)
+			if len(fields) == 0 {
+				continue
+			}
+			references = append(references, "Found file upload for "+strings.Join(fields, ", "))
+		case rule.APKLinks:
+			if len(apkLinks) == 0 {
+				continue
+			}
+			references = append(references, "Found APK download links: "+strings.Join(apkLinks, ", "))
+		default:
+			if len(matches) < rule.MinMatches {
+				continue
+			}
+		}
+		if len(matches) > 0 {
+			references = append(references, "Found phrases: "+strings.Join(matches, ", "))
+		}
+
+		var evidence []models.Evidence
+		for _, reference := range references {
+			evidence = append(evidence, models.Evidence{
+				Type:      "html",
+				Reference: reference,
+				Timestamp: time.Now(),
+			})
+		}
+
+		signals = append(signals, models.Signal{
+			SignalID:    rule.ID,
+			Category:    rule.Category,
+			Description: rule.Description,
+			Confidence:  rule.Confidence,
+			Evidence:    evidence,
+			Threat:      rule.Threat,
+		})
+	}
+
+	return signals
+}
+
+// uploadFieldTexts returns the lowercased name, id, accept and label text of
+// each file upload input, along with the text of its enclosing element
+func uploadFieldTexts(doc *html.Node) []string {
+	var texts []string
+	walkElements(doc, func(n *html.Node) bool {
+		if n.Data != "input" || !strings.EqualFold(nodeAttr(n, "type"), "file") {
+			return true
+		}
+		parts := []string{nodeAttr(n, "name"), nodeAttr(n, "id"), nodeAttr(n, "aria-label"), nodeAttr(n, "placeholder")}
+		if n.Parent != nil {
+			parts = append(parts, nodeText(n.Parent))
+		}
+		texts = append(texts, strings.ToLower(strings.Join(parts, " ")))
+		return false
+	})
+	return texts
+}
+
+// matchingUploadFields returns the terms mentioned by any upload field
+func matchingUploadFields(uploads, terms []string) []string {
+	var fields []string
+	for _, term := range terms {
+		for _, upload := range uploads {
+			if containsWord(upload, strings.ToLower(term)) {
+				fields = append(fields, term)
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// apkLinks returns the links on the page pointing at .apk files
+func apkLinks(doc *html.Node) []string {
+	var links []string
+	walkElements(doc, func(n *html.Node) bool {
+		if n.Data != "a" {
+			return true
+		}
+		href := nodeAttr(n, "href")
+		path := strings.ToLower(href)
+		if i := strings.IndexAny(path, "?#"); i >= 0 {
+			path = path[:i]
+		}
+		if strings.HasSuffix(path, ".apk") {
+			links = appendUniqueString(links, href)
+		}
+		return true
+	})
+	return links
+}
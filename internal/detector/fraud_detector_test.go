@@ -0,0 +1,92 @@
+package detector
+
+import (
+	"testing"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// TestDetectFraudPinjol tests loan offers, KYC upload forms, upfront fees and APK links
+func TestDetectFraudPinjol(t *testing.T) {
+	fd, err := NewFraudDetector("")
+	if err != nil {
+		t.Fatalf("Unexpected error loading built-in rule pack: %v", err)
+	}
+
+	testContent := `
+	<html>
+	<body>
+		<h1>Pinjaman Online Tanpa Jaminan, Langsung Cair 5 Menit!</h1>
+		<p>Tanpa BI checking, bunga rendah, tenor sampai 12 bulan.</p>
+		<form>
+			<label>Foto KTP <input type="file" name="foto_ktp"></label>
+			<div>Upload swafoto dengan KTP <input type="file" name="doc2"></div>
+		</form>
+		<p>Transfer biaya admin di muka sebelum pencairan.</p>
+		<a href="https://cdn.danakilat.xyz/app/danakilat-v2.apk?ref=web">Download APK</a>
+	</body>
+	</html>
+	`
+
+	found := make(map[string]models.Signal)
+	for _, signal := range fd.DetectFraud(testContent) {
+		found[signal.SignalID] = signal
+	}
+
+	for _, id := range []string{"PINJOL_LOAN_OFFER", "PINJOL_KYC_UPLOAD", "PINJOL_UPFRONT_FEE", "FRAUD_APK_DOWNLOAD"} {
+		signal, ok := found[id]
+		if !ok {
+			t.Errorf("Expected %s signal, got %v", id, found)
+			continue
+		}
+		if signal.Threat != models.ThreatPinjol || len(signal.Evidence) == 0 {
+			t.Errorf("Expected %s to be a pinjol signal with evidence, got %+v", id, signal)
+		}
+	}
+	if _, ok := found["INVEST_GUARANTEED_RETURN"]; ok {
+		t.Error("Did not expect investment signals on a lending page")
+	}
+}
+
+// TestDetectFraudInvestment tests guaranteed returns and referral schemes
+func TestDetectFraudInvestment(t *testing.T) {
+	fd, _ := NewFraudDetector("")
+
+	testContent := `
+	<html>
+	<body>
+		<h1>Profit Pasti 3% per hari dengan Robot Trading</h1>
+		<p>Dijamin untung, modal kembali dalam 30 hari. Passive income tanpa risiko.</p>
+		<p>Dapatkan bonus referral dan komisi level dari downline Anda.</p>
+	</body>
+	</html>
+	`
+
+	found := make(map[string]bool)
+	for _, signal := range fd.DetectFraud(testContent) {
+		found[signal.SignalID] = true
+		if signal.Threat != models.ThreatInvestment {
+			t.Errorf("Expected %s to be an investment scam signal, got %q", signal.SignalID, signal.Threat)
+		}
+	}
+
+	for _, id := range []string{"INVEST_GUARANTEED_RETURN", "INVEST_REFERRAL_SCHEME", "INVEST_TRADING_ROBOT"} {
+		if !found[id] {
+			t.Errorf("Expected %s signal, got %v", id, found)
+		}
+	}
+}
+
+// TestParseFraudRulePackInvalid tests that rules with unknown threats,
+// unknown categories or bad patterns are rejected
+func TestParseFraudRulePackInvalid(t *testing.T) {
+	if _, err := ParseFraudRulePack([]byte("rules:\n  - id: X\n    threat: phishing\n")); err == nil {
+		t.Error("Expected an error for an unknown threat")
+	}
+	if _, err := ParseFraudRulePack([]byte("rules:\n  - id: X\n    threat: pinjol\n    category: FRAUD\n")); err == nil {
+		t.Error("Expected an error for an unknown category")
+	}
+	if _, err := ParseFraudRulePack([]byte("rules:\n  - id: X\n    threat: pinjol\n    category: UX\n    patterns: ['(']\n")); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
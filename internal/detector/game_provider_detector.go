@@ -0,0 +1,277 @@
+package detector
+
+import (
+	_ "embed"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+//go:embed data/game_providers.yaml
+var defaultGameProviderCatalog []byte
+
+// GameProvider describes how a slot game provider shows up in a page
+type GameProvider struct {
+	Name   string            `yaml:"name"`
+	Hosts  []string          `yaml:"hosts"`
+	Paths  []string          `yaml:"paths"`
+	Games  map[string]string `yaml:"games"`
+	Titles []string          `yaml:"titles"`
+}
+
+// GameProviderCatalog holds the known game providers
+type GameProviderCatalog struct {
+	Providers []GameProvider `yaml:"providers"`
+}
+
+// GameProviderMatch holds the games and evidence found for one provider
+type GameProviderMatch struct {
+	Provider string
+	Games    []string
+	Evidence []string
+	// Confirmed is set when a provider host, path or game ID was found.
+	// Titles alone appear in reviews and news about the games.
+	Confirmed bool
+}
+
+// GameProviderDetector fingerprints embedded slot game thumbnails and launch URLs
+type GameProviderDetector struct {
+	Catalog  *GameProviderCatalog
+	URLRegex *regexp.Regexp
+}
+
+// NewGameProviderDetector creates a new game provider detector. If catalogPath
+// is empty the built-in catalog is used.
+func NewGameProviderDetector(catalogPath string) (*GameProviderDetector, error) {
+	data := defaultGameProviderCatalog
+	if catalogPath != "" {
+		fileData, err := os.ReadFile(catalogPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read game provider catalog: %v", err)
+		}
+		data = fileData
+	}
+
+	catalog, err := ParseGameProviderCatalog(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GameProviderDetector{
+		Catalog:  catalog,
+		URLRegex: regexp.MustCompile(`(?i)(?:src|href|data-src|data-original|data-lazy-src|data-url)\s*=\s*["']([^"']+)["']`),
+	}, nil
+}
+
+// ParseGameProviderCatalog parses a YAML game provider catalog
+func ParseGameProviderCatalog(data []byte) (*GameProviderCatalog, error) {
+	catalog := &GameProviderCatalog{}
+	if err := yaml.Unmarshal(data, catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse game provider catalog: %v", err)
+	}
+	return catalog, nil
+}
+
+// MatchProviders returns the providers whose assets, game IDs or titles appear in content
+func (gd *GameProviderDetector) MatchProviders(content string) []GameProviderMatch {
+	var matches []GameProviderMatch
+
+	var urls []string
+	for _, m := range gd.URLRegex.FindAllStringSubmatch(content, -1) {
+		urls = append(urls, strings.ToLower(m[1]))
+	}
+	lowerContent := strings.ToLower(content)
+
+	for _, provider := range gd.Catalog.Providers {
+		match := GameProviderMatch{Provider: provider.Name}
+		games := make(map[string]bool)
+
+		for _, rawURL := range urls {
+			if host := urlHost(rawURL); host != "" {
+				for _, providerHost := range provider.Hosts {
+					if host == providerHost || strings.HasSuffix(host, "."+providerHost) {
+						match.Evidence = appendUniqueString(match.Evidence, "asset host "+host)
+						match.Confirmed = true
+					}
+				}
+			}
+
+			for _, path := range provider.Paths {
+				if strings.Contains(rawURL, strings.ToLower(path)) {
+					match.Evidence = appendUniqueString(match.Evidence, "asset path "+path)
+					match.Confirmed = true
+				}
+			}
+
+			for gameID, title := range provider.Games {
+				if containsToken(rawURL, strings.ToLower(gameID)) {
+					games[title] = true
+					match.Evidence = appendUniqueString(match.Evidence, "game ID "+gameID)
+					match.Confirmed = true
+				}
+			}
+		}
+
+		// Game titles in the page text
+		titles := append([]string{}, provider.Titles...)
+		for _, title := range provider.Games {
+			titles = append(titles, title)
+		}
+		for _, title := range titles {
+			if containsToken(lowerContent, strings.ToLower(title)) {
+				games[title] = true
+				match.Evidence = appendUniqueString(match.Evidence, "game title "+title)
+			}
+		}
+
+		if len(match.Evidence) == 0 {
+			continue
+		}
+
+		for title := range games {
+			match.Games = append(match.Games, title)
+		}
+		sort.Strings(match.Games)
+		sort.Strings(match.Evidence)
+		matches = append(matches, match)
+	}
+
+	return matches
+}
+
+// containsToken reports whether token occurs in s without a letter, digit,
+// '-' or '_' directly before or after it, so that mahjong-ways does not
+// match mahjong-ways2
+func containsToken(s, token string) bool {
+	if token == "" {
+		return false
+	}
+	for offset := 0; ; {
+		i := strings.Index(s[offset:], token)
+		if i < 0 {
+			return false
+		}
+		start := offset + i
+		end := start + len(token)
+		if (start == 0 || !isTokenByte(s[start-1])) && (end == len(s) || !isTokenByte(s[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func isTokenByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b == '-' || b == '_'
+}
+
+// DetectGameProviders produces a GAME_PROVIDER signal listing the providers
+// and games embedded in content. Providers named only by game titles are
+// also written about on review and news pages: they are kept as evidence
+// of a signal but never produce one.
+func (gd *GameProviderDetector) DetectGameProviders(content string) []models.Signal {
+	var signals []models.Signal
+
+	var descriptions []string
+	var evidence []models.Evidence
+	confirmed := 0
+	for _, match := range gd.MatchProviders(content) {
+		if match.Confirmed {
+			confirmed++
+			description := match.Provider
+			if len(match.Games) > 0 {
+				description += " (" + strings.Join(match.Games, ", ") + ")"
+			}
+			descriptions = append(descriptions, description)
+		}
+
+		evidence = append(evidence, models.Evidence{
+			Type:      "html",
+			Reference: "Found " + match.Provider + ": " + strings.Join(match.Evidence, "; "),
+			Timestamp: time.Now(),
+		})
+	}
+	if confirmed == 0 {
+		return signals
+	}
+
+	// Embedding games from several providers is typical of aggregator judol sites
+	confidence := 0.9
+	if confirmed > 1 {
+		confidence = 0.95
+	}
+
+	signal := models.Signal{
+		SignalID:    "GAME_PROVIDER",
+		Category:    "UX",
+		Description: "Embedded slot games from providers: " + strings.Join(descriptions, ", "),
+		Confidence:  confidence,
+		Evidence:    evidence,
+		Vertical:    models.VerticalSlot,
+	}
+	signals = append(signals, signal)
+
+	return signals
+}
+
+// IsProviderAsset reports whether a URL is served from a game provider's
+// asset host or path. Such assets appear on unrelated sites that embed the
+// same games.
+func (gd *GameProviderDetector) IsProviderAsset(rawURL string) bool {
+	lowerURL := strings.ToLower(rawURL)
+	host := urlHost(lowerURL)
+	for _, provider := range gd.Catalog.Providers {
+		for _, providerHost := range provider.Hosts {
+			if host == providerHost || strings.HasSuffix(host, "."+providerHost) {
+				return true
+			}
+		}
+		for _, path := range provider.Paths {
+			if strings.Contains(lowerURL, strings.ToLower(path)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GameProviderNames returns the names of the providers whose assets or
+// game IDs are embedded in content
+func (gd *GameProviderDetector) GameProviderNames(content string) []string {
+	var names []string
+	for _, match := range gd.MatchProviders(content) {
+		if match.Confirmed {
+			names = append(names, match.Provider)
+		}
+	}
+	return names
+}
+
+// urlHost returns the lowercase host of a possibly scheme-relative URL
+func urlHost(rawURL string) string {
+	if strings.HasPrefix(rawURL, "//") {
+		rawURL = "https:" + rawURL
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
+// appendUniqueString appends value to values unless it is already present
+func appendUniqueString(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
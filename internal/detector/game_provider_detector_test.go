@@ -0,0 +1,73 @@
+package detector
+
+import (
+	"testing"
+)
+
+// TestGameProviderDetection tests provider fingerprinting from assets, game IDs and titles
+func TestGameProviderDetection(t *testing.T) {
+	gd, err := NewGameProviderDetector("")
+	if err != nil {
+		t.Fatalf("Expected built-in catalog to load, got error: %v", err)
+	}
+
+	testContent := `
+	<html>
+	<body>
+		<div class="game-list">
+			<img data-src="https://common-static.ppgames.net/game_pic/square/200/vs20olympgate.png" alt="">
+			<a href="/play?provider=pgsoft&game=mahjong-ways2">Main</a>
+			<span>Starlight Princess</span>
+		</div>
+	</body>
+	</html>
+	`
+
+	matches := gd.MatchProviders(testContent)
+
+	games := make(map[string][]string)
+	for _, match := range matches {
+		games[match.Provider] = match.Games
+	}
+
+	pragmatic, ok := games["Pragmatic Play"]
+	if !ok {
+		t.Fatalf("Expected to find Pragmatic Play, got %v", matches)
+	}
+	if len(pragmatic) != 2 {
+		t.Errorf("Expected Gates of Olympus and Starlight Princess, got %v", pragmatic)
+	}
+
+	if _, ok := games["PG Soft"]; !ok {
+		t.Errorf("Expected to find PG Soft from game ID, got %v", matches)
+	}
+
+	signals := gd.DetectGameProviders(testContent)
+	if len(signals) != 1 || signals[0].SignalID != "GAME_PROVIDER" || signals[0].Category != "UX" {
+		t.Errorf("Expected a single UX GAME_PROVIDER signal, got %v", signals)
+	}
+}
+
+// TestGameProviderMatchBoundaries tests that game IDs match whole tokens and
+// that titles alone give no signal
+func TestGameProviderMatchBoundaries(t *testing.T) {
+	gd, err := NewGameProviderDetector("")
+	if err != nil {
+		t.Fatalf("Expected built-in catalog to load, got error: %v", err)
+	}
+
+	matches := gd.MatchProviders(`<a href="/play?provider=pgsoft&game=mahjong-ways2">Main</a>
+		<img src="https://cdn.tokoku.id/game_pic/sepatu.png">`)
+	if len(matches) != 1 || matches[0].Provider != "PG Soft" || len(matches[0].Games) != 1 || matches[0].Games[0] != "Mahjong Ways 2" {
+		t.Errorf("Expected only Mahjong Ways 2 of PG Soft, got %+v", matches)
+	}
+
+	review := `<article><h1>Review Gates of Olympus dan Sweet Bonanza</h1></article>`
+	signals := gd.DetectGameProviders(review)
+	if len(signals) != 0 {
+		t.Errorf("Expected no signal for titles only, got %+v", signals)
+	}
+	if names := gd.GameProviderNames(review); len(names) != 0 {
+		t.Errorf("Expected no confirmed providers from titles only, got %v", names)
+	}
+}
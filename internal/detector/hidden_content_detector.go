@@ -0,0 +1,198 @@
+package detector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// HiddenContentDetector detects gambling keywords hidden from visitors but
+// left for search engines (display:none, off-screen text, zero font size,
+// noscript and marquee blocks) and keyword stuffing
+type HiddenContentDetector struct {
+	// Keywords are gambling words counted in page text; trailing digits of a
+	// word are ignored (gacor88 counts as gacor)
+	Keywords []string
+	// MinHiddenKeywords is how many hidden keyword occurrences produce a signal
+	MinHiddenKeywords int
+	// MinHiddenShare is the share of all keyword occurrences that must be hidden
+	MinHiddenShare float64
+	// StuffingRepeats is how often one keyword must repeat to count as stuffing
+	StuffingRepeats int
+	// StuffingDensity is the share of words that must be keywords to count as stuffing
+	StuffingDensity float64
+}
+
+// keywordStats counts the gambling keywords in a body of text
+type keywordStats struct {
+	words    int
+	hits     int
+	keywords map[string]int
+}
+
+// NewHiddenContentDetector creates a new hidden content detector
+func NewHiddenContentDetector() *HiddenContentDetector {
+	return &HiddenContentDetector{
+		Keywords: []string{
+			"slot", "slots", "gacor", "maxwin", "togel", "judi", "casino", "sbobet",
+			"scatter", "rtp", "jackpot", "bandar", "poker", "toto", "zeus", "olympus",
+			"mahjong", "pragmatic", "depo", "wd", "bonus", "jp",
+		},
+		MinHiddenKeywords: 3,
+		MinHiddenShare:    0.6,
+		StuffingRepeats:   10,
+		StuffingDensity:   0.2,
+	}
+}
+
+// DetectHiddenContent produces signals when gambling keywords appear mainly in
+// hidden text, and when text is stuffed with them
+func (hd *HiddenContentDetector) DetectHiddenContent(content string) []models.Signal {
+	var signals []models.Signal
+
+	visible := hd.newStats()
+	hidden := hd.newStats()
+	byTechnique := make(map[string]*keywordStats)
+	for _, run := range textRuns(parseHTML(content)) {
+		if run.Technique == "" {
+			hd.count(visible, run.Text)
+			continue
+		}
+		hd.count(hidden, run.Text)
+		if byTechnique[run.Technique] == nil {
+			byTechnique[run.Technique] = hd.newStats()
+		}
+		hd.count(byTechnique[run.Technique], run.Text)
+	}
+
+	total := visible.hits + hidden.hits
+	if hidden.hits >= hd.MinHiddenKeywords && float64(hidden.hits) >= hd.MinHiddenShare*float64(total) {
+		techniques := make([]string, 0, len(byTechnique))
+		for technique, stats := range byTechnique {
+			if stats.hits > 0 {
+				techniques = append(techniques, technique)
+			}
+		}
+		sort.Slice(techniques, func(i, j int) bool {
+			if byTechnique[techniques[i]].hits != byTechnique[techniques[j]].hits {
+				return byTechnique[techniques[i]].hits > byTechnique[techniques[j]].hits
+			}
+			return techniques[i] < techniques[j]
+		})
+
+		var evidence []models.Evidence
+		for _, technique := range techniques {
+			stats := byTechnique[technique]
+			evidence = append(evidence, models.Evidence{
+				Type:      "html",
+				Reference: fmt.Sprintf("Hidden with %s: %d gambling keywords (%s)", technique, stats.hits, topKeywords(stats.keywords, 5)),
+				Timestamp: time.Now(),
+			})
+		}
+
+		signals = append(signals, models.Signal{
+			SignalID: "HIDDEN_GAMBLING_CONTENT",
+			Category: "UX",
+			Description: fmt.Sprintf("Found %d of %d gambling keywords in hidden text (%s)",
+				hidden.hits, total, strings.Join(techniques, ", ")),
+			Confidence: 0.85,
+			Evidence:   evidence,
+		})
+	}
+
+	// Keyword stuffing, checked separately for hidden and visible text since
+	// the hidden block is usually the stuffed one
+	var evidence []models.Evidence
+	confidence := 0.0
+	for _, part := range []struct {
+		name       string
+		stats      *keywordStats
+		confidence float64
+	}{
+		{"hidden", hidden, 0.8},
+		{"visible", visible, 0.6},
+	} {
+		keyword, repeats := mostRepeated(part.stats.keywords)
+		if repeats < hd.StuffingRepeats || part.stats.words == 0 {
+			continue
+		}
+		density := float64(part.stats.hits) / float64(part.stats.words)
+		if density < hd.StuffingDensity {
+			continue
+		}
+		evidence = append(evidence, models.Evidence{
+			Type: "html",
+			Reference: fmt.Sprintf("Keyword '%s' repeated %d times in %s text; gambling keywords are %.0f%% of %d words",
+				keyword, repeats, part.name, density*100, part.stats.words),
+			Timestamp: time.Now(),
+		})
+		if part.confidence > confidence {
+			confidence = part.confidence
+		}
+	}
+	if len(evidence) > 0 {
+		signals = append(signals, models.Signal{
+			SignalID:    "KEYWORD_STUFFING",
+			Category:    "UX",
+			Description: "Found gambling keyword stuffing",
+			Confidence:  confidence,
+			Evidence:    evidence,
+		})
+	}
+
+	return signals
+}
+
+// newStats creates empty keyword counts
+func (hd *HiddenContentDetector) newStats() *keywordStats {
+	return &keywordStats{keywords: make(map[string]int)}
+}
+
+// count adds the words and keywords of text to stats
+func (hd *HiddenContentDetector) count(stats *keywordStats, text string) {
+	for _, word := range wordRegex.FindAllString(strings.ToLower(text), -1) {
+		stats.words++
+		word = strings.TrimRight(word, "0123456789")
+		for _, keyword := range hd.Keywords {
+			if word == keyword {
+				stats.hits++
+				stats.keywords[keyword]++
+				break
+			}
+		}
+	}
+}
+
+// mostRepeated returns the keyword counted most often
+func mostRepeated(keywords map[string]int) (string, int) {
+	best, repeats := "", 0
+	for _, keyword := range sortedCountKeys(keywords) {
+		if keywords[keyword] > repeats {
+			best, repeats = keyword, keywords[keyword]
+		}
+	}
+	return best, repeats
+}
+
+// topKeywords lists up to n keywords, most frequent first
+func topKeywords(keywords map[string]int, n int) string {
+	names := sortedCountKeys(keywords)
+	sort.SliceStable(names, func(i, j int) bool { return keywords[names[i]] > keywords[names[j]] })
+	if len(names) > n {
+		names = names[:n]
+	}
+	return strings.Join(names, ", ")
+}
+
+// sortedCountKeys returns the keys of a count map in sorted order
+func sortedCountKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,79 @@
+package detector
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDetectHiddenContent tests gambling keywords hidden with inline styles, classes, noscript and marquee
+func TestDetectHiddenContent(t *testing.T) {
+	hd := NewHiddenContentDetector()
+
+	testContent := `
+	<html>
+	<head><style>.seo-box { position: absolute; display : none; }</style></head>
+	<body>
+		<h1>Sekolah Menengah Atas Negeri 1</h1>
+		<p>Jadwal ujian semester genap telah diumumkan.</p>
+		<div style="position:absolute;left:-9999px">slot gacor maxwin hari ini</div>
+		<span class="seo-box">situs judi slot88 terpercaya</span>
+		<p style="font-size: 0px">rtp slot</p>
+		<noscript><a href="https://gacor88.xyz">togel sbobet casino</a></noscript>
+		<marquee>bonus jackpot</marquee>
+	</body>
+	</html>
+	`
+
+	signals := hd.DetectHiddenContent(testContent)
+	if len(signals) != 1 || signals[0].SignalID != "HIDDEN_GAMBLING_CONTENT" {
+		t.Fatalf("Expected a HIDDEN_GAMBLING_CONTENT signal, got %v", signals)
+	}
+
+	references := make([]string, 0, len(signals[0].Evidence))
+	for _, evidence := range signals[0].Evidence {
+		references = append(references, evidence.Reference)
+	}
+	joined := strings.Join(references, "\n")
+	for _, technique := range []string{"off-screen position", "display:none (class .seo-box)", "font-size:0", "noscript", "marquee"} {
+		if !strings.Contains(joined, "Hidden with "+technique+":") {
+			t.Errorf("Expected evidence for %s, got:\n%s", technique, joined)
+		}
+	}
+}
+
+// TestDetectHiddenContentVisible tests that visible gambling keywords are not reported as hidden
+func TestDetectHiddenContentVisible(t *testing.T) {
+	hd := NewHiddenContentDetector()
+
+	testContent := `
+	<html><body>
+		<h1>Slot Gacor Maxwin</h1>
+		<p>Daftar slot online, RTP live, bonus new member dan jackpot setiap hari.</p>
+		<div style="display:none">slot</div>
+	</body></html>
+	`
+
+	for _, signal := range hd.DetectHiddenContent(testContent) {
+		t.Errorf("Did not expect %s on a page with visible gambling content", signal.SignalID)
+	}
+}
+
+// TestDetectKeywordStuffing tests repeated gambling keywords in a hidden block
+func TestDetectKeywordStuffing(t *testing.T) {
+	hd := NewHiddenContentDetector()
+
+	testContent := `<html><body><p>Portal berita desa.</p><div hidden>` +
+		strings.Repeat("slot gacor slot88 maxwin deposit ", 6) +
+		`</div></body></html>`
+
+	found := make(map[string]float64)
+	for _, signal := range hd.DetectHiddenContent(testContent) {
+		found[signal.SignalID] = signal.Confidence
+	}
+	if _, ok := found["HIDDEN_GAMBLING_CONTENT"]; !ok {
+		t.Errorf("Expected HIDDEN_GAMBLING_CONTENT, got %v", found)
+	}
+	if confidence, ok := found["KEYWORD_STUFFING"]; !ok || confidence != 0.8 {
+		t.Errorf("Expected KEYWORD_STUFFING in hidden text with confidence 0.8, got %v", found)
+	}
+}
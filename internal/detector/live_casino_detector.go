@@ -0,0 +1,75 @@
+package detector
+
+import (
+	"strings"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// LiveCasinoDetector detects live-casino pages: table games streamed by a
+// live dealer and the studios that provide them
+type LiveCasinoDetector struct {
+	Games        []string
+	Providers    []string
+	LobbyPhrases []string
+}
+
+// NewLiveCasinoDetector creates a new live casino detector
+func NewLiveCasinoDetector() *LiveCasinoDetector {
+	return &LiveCasinoDetector{
+		Games: []string{
+			"baccarat", "bakarat", "sicbo", "sic bo", "dragon tiger", "roulette", "rolet",
+			"blackjack", "fan tan", "teen patti", "andar bahar", "niu niu", "poker casino",
+		},
+		Providers: []string{
+			"evolution gaming", "evo-games", "ezugi", "sa gaming",
+			"sexy baccarat", "ae sexy", "wm casino", "dream gaming", "allbet", "asia gaming",
+			"pragmatic play live", "playtech live", "big gaming", "green dragon", "opus gaming",
+		},
+		LobbyPhrases: []string{"live casino", "casino live", "live dealer", "dealer live", "meja live"},
+	}
+}
+
+// DetectLiveCasino produces live casino signals from the page content
+func (ld *LiveCasinoDetector) DetectLiveCasino(content string) []models.Signal {
+	var signals []models.Signal
+
+	doc := parseHTML(content)
+	text := strings.ToLower(visibleText(doc))
+
+	var games []string
+	for _, game := range ld.Games {
+		if containsWord(text, game) {
+			games = append(games, game)
+		}
+	}
+	if len(games) >= 2 {
+		signals = append(signals, verticalSignal(models.VerticalCasino, "LIVE_CASINO_GAMES",
+			"Found live casino games: "+strings.Join(games, ", "), 0.75,
+			"Found live casino table game names in page text"))
+	}
+
+	// Studios are matched in lobby thumbnails, launch frames and link hosts;
+	// names like "green dragon" or "big gaming" are ordinary words in text
+	urls := embeddedURLs(doc)
+	var providers []string
+	for _, provider := range ld.Providers {
+		if containsNameInURLs(urls, provider) {
+			providers = append(providers, provider)
+		}
+	}
+	if len(providers) > 0 {
+		signals = append(signals, verticalSignal(models.VerticalCasino, "LIVE_CASINO_PROVIDERS",
+			"Found live casino providers: "+strings.Join(providers, ", "), 0.85,
+			"Found live casino studio names in embedded sources and link hosts"))
+	}
+
+	// A lobby heading alone is common in menus; only count it next to games
+	if phrase := firstPhrase(text, ld.LobbyPhrases); phrase != "" && len(games) > 0 {
+		signals = append(signals, verticalSignal(models.VerticalCasino, "LIVE_CASINO_LOBBY",
+			"Found live casino lobby", 0.6,
+			"Found phrase '"+phrase+"' next to table game names"))
+	}
+
+	return signals
+}
@@ -0,0 +1,67 @@
+package detector
+
+import (
+	"testing"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// TestDetectLiveCasino tests live casino games, studios and lobby phrases
+func TestDetectLiveCasino(t *testing.T) {
+	ld := NewLiveCasinoDetector()
+
+	testContent := `
+	<html>
+	<body>
+		<h2>Live Casino</h2>
+		<div class="game"><img src="/img/evo-games/lightning.jpg" alt="Lightning Roulette"></div>
+		<div class="game">Baccarat</div>
+		<div class="game">Sicbo</div>
+		<div class="game">Dragon Tiger</div>
+	</body>
+	</html>
+	`
+
+	signals := ld.DetectLiveCasino(testContent)
+
+	found := make(map[string]bool)
+	for _, signal := range signals {
+		found[signal.SignalID] = true
+		if signal.Vertical != models.VerticalCasino {
+			t.Errorf("Expected %s to be tagged with the casino vertical, got %q", signal.SignalID, signal.Vertical)
+		}
+	}
+
+	for _, id := range []string{"LIVE_CASINO_GAMES", "LIVE_CASINO_PROVIDERS", "LIVE_CASINO_LOBBY"} {
+		if !found[id] {
+			t.Errorf("Expected %s signal, got %v", id, signals)
+		}
+	}
+}
+
+// TestDetectLiveCasinoProviderNamesInText tests that studio names in page
+// text are not taken for embedded studios
+func TestDetectLiveCasinoProviderNamesInText(t *testing.T) {
+	ld := NewLiveCasinoDetector()
+
+	testContent := `
+	<html>
+	<body>
+		<h1>Green Dragon Restaurant</h1>
+		<p>Big gaming setups and Asia gaming news. Allbet is our fantasy league.</p>
+		<img src="/img/menu/dimsum.jpg">
+		<a href="https://gofood.co.id/jakarta/restaurant/green-dragon">Pesan</a>
+	</body>
+	</html>
+	`
+
+	if signals := ld.DetectLiveCasino(testContent); len(signals) != 0 {
+		t.Errorf("Expected no live casino signals, got %v", signals)
+	}
+
+	embedded := ld.DetectLiveCasino(`<html><body><iframe src="https://lobby.allbet.gaming/launch"></iframe>
+		<img src="/img/live/big-gaming.png"></body></html>`)
+	if len(embedded) != 1 || embedded[0].SignalID != "LIVE_CASINO_PROVIDERS" {
+		t.Errorf("Expected a providers signal from embedded sources, got %v", embedded)
+	}
+}
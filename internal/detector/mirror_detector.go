@@ -0,0 +1,419 @@
+package detector
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// Mirror discovery sources
+const (
+	MirrorSourceSection   = "link_alternatif"
+	MirrorSourceOutbound  = "outbound"
+	MirrorSourceShortlink = "shortlink"
+)
+
+// ResourceShortlink is the resource type for shortener URLs found on a page
+const ResourceShortlink = "shortlink"
+
+// How a shortener leads to its destination
+const (
+	// ShortenerRedirect answers with a plain HTTP redirect
+	ShortenerRedirect = "redirect"
+	// ShortenerInterstitial usually serves a "continue to site" page
+	ShortenerInterstitial = "interstitial"
+	// ShortenerLinkPage serves a link-in-bio page listing several links
+	ShortenerLinkPage = "link_page"
+)
+
+// Shortener is a URL shortener or link-in-bio host
+type Shortener struct {
+	Host string
+	Kind string
+}
+
+// Shorteners are the shorteners and link-in-bio hosts commonly used to
+// promote judol sites. The mirrors module collects their links as shortlinks
+// and the shortlinks module expands them, both from this catalog.
+var Shorteners = []Shortener{
+	{Host: "s.id", Kind: ShortenerInterstitial},
+	{Host: "bit.ly", Kind: ShortenerRedirect},
+	{Host: "cutt.ly", Kind: ShortenerInterstitial},
+	{Host: "tinyurl.com", Kind: ShortenerRedirect},
+	{Host: "shorturl.at", Kind: ShortenerInterstitial},
+	{Host: "rebrand.ly", Kind: ShortenerRedirect},
+	{Host: "is.gd", Kind: ShortenerRedirect},
+	{Host: "t.ly", Kind: ShortenerRedirect},
+	{Host: "rb.gy", Kind: ShortenerInterstitial},
+	{Host: "ow.ly", Kind: ShortenerRedirect},
+	{Host: "linktr.ee", Kind: ShortenerLinkPage},
+	{Host: "heylink.me", Kind: ShortenerLinkPage},
+	{Host: "lynk.id", Kind: ShortenerLinkPage},
+	{Host: "linkr.bio", Kind: ShortenerLinkPage},
+}
+
+// MirrorLink is a mirror domain (or shortlink pointing to one) advertised by a page
+type MirrorLink struct {
+	Domain  string
+	URL     string
+	Source  string
+	Context string
+}
+
+// MirrorDetector finds the mirror domains a judol site advertises. Operators
+// rotate domains as they get blocked and list the current ones openly in
+// "link alternatif" sections, so one page usually reveals the rotation set.
+type MirrorDetector struct {
+	SectionKeywords []string
+	ShortlinkHosts  []string
+	IgnoredHosts    []string
+	DomainRegex     *regexp.Regexp
+	URLRegex        *regexp.Regexp
+}
+
+// NewMirrorDetector creates a new mirror domain detector
+func NewMirrorDetector() *MirrorDetector {
+	var shortlinkHosts []string
+	for _, shortener := range Shorteners {
+		shortlinkHosts = append(shortlinkHosts, shortener.Host)
+	}
+
+	return &MirrorDetector{
+		SectionKeywords: []string{
+			"link alternatif", "link alternative", "link daftar", "link login", "link resmi",
+			"situs alternatif", "domain alternatif", "alamat alternatif", "link terbaru", "link anti blokir",
+		},
+		ShortlinkHosts: shortlinkHosts,
+		IgnoredHosts: []string{
+			"wa.me", "whatsapp.com", "t.me", "telegram.me", "line.me", "facebook.com", "instagram.com",
+			"twitter.com", "x.com", "youtube.com", "tiktok.com", "google.com", "apple.com",
+			"threads.net", "pinterest.com", "linkedin.com", "reddit.com", "discord.gg", "discord.com",
+			"wikipedia.org", "wordpress.org", "gravatar.com",
+			// CDNs and public asset hosts
+			"googleapis.com", "gstatic.com", "googletagmanager.com", "cloudflare.com", "cloudfront.net",
+			"jsdelivr.net", "unpkg.com", "bootstrapcdn.com", "jquery.com", "fontawesome.com",
+		},
+		DomainRegex: regexp.MustCompile(`(?i)\b((?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,24})\b`),
+		URLRegex:    regexp.MustCompile(`(?i)(?:https?:)?//[a-z0-9.-]+\.[a-z]{2,24}[^\s"'<>)]*`),
+	}
+}
+
+// ExtractMirrors returns the mirror domains and shortlinks advertised by a page
+func (md *MirrorDetector) ExtractMirrors(content, pageDomain string) []MirrorLink {
+	var links []MirrorLink
+	seen := make(map[string]bool)
+	pageHost := stripWWW(strings.ToLower(pageDomain))
+
+	add := func(rawURL, source, context string) {
+		host := stripWWW(urlHost(normalizeLinkURL(rawURL)))
+		if host == "" || !strings.Contains(host, ".") || md.IsIgnoredHost(host) {
+			return
+		}
+		if host == pageHost || strings.HasSuffix(host, "."+pageHost) {
+			return
+		}
+
+		link := MirrorLink{Domain: host, URL: rawURL, Source: source, Context: context}
+		if md.IsShortlinkHost(host) {
+			link.Source = MirrorSourceShortlink
+			link.Domain = strings.TrimSuffix(host+urlPath(normalizeLinkURL(rawURL)), "/")
+		}
+		if seen[link.Domain] {
+			return
+		}
+		seen[link.Domain] = true
+		links = append(links, link)
+	}
+
+	doc := parseHTML(content)
+	walkElements(doc, func(n *html.Node) bool {
+		switch n.Data {
+		case "script", "style", "noscript":
+			return false
+		}
+
+		// Mirror buttons are labelled with the keyword themselves
+		if keyword := md.matchKeyword(nodeText(n)); keyword != "" {
+			for _, rawURL := range md.elementURLs(n) {
+				add(rawURL, MirrorSourceSection, keyword)
+			}
+		}
+
+		// Section headings: collect the list or small block following them
+		if keyword := md.matchKeyword(ownText(n)); keyword != "" {
+			for _, block := range sectionBlocks(n) {
+				for _, rawURL := range md.elementURLs(block) {
+					add(rawURL, MirrorSourceSection, keyword)
+				}
+				walkElements(block, func(c *html.Node) bool {
+					for _, rawURL := range md.elementURLs(c) {
+						add(rawURL, MirrorSourceSection, keyword)
+					}
+					return true
+				})
+				for _, domain := range md.textDomains(nodeText(block)) {
+					add("https://"+domain, MirrorSourceSection, keyword)
+				}
+			}
+		}
+
+		// Outbound links to domains carrying the same brand
+		if n.Data == "a" {
+			rawURL := nodeAttr(n, "href")
+			host := stripWWW(urlHost(normalizeLinkURL(rawURL)))
+			if host != "" && sameBrand(host, pageHost) {
+				add(rawURL, MirrorSourceOutbound, nodeText(n))
+			} else if md.IsShortlinkHost(host) {
+				add(rawURL, MirrorSourceShortlink, nodeText(n))
+			}
+		}
+
+		return true
+	})
+
+	sort.SliceStable(links, func(i, j int) bool {
+		return mirrorSourceRank(links[i].Source) < mirrorSourceRank(links[j].Source)
+	})
+
+	return links
+}
+
+// DetectMirrorSignals produces a signal when a page advertises mirror domains
+func (md *MirrorDetector) DetectMirrorSignals(links []MirrorLink) []models.Signal {
+	var signals []models.Signal
+
+	var domains []string
+	var evidence []models.Evidence
+	confidence := 0.0
+	for _, link := range links {
+		domains = append(domains, link.Domain)
+
+		reference := "Found " + link.Domain + " via " + link.Source
+		if link.Context != "" {
+			reference += " ('" + truncateContext(link.Context) + "')"
+		}
+		evidence = append(evidence, models.Evidence{
+			Type:      "html",
+			Reference: reference,
+			Timestamp: time.Now(),
+		})
+
+		// Listing alternatives is how operators survive blocking; outbound
+		// brand links and shortlinks are weaker on their own
+		switch link.Source {
+		case MirrorSourceSection:
+			confidence = 0.6
+		default:
+			if confidence < 0.4 {
+				confidence = 0.4
+			}
+		}
+	}
+
+	if len(domains) == 0 {
+		return signals
+	}
+
+	signal := models.Signal{
+		SignalID:    "MIRROR_DOMAINS",
+		Category:    "INFRA",
+		Description: "Advertises mirror domains: " + strings.Join(domains, ", "),
+		Confidence:  confidence,
+		Evidence:    evidence,
+	}
+	signals = append(signals, signal)
+
+	return signals
+}
+
+// IsShortlinkHost reports whether host is a known URL shortener
+func (md *MirrorDetector) IsShortlinkHost(host string) bool {
+	for _, shortener := range md.ShortlinkHosts {
+		if host == shortener {
+			return true
+		}
+	}
+	return false
+}
+
+// IsIgnoredHost reports whether host is a social or contact platform or a
+// public CDN
+func (md *MirrorDetector) IsIgnoredHost(host string) bool {
+	for _, ignored := range md.IgnoredHosts {
+		if host == ignored || strings.HasSuffix(host, "."+ignored) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchKeyword returns the mirror section keyword contained in text, if any
+func (md *MirrorDetector) matchKeyword(text string) string {
+	if len(text) > 200 {
+		return ""
+	}
+	lower := strings.ToLower(text)
+	for _, keyword := range md.SectionKeywords {
+		if strings.Contains(lower, keyword) {
+			return keyword
+		}
+	}
+	return ""
+}
+
+// Limits of the block read after a link alternatif heading
+const (
+	maxSectionSiblings = 8
+	maxSectionText     = 1000
+)
+
+// sectionBlocks returns the elements following a section heading up to the
+// next heading, stopping at the first list or when the section grows too
+// large to be a list of mirrors. A heading wrapped on its own (e.g.
+// <div><h3>Link Alternatif</h3></div>) is followed from its wrapper.
+func sectionBlocks(heading *html.Node) []*html.Node {
+	start := heading
+	if nextElementSibling(start) == nil && start.Parent != nil && start.Parent.Data != "body" {
+		start = start.Parent
+	}
+
+	var blocks []*html.Node
+	size := 0
+	for sibling := nextElementSibling(start); sibling != nil && len(blocks) < maxSectionSiblings; sibling = nextElementSibling(sibling) {
+		if headingTags[sibling.Data] {
+			break
+		}
+		size += len(nodeText(sibling))
+		if size > maxSectionText {
+			break
+		}
+		blocks = append(blocks, sibling)
+		switch sibling.Data {
+		case "ul", "ol", "dl", "table":
+			return blocks
+		}
+	}
+	return blocks
+}
+
+// headingTags end a link alternatif section
+var headingTags = map[string]bool{
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// nextElementSibling returns the next sibling of n that is an element
+func nextElementSibling(n *html.Node) *html.Node {
+	for c := n.NextSibling; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			return c
+		}
+	}
+	return nil
+}
+
+// elementURLs returns the URLs an element links or redirects to
+func (md *MirrorDetector) elementURLs(n *html.Node) []string {
+	var urls []string
+	for _, key := range []string{"href", "data-href", "data-url", "data-link"} {
+		if value := nodeAttr(n, key); value != "" {
+			urls = append(urls, value)
+		}
+	}
+	if onclick := nodeAttr(n, "onclick"); onclick != "" {
+		urls = append(urls, md.URLRegex.FindAllString(onclick, -1)...)
+	}
+	return urls
+}
+
+// textDomains returns domain names written out as plain text
+func (md *MirrorDetector) textDomains(text string) []string {
+	var domains []string
+	for _, m := range md.DomainRegex.FindAllStringSubmatch(text, -1) {
+		domain := strings.ToLower(m[1])
+		if fileExtensionRegex.MatchString(domain) {
+			continue
+		}
+		domains = append(domains, domain)
+	}
+	return domains
+}
+
+var (
+	fileExtensionRegex = regexp.MustCompile(`\.(?:html?|php|aspx?|jsp|js|css|png|jpe?g|gif|webp|svg|ico)$`)
+	brandRegex         = regexp.MustCompile(`^[a-z]+`)
+)
+
+// sameBrand reports whether two hosts share the alphabetic brand prefix of
+// their first label (gacor88.com and gacor99.net share "gacor")
+func sameBrand(host, pageHost string) bool {
+	if host == pageHost {
+		return false
+	}
+	brand := brandRegex.FindString(strings.SplitN(host, ".", 2)[0])
+	pageBrand := brandRegex.FindString(strings.SplitN(pageHost, ".", 2)[0])
+	return len(brand) >= 4 && brand == pageBrand
+}
+
+// ownText returns the text of a node's direct text children
+func ownText(n *html.Node) string {
+	var parts []string
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			parts = append(parts, c.Data)
+		}
+	}
+	return strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+}
+
+// normalizeLinkURL turns a bare or scheme-relative link into an absolute URL
+func normalizeLinkURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	lower := strings.ToLower(rawURL)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "//") {
+		return rawURL
+	}
+	return ""
+}
+
+// urlPath returns the path of a possibly scheme-relative URL
+func urlPath(rawURL string) string {
+	if strings.HasPrefix(rawURL, "//") {
+		rawURL = "https:" + rawURL
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Path
+}
+
+// stripWWW removes a leading "www." label
+func stripWWW(host string) string {
+	return strings.TrimPrefix(host, "www.")
+}
+
+// mirrorSourceRank orders mirror links from strongest to weakest source
+func mirrorSourceRank(source string) int {
+	switch source {
+	case MirrorSourceSection:
+		return 0
+	case MirrorSourceOutbound:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// truncateContext shortens link text for evidence references
+func truncateContext(text string) string {
+	if len(text) > 60 {
+		return text[:60] + "..."
+	}
+	return text
+}
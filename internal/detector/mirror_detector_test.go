@@ -0,0 +1,87 @@
+package detector
+
+import (
+	"testing"
+)
+
+// TestExtractMirrors tests mirror discovery from link alternatif sections, brand links and shortlinks
+func TestExtractMirrors(t *testing.T) {
+	md := NewMirrorDetector()
+
+	testContent := `
+	<html>
+	<body>
+		<div class="alt-links">
+			<h3>LINK ALTERNATIF GACOR88</h3>
+			<a href="https://gacor88b.net/">Link 1</a>
+			<button onclick="window.location.href='https://masukgacor.org/daftar'">Link 2</button>
+			<p>Atau ketik: gacor88vip.xyz</p>
+		</div>
+		<a class="btn" href="https://gacor88.com/login">Login</a>
+		<a href="https://www.gacor99.site/">Promo</a>
+		<a href="https://s.id/gacor88daftar?ref=1">Daftar</a>
+		<a href="https://wa.me/6281234567890">CS</a>
+		<a href="https://news.example.com/">Berita</a>
+	</body>
+	</html>
+	`
+
+	links := md.ExtractMirrors(testContent, "www.gacor88.com")
+
+	expected := map[string]string{
+		"gacor88b.net":       MirrorSourceSection,
+		"masukgacor.org":     MirrorSourceSection,
+		"gacor88vip.xyz":     MirrorSourceSection,
+		"gacor99.site":       MirrorSourceOutbound,
+		"s.id/gacor88daftar": MirrorSourceShortlink,
+	}
+
+	found := make(map[string]string)
+	for _, link := range links {
+		found[link.Domain] = link.Source
+	}
+
+	for domain, source := range expected {
+		if found[domain] != source {
+			t.Errorf("Expected %s to be found via %s, got %q", domain, source, found[domain])
+		}
+	}
+
+	if len(links) != len(expected) {
+		t.Errorf("Expected %d mirror links, got %d: %v", len(expected), len(links), links)
+	}
+
+	signals := md.DetectMirrorSignals(links)
+	if len(signals) != 1 || signals[0].Confidence != 0.6 || signals[0].Category != "INFRA" {
+		t.Errorf("Expected one INFRA MIRROR_DOMAINS signal with section confidence, got %v", signals)
+	}
+}
+
+// TestExtractMirrorsSectionScope tests that a link alternatif heading only
+// collects the list following it and skips well-known hosts
+func TestExtractMirrorsSectionScope(t *testing.T) {
+	md := NewMirrorDetector()
+
+	testContent := `
+	<html>
+	<body>
+		<main>
+			<h2>Link Alternatif</h2>
+			<ul>
+				<li><a href="https://gacor88b.net/">gacor88b.net</a></li>
+				<li><a href="https://instagram.com/gacor88">Instagram</a></li>
+				<li><a href="https://cdnjs.cloudflare.com/ajax/libs/jquery.js">jQuery</a></li>
+			</ul>
+			<h2>Berita Terkini</h2>
+			<p>Baca di <a href="https://kompas.com/berita">kompas.com</a> dan detik.com</p>
+			<a href="https://tokopedia.com/promo">Promo</a>
+		</main>
+	</body>
+	</html>
+	`
+
+	links := md.ExtractMirrors(testContent, "gacor88.com")
+	if len(links) != 1 || links[0].Domain != "gacor88b.net" || links[0].Source != MirrorSourceSection {
+		t.Errorf("Expected only gacor88b.net from the section, got %v", links)
+	}
+}
@@ -0,0 +1,215 @@
+package detector
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// MinHashSize is the number of hash functions in a MinHash signature
+const MinHashSize = 64
+
+// LSH banding of MinHash signatures, tuned for near-duplicates. Pages become
+// candidates when all rows of any band agree, which for a Jaccard similarity
+// s happens with probability 1-(1-s^LSHRows)^LSHBands: about 0.9998 at
+// s = 0.8, 0.99 at 0.7 and 0.12 at 0.3.
+const (
+	LSHBands = 16
+	LSHRows  = MinHashSize / LSHBands
+)
+
+// LSHMinSimilarity is the lowest similarity the LSH buckets find reliably.
+// Lookups for less similar pages compare every page instead.
+const LSHMinSimilarity = 0.7
+
+// signatureSkipTags are elements whose content is not rendered as page text
+var signatureSkipTags = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "svg": true,
+}
+
+var wordRegex = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// minHashSeeds are the per-function seeds of the MinHash family
+var minHashSeeds = func() []uint64 {
+	seeds := make([]uint64, MinHashSize)
+	for i := range seeds {
+		seeds[i] = mix64(uint64(i) + 0x9e3779b97f4a7c15)
+	}
+	return seeds
+}()
+
+// ComputePageSignature computes SimHash signatures of the visible text and
+// the DOM structure of a page, and a MinHash signature over both shingle
+// sets. It returns nil for pages without content.
+func ComputePageSignature(body string) *models.PageSignature {
+	textShingles, domShingles := pageShingles(body)
+	if len(textShingles) == 0 && len(domShingles) == 0 {
+		return nil
+	}
+
+	signature := &models.PageSignature{
+		MinHash: minHash(append(append([]string{}, textShingles...), domShingles...)),
+	}
+	if len(textShingles) > 0 {
+		signature.TextSimHash = formatHash(simHash(textShingles))
+	}
+	if len(domShingles) > 0 {
+		signature.DOMSimHash = formatHash(simHash(domShingles))
+	}
+
+	return signature
+}
+
+// LSHBandKeys returns the bucket key of each band of a MinHash signature
+// split into bands of rows values
+func LSHBandKeys(minHash []uint32, bands, rows int) []uint64 {
+	keys := make([]uint64, bands)
+	buf := make([]byte, 4)
+	for band := 0; band < bands; band++ {
+		h := fnv.New64a()
+		binary.LittleEndian.PutUint32(buf, uint32(band))
+		h.Write(buf)
+		for _, v := range minHash[band*rows : (band+1)*rows] {
+			binary.LittleEndian.PutUint32(buf, v)
+			h.Write(buf)
+		}
+		keys[band] = h.Sum64()
+	}
+	return keys
+}
+
+// MinHashSimilarity estimates the Jaccard similarity of the shingle sets
+// behind two MinHash signatures
+func MinHashSimilarity(a, b []uint32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	equal := 0
+	for i := range a {
+		if a[i] == b[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(a))
+}
+
+// pageShingles returns the word 3-grams of the visible text and the 4-grams
+// of the element sequence of a page
+func pageShingles(body string) ([]string, []string) {
+	var words []string
+	var elements []string
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch c.Type {
+			case html.TextNode:
+				words = append(words, wordRegex.FindAllString(strings.ToLower(c.Data), -1)...)
+			case html.ElementNode:
+				if signatureSkipTags[c.Data] {
+					continue
+				}
+				// The parser adds html, head and body to every document
+				if c.Data == "html" || c.Data == "head" || c.Data == "body" {
+					walk(c)
+					continue
+				}
+				classes := strings.Fields(strings.ToLower(nodeAttr(c, "class")))
+				sort.Strings(classes)
+				elements = append(elements, strings.Join(append([]string{c.Data}, classes...), "."))
+				walk(c)
+			default:
+				walk(c)
+			}
+		}
+	}
+	walk(parseHTML(body))
+
+	return shingles(words, 3, ""), shingles(elements, 4, "dom:")
+}
+
+// shingles returns the unique n-grams of tokens, or the tokens themselves
+// when there are fewer than n
+func shingles(tokens []string, n int, prefix string) []string {
+	if len(tokens) == 0 {
+		return nil
+	}
+	if len(tokens) < n {
+		n = len(tokens)
+	}
+
+	seen := make(map[string]bool)
+	var result []string
+	for i := 0; i+n <= len(tokens); i++ {
+		shingle := prefix + strings.Join(tokens[i:i+n], " ")
+		if !seen[shingle] {
+			seen[shingle] = true
+			result = append(result, shingle)
+		}
+	}
+	return result
+}
+
+// simHash computes the 64-bit SimHash of a set of features
+func simHash(features []string) uint64 {
+	var counts [64]int
+	for _, feature := range features {
+		h := hash64(feature)
+		for bit := 0; bit < 64; bit++ {
+			if h&(1<<uint(bit)) != 0 {
+				counts[bit]++
+			} else {
+				counts[bit]--
+			}
+		}
+	}
+
+	var hash uint64
+	for bit := 0; bit < 64; bit++ {
+		if counts[bit] > 0 {
+			hash |= 1 << uint(bit)
+		}
+	}
+	return hash
+}
+
+// minHash computes the MinHash signature of a set of features
+func minHash(features []string) []uint32 {
+	signature := make([]uint32, MinHashSize)
+	for i := range signature {
+		signature[i] = ^uint32(0)
+	}
+
+	for _, feature := range features {
+		h := hash64(feature)
+		for i, seed := range minHashSeeds {
+			if v := uint32(mix64(h^seed) >> 32); v < signature[i] {
+				signature[i] = v
+			}
+		}
+	}
+	return signature
+}
+
+// hash64 returns the 64-bit FNV-1a hash of s
+func hash64(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// mix64 is the SplitMix64 finalizer, used to derive independent hash functions
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
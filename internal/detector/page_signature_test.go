@@ -0,0 +1,47 @@
+package detector
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestPageSignature tests that mirrored pages produce similar signatures
+func TestPageSignature(t *testing.T) {
+	page := func(brand string, extra string) string {
+		return `<html><body>
+			<div class="header"><a class="logo">` + brand + `</a><a class="btn-daftar">Daftar</a></div>
+			<div class="running-text">Selamat datang di ` + brand + ` situs slot gacor terpercaya dengan RTP tertinggi hari ini</div>
+			<ul class="game-list"><li>Gates of Olympus</li><li>Mahjong Ways 2</li><li>Starlight Princess</li></ul>
+			<p>Deposit via DANA, OVO, GoPay dan QRIS mulai 10 ribu. Bonus new member 100 persen. ` + extra + `</p>
+			<footer class="footer">Copyright 2024 ` + brand + `. Semua hak dilindungi.</footer>
+			<script>var x = "` + strings.Repeat("ignored ", 20) + `";</script>
+		</body></html>`
+	}
+
+	original := ComputePageSignature(page("GACOR88", ""))
+	mirror := ComputePageSignature(page("GACOR99", "Withdraw cepat."))
+	unrelated := ComputePageSignature(`<html><body><article><h1>Resep nasi goreng</h1>
+		<p>Panaskan minyak, tumis bawang merah dan bawang putih hingga harum, lalu masukkan nasi.</p></article></body></html>`)
+
+	if original == nil || len(original.MinHash) != MinHashSize {
+		t.Fatalf("Expected a MinHash signature of %d values, got %v", MinHashSize, original)
+	}
+
+	if similarity := MinHashSimilarity(original.MinHash, mirror.MinHash); similarity < 0.5 {
+		t.Errorf("Expected mirrored pages to be similar, got %.2f", similarity)
+	}
+	if similarity := MinHashSimilarity(original.MinHash, unrelated.MinHash); similarity > 0.2 {
+		t.Errorf("Expected unrelated pages to differ, got %.2f", similarity)
+	}
+
+	if d := HammingDistance(original.DOMSimHash, mirror.DOMSimHash); d != 0 {
+		t.Errorf("Expected identical DOM structure to give equal DOM SimHashes, got distance %d", d)
+	}
+	if d := HammingDistance(original.TextSimHash, mirror.TextSimHash); d < 0 || d > 16 {
+		t.Errorf("Expected close text SimHashes, got distance %d", d)
+	}
+
+	if ComputePageSignature("") != nil {
+		t.Error("Expected no signature for an empty page")
+	}
+}
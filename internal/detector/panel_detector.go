@@ -0,0 +1,381 @@
+package detector
+
+import (
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"gopkg.in/yaml.v3"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+//go:embed data/panels.yaml
+var defaultPanelCatalog []byte
+
+// Panel fingerprint resource types
+const (
+	ResourcePanel       = "panel"
+	ResourceDOMSkeleton = "dom_skeleton"
+	ResourceAssetSet    = "asset_set"
+)
+
+// Panel describes the structural markers of a white-label panel
+type Panel struct {
+	Name           string   `yaml:"name"`
+	Markers        []string `yaml:"markers"`
+	Scripts        []string `yaml:"scripts"`
+	Endpoints      []string `yaml:"endpoints"`
+	Classes        []string `yaml:"classes"`
+	SkeletonHashes []string `yaml:"skeleton_hashes"`
+	AssetHashes    []string `yaml:"asset_hashes"`
+}
+
+// PanelCatalog holds the known white-label panels
+type PanelCatalog struct {
+	MinScore int     `yaml:"min_score"`
+	Panels   []Panel `yaml:"panels"`
+}
+
+// PanelFingerprint holds the structural fingerprint of a page
+type PanelFingerprint struct {
+	SkeletonHash string
+	// SkeletonSize is the number of elements in the collapsed skeleton
+	SkeletonSize int
+	AssetSetHash string
+	Assets       []string
+	Classes      map[string]bool
+}
+
+// PanelResult holds the fingerprint of a page and the panel it matched, if any
+type PanelResult struct {
+	Fingerprint *PanelFingerprint
+	Platform    string
+	Score       int
+	Evidence    []string
+}
+
+// PanelDetector fingerprints the white-label panel a site is built on
+type PanelDetector struct {
+	Catalog *PanelCatalog
+}
+
+// NewPanelDetector creates a new panel detector. If catalogPath is empty the
+// built-in catalog is used.
+func NewPanelDetector(catalogPath string) (*PanelDetector, error) {
+	data := defaultPanelCatalog
+	if catalogPath != "" {
+		fileData, err := os.ReadFile(catalogPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read panel catalog: %v", err)
+		}
+		data = fileData
+	}
+
+	catalog, err := ParsePanelCatalog(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PanelDetector{Catalog: catalog}, nil
+}
+
+// ParsePanelCatalog parses a YAML panel catalog
+func ParsePanelCatalog(data []byte) (*PanelCatalog, error) {
+	catalog := &PanelCatalog{}
+	if err := yaml.Unmarshal(data, catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse panel catalog: %v", err)
+	}
+	if catalog.MinScore <= 0 {
+		catalog.MinScore = 2
+	}
+	return catalog, nil
+}
+
+// FingerprintPanel computes the page fingerprint and matches it against the catalog
+func (pd *PanelDetector) FingerprintPanel(body string) *PanelResult {
+	result := &PanelResult{
+		Fingerprint: ComputePanelFingerprint(body),
+	}
+
+	lowerBody := strings.ToLower(body)
+	for _, panel := range pd.Catalog.Panels {
+		score, evidence := pd.scorePanel(panel, body, lowerBody, result.Fingerprint)
+		if score >= pd.Catalog.MinScore && score > result.Score {
+			result.Platform = panel.Name
+			result.Score = score
+			result.Evidence = evidence
+		}
+	}
+
+	return result
+}
+
+// scorePanel scores how well a page matches one catalog panel
+func (pd *PanelDetector) scorePanel(panel Panel, body, lowerBody string, fp *PanelFingerprint) (int, []string) {
+	score := 0
+	var evidence []string
+
+	for _, hash := range panel.SkeletonHashes {
+		if hash == fp.SkeletonHash {
+			score += pd.Catalog.MinScore
+			evidence = append(evidence, "DOM skeleton hash "+hash)
+		}
+	}
+	for _, hash := range panel.AssetHashes {
+		if hash == fp.AssetSetHash {
+			score += pd.Catalog.MinScore
+			evidence = append(evidence, "asset set hash "+hash)
+		}
+	}
+	for _, marker := range panel.Markers {
+		if strings.Contains(body, marker) {
+			score++
+			evidence = append(evidence, "marker "+marker)
+		}
+	}
+	for _, script := range panel.Scripts {
+		for _, asset := range fp.Assets {
+			if strings.Contains(asset, strings.ToLower(script)) {
+				score++
+				evidence = append(evidence, "asset path "+script)
+				break
+			}
+		}
+	}
+	for _, endpoint := range panel.Endpoints {
+		if strings.Contains(lowerBody, strings.ToLower(endpoint)) {
+			score++
+			evidence = append(evidence, "API endpoint "+endpoint)
+		}
+	}
+	for _, class := range panel.Classes {
+		if fp.Classes[strings.ToLower(class)] {
+			score++
+			evidence = append(evidence, "CSS class "+class)
+		}
+	}
+
+	return score, evidence
+}
+
+// ExtractPanelResources returns the structural hashes and matched panel as shared resources
+func (pd *PanelDetector) ExtractPanelResources(result *PanelResult) []models.Resource {
+	var resources []models.Resource
+
+	if result.Platform != "" {
+		resources = append(resources, models.Resource{Type: ResourcePanel, Value: result.Platform})
+	}
+	// Small skeletons (parked pages, redirects, bare templates) are shared by
+	// unrelated sites
+	if result.Fingerprint.SkeletonHash != "" && result.Fingerprint.SkeletonSize >= minSkeletonSize {
+		resources = append(resources, models.Resource{Type: ResourceDOMSkeleton, Value: result.Fingerprint.SkeletonHash})
+	}
+	if result.Fingerprint.AssetSetHash != "" {
+		resources = append(resources, models.Resource{Type: ResourceAssetSet, Value: result.Fingerprint.AssetSetHash})
+	}
+
+	return resources
+}
+
+// DetectPanelSignals produces a signal when the page matches a known panel
+func (pd *PanelDetector) DetectPanelSignals(result *PanelResult) []models.Signal {
+	var signals []models.Signal
+
+	if result.Platform == "" {
+		return signals
+	}
+
+	signal := models.Signal{
+		SignalID:    "panel_" + strings.ReplaceAll(strings.ToLower(result.Platform), " ", "_"),
+		Category:    "INFRA",
+		Description: "Site runs on white-label panel: " + result.Platform,
+		Confidence:  0.7,
+		Evidence: []models.Evidence{
+			{
+				Type:      "html",
+				Reference: "Matched panel '" + result.Platform + "': " + strings.Join(result.Evidence, "; "),
+				Timestamp: time.Now(),
+			},
+		},
+	}
+	signals = append(signals, signal)
+
+	return signals
+}
+
+// skeletonSkipTags are elements whose content varies per deployment and is
+// not part of the structural template
+var skeletonSkipTags = map[string]bool{
+	"script": true, "style": true, "noscript": true, "svg": true, "template": true, "iframe": true,
+}
+
+// minSkeletonSize is the number of elements a skeleton needs to be shared
+// as a dom_skeleton resource
+const minSkeletonSize = 12
+
+// thirdPartyAssetHosts are widget and analytics hosts that are not part of a panel's bundle
+var thirdPartyAssetHosts = []string{
+	"googletagmanager.com", "google-analytics.com", "facebook.net", "cloudflareinsights.com",
+	"tawk.to", "livechatinc.com", "jivosite.com", "histats.com", "statcounter.com", "yandex.ru",
+	// Public library CDNs
+	"cdnjs.cloudflare.com", "cdn.jsdelivr.net", "unpkg.com", "code.jquery.com", "ajax.googleapis.com",
+	"fonts.googleapis.com", "stackpath.bootstrapcdn.com", "maxcdn.bootstrapcdn.com", "use.fontawesome.com",
+}
+
+// publicLibraries are common libraries that sites self-host; they say
+// nothing about which panel a site runs on
+var publicLibraries = map[string]bool{
+	"jquery": true, "jquery-ui": true, "jquery.ui": true, "bootstrap": true, "bootstrap.bundle": true,
+	"popper": true, "font-awesome": true, "fontawesome": true, "swiper": true, "swiper-bundle": true,
+	"slick": true, "slick-theme": true, "owl.carousel": true, "owl.theme.default": true, "animate": true,
+	"lodash": true, "moment": true, "vue": true, "react": true, "react-dom": true, "axios": true,
+	"sweetalert": true, "sweetalert2": true, "select2": true, "aos": true, "wow": true, "lazysizes": true,
+}
+
+var (
+	assetHashRegex    = regexp.MustCompile(`([.\-_~])[0-9a-f]{6,}\.`)
+	assetVersionRegex = regexp.MustCompile(`[0-9]+(\.[0-9]+)+`)
+	classIDRegex      = regexp.MustCompile(`[0-9]{3,}`)
+)
+
+// ComputePanelFingerprint hashes the normalized DOM skeleton and the set of
+// script and stylesheet paths of a page
+func ComputePanelFingerprint(body string) *PanelFingerprint {
+	fp := &PanelFingerprint{
+		Classes: make(map[string]bool),
+	}
+
+	doc := parseHTML(body)
+
+	assets := make(map[string]bool)
+	var bodyNode *html.Node
+	walkElements(doc, func(n *html.Node) bool {
+		for _, class := range strings.Fields(strings.ToLower(nodeAttr(n, "class"))) {
+			fp.Classes[class] = true
+		}
+
+		switch n.Data {
+		case "body":
+			bodyNode = n
+		case "script":
+			if asset := normalizeAssetPath(nodeAttr(n, "src")); asset != "" {
+				assets[asset] = true
+			}
+		case "link":
+			if strings.Contains(strings.ToLower(nodeAttr(n, "rel")), "stylesheet") {
+				if asset := normalizeAssetPath(nodeAttr(n, "href")); asset != "" {
+					assets[asset] = true
+				}
+			}
+		}
+		return true
+	})
+
+	for asset := range assets {
+		fp.Assets = append(fp.Assets, asset)
+	}
+	sort.Strings(fp.Assets)
+	if len(fp.Assets) > 0 {
+		fp.AssetSetHash = shortHash(strings.Join(fp.Assets, "\n"))
+	}
+
+	if bodyNode != nil {
+		if skeleton, size := domSkeleton(bodyNode); skeleton != "" {
+			fp.SkeletonHash = shortHash(skeleton)
+			fp.SkeletonSize = size
+		}
+	}
+
+	return fp
+}
+
+// domSkeleton serializes the element structure below n. Tag names and
+// class lists are kept, text is dropped, and runs of identical sibling
+// subtrees (game grids, banner carousels) are collapsed so the skeleton does
+// not depend on how many items a site lists. It also returns the number of
+// elements kept.
+func domSkeleton(n *html.Node) (string, int) {
+	var children []string
+	size := 1
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || skeletonSkipTags[c.Data] {
+			continue
+		}
+		child, childSize := domSkeleton(c)
+		if len(children) > 0 && children[len(children)-1] == child {
+			continue
+		}
+		children = append(children, child)
+		size += childSize
+	}
+
+	var classes []string
+	for _, class := range strings.Fields(strings.ToLower(nodeAttr(n, "class"))) {
+		if !classIDRegex.MatchString(class) {
+			classes = append(classes, class)
+		}
+	}
+	sort.Strings(classes)
+
+	token := n.Data
+	if len(classes) > 0 {
+		token += "." + strings.Join(classes, ".")
+	}
+	if len(children) > 0 {
+		token += "(" + strings.Join(children, ",") + ")"
+	}
+	return token, size
+}
+
+// normalizeAssetPath strips the host, query and build hashes from an asset
+// URL. Third-party and public library assets are dropped.
+func normalizeAssetPath(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+
+	host := urlHost(rawURL)
+	for _, thirdParty := range thirdPartyAssetHosts {
+		if host == thirdParty || strings.HasSuffix(host, "."+thirdParty) {
+			return ""
+		}
+	}
+
+	path := rawURL
+	if parsed, err := url.Parse(strings.TrimSpace(rawURL)); err == nil {
+		path = parsed.Path
+	}
+	path = strings.ToLower(path)
+	path = assetHashRegex.ReplaceAllString(path, "$1*.")
+	path = assetVersionRegex.ReplaceAllString(path, "*")
+	if isPublicLibrary(path) {
+		return ""
+	}
+
+	return path
+}
+
+// isPublicLibrary reports whether a normalized asset path is a build of a
+// common public library, e.g. /js/jquery-*.min.js
+func isPublicLibrary(path string) bool {
+	name := path[strings.LastIndex(path, "/")+1:]
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".js"), ".css")
+	name = strings.TrimSuffix(name, ".min")
+	name = strings.TrimRight(name, "*-._")
+	return publicLibraries[name]
+}
+
+// shortHash returns a short hex SHA-1 digest of s
+func shortHash(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])[:16]
+}
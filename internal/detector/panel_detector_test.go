@@ -0,0 +1,122 @@
+package detector
+
+import (
+	"testing"
+)
+
+// testPanelCatalog is a panel catalog entry of the kind gathered from sample sites
+const testPanelCatalog = `
+min_score: 2
+panels:
+  - name: Vue Member Panel
+    markers: ["window.__MEMBER_CONFIG__", "id=\"member-app\""]
+    scripts: [/static/js/member., /static/css/member.]
+    endpoints: [/api/member/login, /api/member/balance]
+    classes: [member-header, game-provider-list]
+`
+
+// TestPanelFingerprint tests that mirrors of the same panel share structural hashes
+func TestPanelFingerprint(t *testing.T) {
+	catalog, err := ParsePanelCatalog([]byte(testPanelCatalog))
+	if err != nil {
+		t.Fatalf("Failed to parse panel catalog: %v", err)
+	}
+	pd := &PanelDetector{Catalog: catalog}
+
+	siteA := `
+	<html>
+	<head>
+		<link rel="stylesheet" href="/static/css/member.3f9a1c2b.css">
+		<script src="https://www.googletagmanager.com/gtag/js?id=G-ABC1234567"></script>
+	</head>
+	<body>
+		<div id="member-app" class="member-header"><a class="logo">GACOR88</a>
+			<nav class="menu"><a class="menu-item">Slot</a><a class="menu-item">Casino</a></nav>
+			<form class="login-form"><input name="username"><input name="password"><button class="btn-login">Masuk</button></form>
+		</div>
+		<ul class="game-provider-list">
+			<li class="item"><img src="/img/a.png"></li>
+			<li class="item"><img src="/img/b.png"></li>
+		</ul>
+		<footer class="footer"><p class="copyright">GACOR88</p></footer>
+		<script>window.__MEMBER_CONFIG__ = {api: "/api/member/login"};</script>
+		<script src="/static/js/member.8d7e6f5a.js?v=2"></script>
+	</body>
+	</html>`
+
+	siteB := `
+	<html>
+	<head>
+		<link rel="stylesheet" href="https://cdn.maxwin77.net/static/css/member.aa11bb22.css">
+	</head>
+	<body>
+		<div id="member-app" class="member-header"><a class="logo">MAXWIN77</a>
+			<nav class="menu"><a class="menu-item">Slot</a><a class="menu-item">Casino</a><a class="menu-item">Togel</a></nav>
+			<form class="login-form"><input name="username"><input name="password"><button class="btn-login">Login</button></form>
+		</div>
+		<ul class="game-provider-list">
+			<li class="item"><img src="/img/x.png"></li>
+			<li class="item"><img src="/img/y.png"></li>
+			<li class="item"><img src="/img/z.png"></li>
+		</ul>
+		<footer class="footer"><p class="copyright">MAXWIN77</p></footer>
+		<script>window.__MEMBER_CONFIG__ = {};</script>
+		<script src="/static/js/member.0c0d0e0f.js"></script>
+	</body>
+	</html>`
+
+	resultA := pd.FingerprintPanel(siteA)
+	resultB := pd.FingerprintPanel(siteB)
+
+	if resultA.Fingerprint.SkeletonHash == "" || resultA.Fingerprint.SkeletonHash != resultB.Fingerprint.SkeletonHash {
+		t.Errorf("Expected matching DOM skeleton hashes, got %q and %q",
+			resultA.Fingerprint.SkeletonHash, resultB.Fingerprint.SkeletonHash)
+	}
+
+	if resultA.Fingerprint.AssetSetHash == "" || resultA.Fingerprint.AssetSetHash != resultB.Fingerprint.AssetSetHash {
+		t.Errorf("Expected matching asset set hashes, got %v and %v",
+			resultA.Fingerprint.Assets, resultB.Fingerprint.Assets)
+	}
+
+	if resultA.Platform != "Vue Member Panel" {
+		t.Errorf("Expected platform 'Vue Member Panel', got %q", resultA.Platform)
+	}
+
+	if len(pd.DetectPanelSignals(resultA)) != 1 {
+		t.Errorf("Expected one panel signal")
+	}
+
+	if len(pd.ExtractPanelResources(resultA)) != 3 {
+		t.Errorf("Expected panel, dom_skeleton and asset_set resources, got %v", pd.ExtractPanelResources(resultA))
+	}
+
+	unrelated := pd.FingerprintPanel(`<html><body><main><article><p>Berita hari ini</p></article></main></body></html>`)
+	if unrelated.Platform != "" {
+		t.Errorf("Expected no platform for unrelated page, got %q", unrelated.Platform)
+	}
+	if unrelated.Fingerprint.SkeletonHash == resultA.Fingerprint.SkeletonHash {
+		t.Errorf("Expected different skeleton hash for unrelated page")
+	}
+}
+
+// TestPanelResourcesSkipGenericPages tests that small skeletons and public
+// libraries are not shared as panel resources
+func TestPanelResourcesSkipGenericPages(t *testing.T) {
+	pd, err := NewPanelDetector("")
+	if err != nil {
+		t.Fatalf("Failed to load built-in panel catalog: %v", err)
+	}
+
+	result := pd.FingerprintPanel(`<html><head>
+		<link rel="stylesheet" href="/assets/css/bootstrap.min.css">
+		<script src="/assets/js/jquery-3.6.0.min.js"></script>
+		<script src="https://cdn.jsdelivr.net/npm/swiper@11/swiper-bundle.min.js"></script>
+	</head><body><div class="container"><h1>Segera Hadir</h1></div></body></html>`)
+
+	if len(result.Fingerprint.Assets) != 0 || result.Fingerprint.AssetSetHash != "" {
+		t.Errorf("Expected public libraries to be ignored, got %v", result.Fingerprint.Assets)
+	}
+	if resources := pd.ExtractPanelResources(result); len(resources) != 0 {
+		t.Errorf("Expected no resources for a generic page, got %v", resources)
+	}
+}
@@ -0,0 +1,107 @@
+package detector
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// Resource types of domain registrations, shared by domains an operator
+// registers in bulk
+const (
+	ResourceRegistrar       = "registrar"
+	ResourcePrivacyService  = "privacy_service"
+	ResourceRegistrationDay = "registration_day"
+)
+
+// RegistrationDetector detects judol-typical registrations: freshly
+// registered domains, privacy services and registrars favored by operators
+type RegistrationDetector struct {
+	// NewDomainDays and RecentDomainDays are the ages, in days, below which a
+	// domain counts as newly and recently registered
+	NewDomainDays    int
+	RecentDomainDays int
+	// RiskyRegistrars are lowercase registrar name fragments
+	RiskyRegistrars []string
+}
+
+// NewRegistrationDetector creates a new registration detector
+func NewRegistrationDetector() *RegistrationDetector {
+	return &RegistrationDetector{
+		NewDomainDays:    30,
+		RecentDomainDays: 90,
+		// Registrars behind many judol domains in takedown lists
+		RiskyRegistrars: []string{"gname", "dominet", "namesilo", "web commerce communications", "pdr ltd"},
+	}
+}
+
+// DetectRegistration produces signals and resources from a registration
+func (rd *RegistrationDetector) DetectRegistration(registration *models.Registration, now time.Time) ([]models.Signal, []models.Resource) {
+	var signals []models.Signal
+	var resources []models.Resource
+
+	if registration.CreatedAt != nil {
+		days := int(registration.Age(now).Hours() / 24)
+		switch {
+		case days < rd.NewDomainDays:
+			signals = append(signals, registrationSignal(registration, "DOMAIN_NEWLY_REGISTERED",
+				fmt.Sprintf("Domain registered %d days ago", days), 0.7,
+				"Registered on "+registration.CreatedAt.Format("2006-01-02")))
+		case days < rd.RecentDomainDays:
+			signals = append(signals, registrationSignal(registration, "DOMAIN_RECENTLY_REGISTERED",
+				fmt.Sprintf("Domain registered %d days ago", days), 0.4,
+				"Registered on "+registration.CreatedAt.Format("2006-01-02")))
+		}
+	}
+
+	if registration.PrivacyService != "" {
+		signals = append(signals, registrationSignal(registration, "DOMAIN_PRIVACY_SERVICE",
+			"Registrant hidden behind privacy service: "+registration.PrivacyService, 0.3,
+			"Registrant "+registration.PrivacyService))
+		resources = append(resources, models.Resource{Type: ResourcePrivacyService, Value: strings.ToLower(registration.PrivacyService)})
+	}
+
+	if registration.Registrar != "" {
+		registrar := strings.ToLower(registration.Registrar)
+		for _, risky := range rd.RiskyRegistrars {
+			if risky != "" && strings.Contains(registrar, strings.ToLower(risky)) {
+				signals = append(signals, registrationSignal(registration, "DOMAIN_RISKY_REGISTRAR",
+					"Domain registered through registrar common among judol sites: "+registration.Registrar, 0.4,
+					"Registrar "+registration.Registrar))
+				break
+			}
+		}
+
+		resources = append(resources, models.Resource{Type: ResourceRegistrar, Value: registrar})
+		// Domains registered the same day at the same registrar are often one bulk order
+		if registration.CreatedAt != nil {
+			resources = append(resources, models.Resource{
+				Type:  ResourceRegistrationDay,
+				Value: registrar + "|" + registration.CreatedAt.Format("2006-01-02"),
+			})
+		}
+	}
+
+	return signals, resources
+}
+
+// registrationSignal builds a registration signal with one piece of evidence
+// naming the service that answered
+func registrationSignal(registration *models.Registration, signalID, description string, confidence float64, reference string) models.Signal {
+	return models.Signal{
+		SignalID:    signalID,
+		Category:    "DNS",
+		Description: description,
+		Confidence:  confidence,
+		Evidence: []models.Evidence{
+			{
+				Type:      registration.Source,
+				Reference: reference,
+				Source:    registration.Server,
+				Timestamp: time.Now(),
+			},
+		},
+	}
+}
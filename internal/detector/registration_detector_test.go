@@ -0,0 +1,62 @@
+package detector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// TestDetectRegistration tests age, privacy and registrar signals and the
+// registration resources
+func TestDetectRegistration(t *testing.T) {
+	rd := NewRegistrationDetector()
+	now := time.Date(2025, 6, 18, 0, 0, 0, 0, time.UTC)
+	created := now.AddDate(0, 0, -10)
+
+	signals, resources := rd.DetectRegistration(&models.Registration{
+		Domain:         "gacor88.xyz",
+		Registrar:      "Gname.com Pte. Ltd.",
+		CreatedAt:      &created,
+		PrivacyService: "Withheld for Privacy ehf",
+		Source:         "rdap",
+		Server:         "https://rdap.example/",
+	}, now)
+
+	found := make(map[string]models.Signal)
+	for _, signal := range signals {
+		found[signal.SignalID] = signal
+	}
+	for _, id := range []string{"DOMAIN_NEWLY_REGISTERED", "DOMAIN_PRIVACY_SERVICE", "DOMAIN_RISKY_REGISTRAR"} {
+		if _, ok := found[id]; !ok {
+			t.Errorf("Expected %s signal, got %v", id, found)
+		}
+	}
+	if evidence := found["DOMAIN_NEWLY_REGISTERED"].Evidence[0]; evidence.Type != "rdap" || evidence.Source != "https://rdap.example/" {
+		t.Errorf("Expected RDAP evidence naming the server, got %+v", evidence)
+	}
+
+	types := make(map[string]string)
+	for _, resource := range resources {
+		types[resource.Type] = resource.Value
+	}
+	if types[ResourceRegistrationDay] != "gname.com pte. ltd.|2025-06-08" || types[ResourceRegistrar] == "" || types[ResourcePrivacyService] == "" {
+		t.Errorf("Expected registrar, registration day and privacy resources, got %v", types)
+	}
+}
+
+// TestDetectRegistrationEstablished tests that an old domain at a common
+// registrar produces no signals
+func TestDetectRegistrationEstablished(t *testing.T) {
+	now := time.Date(2025, 6, 18, 0, 0, 0, 0, time.UTC)
+	created := now.AddDate(-8, 0, 0)
+
+	signals, _ := NewRegistrationDetector().DetectRegistration(&models.Registration{
+		Registrar: "PT Pandi Registrar",
+		CreatedAt: &created,
+		Source:    "whois",
+	}, now)
+	if len(signals) != 0 {
+		t.Errorf("Expected no signals, got %v", signals)
+	}
+}
@@ -0,0 +1,231 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// Inputs a detector can require from the page. A detector whose inputs are
+// missing is skipped instead of run.
+const (
+	InputBody    = "body"
+	InputHeaders = "headers"
+	InputNetwork = "network"
+)
+
+// Page is the fetched page handed to each detector. Detectors may record
+// resources, relations and page attributes on it; detectors run in
+// registration order, so later detectors see what earlier ones recorded.
+type Page struct {
+	Domain      string
+	URL         string
+	StatusCode  int
+	Headers     http.Header
+	Body        string
+	CDNProvider string
+	// CDN is the CDN identified from the response, with its fingerprints
+	CDN *CDNInfo
+	// CDNObservation is what CDN was identified from, for modules that
+	// add to it and identify the CDN again
+	CDNObservation CDNObservation
+
+	// Client and Timeout are used by detectors that make further requests
+	Client  *http.Client
+	Timeout time.Duration
+
+	Resources      []models.Resource
+	Relations      []models.Relation
+	RedirectChains []models.RedirectChain
+	GameProviders  []string
+	Platform       string
+	InjectedURLs   []string
+	DNSRecords     []models.DNSRecord
+	BlockStatus    *models.BlockStatus
+	Registration   *models.Registration
+	Subdomains     []models.Subdomain
+}
+
+// Detector is a detection module run against every scanned page
+type Detector interface {
+	// Name identifies the module in --modules and --skip-modules
+	Name() string
+	// Category is the signal category the module mainly produces
+	Category() string
+	// Inputs lists the parts of the page the module needs
+	Inputs() []string
+	// Analyze returns the signals found on the page
+	Analyze(ctx context.Context, page *Page) ([]models.Signal, error)
+}
+
+// funcDetector adapts a function to the Detector interface
+type funcDetector struct {
+	name     string
+	category string
+	inputs   []string
+	analyze  func(ctx context.Context, page *Page) ([]models.Signal, error)
+}
+
+// NewFuncDetector creates a detector that runs analyze
+func NewFuncDetector(name, category string, inputs []string, analyze func(ctx context.Context, page *Page) ([]models.Signal, error)) Detector {
+	return &funcDetector{name: name, category: category, inputs: inputs, analyze: analyze}
+}
+
+func (f *funcDetector) Name() string     { return f.name }
+func (f *funcDetector) Category() string { return f.category }
+func (f *funcDetector) Inputs() []string { return f.inputs }
+
+func (f *funcDetector) Analyze(ctx context.Context, page *Page) ([]models.Signal, error) {
+	return f.analyze(ctx, page)
+}
+
+// Registry holds detection modules in the order they run
+type Registry struct {
+	detectors []Detector
+}
+
+// DefaultRegistry holds the built-in modules and those added with Register
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a module to the default registry. It is meant to be called
+// from init functions and panics if the name is already taken.
+func Register(d Detector) {
+	DefaultRegistry.Register(d)
+}
+
+// Register adds a module to the registry. It panics if the name is already taken.
+func (r *Registry) Register(d Detector) {
+	for _, existing := range r.detectors {
+		if existing.Name() == d.Name() {
+			panic("detector: module " + d.Name() + " registered twice")
+		}
+	}
+	r.detectors = append(r.detectors, d)
+}
+
+// Detectors returns the registered modules in run order
+func (r *Registry) Detectors() []Detector {
+	return append([]Detector{}, r.detectors...)
+}
+
+// Names returns the names of the registered modules, sorted
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.detectors))
+	for _, d := range r.detectors {
+		names = append(names, d.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Select returns a registry with only the enabled modules, or all modules if
+// enabled is empty, minus the skipped ones. Unknown names are an error.
+func (r *Registry) Select(enabled, skipped []string) (*Registry, error) {
+	known := make(map[string]bool)
+	for _, d := range r.detectors {
+		known[d.Name()] = true
+	}
+
+	var unknown []string
+	enabledSet := make(map[string]bool)
+	for _, name := range enabled {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+		enabledSet[name] = true
+	}
+	skippedSet := make(map[string]bool)
+	for _, name := range skipped {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+		skippedSet[name] = true
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown modules: %s (available: %s)",
+			strings.Join(unknown, ", "), strings.Join(r.Names(), ", "))
+	}
+
+	selected := NewRegistry()
+	for _, d := range r.detectors {
+		if (len(enabled) == 0 || enabledSet[d.Name()]) && !skippedSet[d.Name()] {
+			selected.detectors = append(selected.detectors, d)
+		}
+	}
+	return selected, nil
+}
+
+// Run runs the modules against the page in order and returns their signals
+// along with the timing and outcome of each module. A module that fails or
+// panics is recorded and does not stop the others.
+func (r *Registry) Run(ctx context.Context, page *Page) ([]models.Signal, []models.ModuleRun) {
+	var signals []models.Signal
+	var runs []models.ModuleRun
+
+	for _, d := range r.detectors {
+		run := models.ModuleRun{Name: d.Name()}
+
+		if err := ctx.Err(); err != nil {
+			run.Skipped = true
+			run.Error = err.Error()
+		} else if missing := missingInput(d, page); missing != "" {
+			run.Skipped = true
+			run.Error = "missing input: " + missing
+		} else {
+			start := time.Now()
+			moduleSignals, err := runDetector(ctx, d, page)
+			run.Duration = time.Since(start)
+			run.Signals = len(moduleSignals)
+			if err != nil {
+				run.Error = err.Error()
+			}
+			signals = append(signals, moduleSignals...)
+		}
+
+		runs = append(runs, run)
+	}
+
+	return signals, runs
+}
+
+// runDetector runs one module, turning a panic into an error
+func runDetector(ctx context.Context, d Detector, page *Page) (signals []models.Signal, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			signals = nil
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return d.Analyze(ctx, page)
+}
+
+// missingInput returns the first input the module needs that the page lacks
+func missingInput(d Detector, page *Page) string {
+	for _, input := range d.Inputs() {
+		switch input {
+		case InputBody:
+			if page.Body == "" {
+				return input
+			}
+		case InputHeaders:
+			if page.Headers == nil {
+				return input
+			}
+		case InputNetwork:
+			if page.Client == nil {
+				return input
+			}
+		}
+	}
+	return ""
+}
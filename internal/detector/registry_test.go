@@ -0,0 +1,65 @@
+package detector
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// TestRegistryRun tests module ordering, selection, input checks and error capture
+func TestRegistryRun(t *testing.T) {
+	registry := NewRegistry()
+	body := []string{InputBody}
+
+	registry.Register(NewFuncDetector("first", "UX", body, func(ctx context.Context, page *Page) ([]models.Signal, error) {
+		page.Platform = "panel-x"
+		return []models.Signal{{SignalID: "FIRST"}}, nil
+	}))
+	registry.Register(NewFuncDetector("second", "UX", body, func(ctx context.Context, page *Page) ([]models.Signal, error) {
+		// Later modules see what earlier ones recorded
+		return []models.Signal{{SignalID: "SECOND_" + page.Platform}}, nil
+	}))
+	registry.Register(NewFuncDetector("broken", "INFRA", body, func(ctx context.Context, page *Page) ([]models.Signal, error) {
+		var resources []models.Resource
+		_ = resources[1]
+		return nil, nil
+	}))
+	registry.Register(NewFuncDetector("failing", "INFRA", body, func(ctx context.Context, page *Page) ([]models.Signal, error) {
+		return nil, errors.New("lookup failed")
+	}))
+	registry.Register(NewFuncDetector("network", "DNS", []string{InputNetwork}, func(ctx context.Context, page *Page) ([]models.Signal, error) {
+		return []models.Signal{{SignalID: "NETWORK"}}, nil
+	}))
+
+	signals, runs := registry.Run(context.Background(), &Page{Domain: "example.com", Body: "<html></html>"})
+
+	if len(signals) != 2 || signals[0].SignalID != "FIRST" || signals[1].SignalID != "SECOND_panel-x" {
+		t.Errorf("Expected FIRST and SECOND_panel-x signals, got %v", signals)
+	}
+	if len(runs) != 5 {
+		t.Fatalf("Expected a run record per module, got %v", runs)
+	}
+	if runs[2].Error == "" || runs[2].Skipped {
+		t.Errorf("Expected the panic in broken to be recorded as an error, got %+v", runs[2])
+	}
+	if runs[3].Error != "lookup failed" {
+		t.Errorf("Expected the failing module's error to be recorded, got %+v", runs[3])
+	}
+	if !runs[4].Skipped {
+		t.Errorf("Expected the network module to be skipped without a client, got %+v", runs[4])
+	}
+
+	selected, err := registry.Select([]string{"first", "second", "network"}, []string{"second"})
+	if err != nil {
+		t.Fatalf("Unexpected selection error: %v", err)
+	}
+	if names := selected.Names(); len(names) != 2 || names[0] != "first" || names[1] != "network" {
+		t.Errorf("Expected first and network to be selected, got %v", names)
+	}
+
+	if _, err := registry.Select([]string{"missing"}, nil); err == nil {
+		t.Error("Expected an error selecting an unknown module")
+	}
+}
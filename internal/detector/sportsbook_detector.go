@@ -0,0 +1,126 @@
+package detector
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// SportsbookDetector detects sportsbook pages: odds tables, match lists,
+// parlay and handicap bet types, and white-label sportsbook brands
+type SportsbookDetector struct {
+	OddsRegex    *regexp.Regexp
+	FixtureRegex *regexp.Regexp
+	Brands       []string
+	BetTypes     []string
+	TableHeaders []string
+}
+
+// NewSportsbookDetector creates a new sportsbook detector
+func NewSportsbookDetector() *SportsbookDetector {
+	return &SportsbookDetector{
+		// Decimal, Malay and Indo odds, optionally as a split handicap line (0.5/1)
+		OddsRegex:    regexp.MustCompile(`^[+-]?\d{1,2}\.\d{1,3}(?:\s*/\s*\d{1,2}(?:\.\d{1,3})?)?$`),
+		FixtureRegex: regexp.MustCompile(`(?i)\b\p{L}[\p{L}.' ]{1,30}\s+(?:vs\.?|v\.)\s+\p{L}`),
+		Brands: []string{
+			"sbobet", "maxbet", "saba sports", "ibcbet", "cmd368", "cmd sports",
+			"united gaming", "ug sports", "bti sports", "3sing", "wbet", "pinnacle", "im sports",
+		},
+		BetTypes: []string{
+			"mix parlay", "parlay", "asian handicap", "handicap", "over/under", "over under",
+			"1x2", "odd/even", "ganjil genap", "correct score", "half time/full time", "outright",
+		},
+		TableHeaders: []string{"hdp", "handicap", "o/u", "1x2", "odds", "home", "away", "ft", "ht"},
+	}
+}
+
+// DetectSportsbook produces sportsbook signals from the page content
+func (sd *SportsbookDetector) DetectSportsbook(content string) []models.Signal {
+	var signals []models.Signal
+
+	doc := parseHTML(content)
+	text := strings.ToLower(visibleText(doc))
+
+	if tables := sd.countOddsTables(doc); tables > 0 {
+		signals = append(signals, verticalSignal(models.VerticalSportsbook, "SPORTSBOOK_ODDS_TABLE",
+			"Found sportsbook odds table", 0.85,
+			"Found table of odds prices with handicap, over/under or match columns"))
+	}
+
+	// Brands are matched in iframe sources, logos and link hosts; names like
+	// "pinnacle" are ordinary words in page text
+	urls := embeddedURLs(doc)
+	var brands []string
+	for _, brand := range sd.Brands {
+		if containsNameInURLs(urls, brand) {
+			brands = append(brands, brand)
+		}
+	}
+	if len(brands) > 0 {
+		signals = append(signals, verticalSignal(models.VerticalSportsbook, "SPORTSBOOK_BRANDS",
+			"Found sportsbook brands: "+strings.Join(brands, ", "), 0.8,
+			"Found sportsbook platform names in embedded sources and link hosts"))
+	}
+
+	var betTypes []string
+	for _, betType := range sd.BetTypes {
+		if containsWord(text, betType) {
+			betTypes = append(betTypes, betType)
+		}
+	}
+	if len(betTypes) >= 2 {
+		signals = append(signals, verticalSignal(models.VerticalSportsbook, "SPORTSBOOK_BET_TYPES",
+			"Found sportsbook bet types: "+strings.Join(betTypes, ", "), 0.75,
+			"Found sportsbook bet-type jargon in page text"))
+	}
+
+	if fixtures := len(sd.FixtureRegex.FindAllString(text, -1)); fixtures >= 3 {
+		signals = append(signals, verticalSignal(models.VerticalSportsbook, "SPORTSBOOK_MATCH_LIST",
+			"Found list of matches", 0.6,
+			"Found "+strconv.Itoa(fixtures)+" 'team vs team' fixtures in page text"))
+	}
+
+	return signals
+}
+
+// countOddsTables counts tables with at least four odds cells and a header
+// or cell naming a handicap, odds or match column
+func (sd *SportsbookDetector) countOddsTables(doc *html.Node) int {
+	count := 0
+	walkElements(doc, func(n *html.Node) bool {
+		if n.Data != "table" {
+			return true
+		}
+
+		odds := 0
+		labelled := false
+		walkElements(n, func(cell *html.Node) bool {
+			if cell.Data != "td" && cell.Data != "th" {
+				return true
+			}
+			cellText := strings.ToLower(strings.TrimSpace(nodeText(cell)))
+			if sd.OddsRegex.MatchString(cellText) {
+				odds++
+			}
+			if sd.FixtureRegex.MatchString(cellText) {
+				labelled = true
+			}
+			for _, header := range sd.TableHeaders {
+				if containsWord(cellText, header) {
+					labelled = true
+				}
+			}
+			return false
+		})
+
+		if odds >= 4 && labelled {
+			count++
+		}
+		return false
+	})
+	return count
+}
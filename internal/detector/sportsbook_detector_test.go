@@ -0,0 +1,87 @@
+package detector
+
+import (
+	"testing"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// TestDetectSportsbook tests odds tables, brands, bet types and match lists
+func TestDetectSportsbook(t *testing.T) {
+	sd := NewSportsbookDetector()
+
+	testContent := `
+	<html>
+	<body>
+		<iframe src="https://sports.sbobet.com/euro"></iframe>
+		<h2>Mix Parlay dan Asian Handicap</h2>
+		<table>
+			<tr><th>Match</th><th>HDP</th><th>Home</th><th>Away</th></tr>
+			<tr><td>Arsenal vs Chelsea</td><td>0.5/1</td><td>1.95</td><td>-0.87</td></tr>
+			<tr><td>Liverpool vs Everton</td><td>1.25</td><td>0.92</td><td>0.98</td></tr>
+		</table>
+		<ul>
+			<li>Persib vs Persija</li>
+		</ul>
+	</body>
+	</html>
+	`
+
+	signals := sd.DetectSportsbook(testContent)
+
+	found := make(map[string]bool)
+	for _, signal := range signals {
+		found[signal.SignalID] = true
+		if signal.Vertical != models.VerticalSportsbook {
+			t.Errorf("Expected %s to be tagged with the sportsbook vertical, got %q", signal.SignalID, signal.Vertical)
+		}
+	}
+
+	for _, id := range []string{"SPORTSBOOK_ODDS_TABLE", "SPORTSBOOK_BRANDS", "SPORTSBOOK_BET_TYPES", "SPORTSBOOK_MATCH_LIST"} {
+		if !found[id] {
+			t.Errorf("Expected %s signal, got %v", id, signals)
+		}
+	}
+}
+
+// TestDetectSportsbookPriceTable tests that a plain price table is not taken for odds
+func TestDetectSportsbookPriceTable(t *testing.T) {
+	sd := NewSportsbookDetector()
+
+	testContent := `
+	<html>
+	<body>
+		<table>
+			<tr><th>Produk</th><th>Harga</th></tr>
+			<tr><td>Kopi</td><td>2.50</td></tr>
+			<tr><td>Teh</td><td>1.75</td></tr>
+			<tr><td>Roti</td><td>3.20</td></tr>
+			<tr><td>Susu</td><td>2.10</td></tr>
+		</table>
+	</body>
+	</html>
+	`
+
+	if signals := sd.DetectSportsbook(testContent); len(signals) != 0 {
+		t.Errorf("Expected no sportsbook signals on a price list, got %v", signals)
+	}
+}
+
+// TestDetectSportsbookBrandNamesInText tests that brand names in page text
+// are not taken for embedded sportsbooks
+func TestDetectSportsbookBrandNamesInText(t *testing.T) {
+	sd := NewSportsbookDetector()
+
+	testContent := `
+	<html>
+	<body>
+		<h1>Pinnacle Properti</h1>
+		<p>Reaching the pinnacle of united gaming communities.</p>
+	</body>
+	</html>
+	`
+
+	if signals := sd.DetectSportsbook(testContent); len(signals) != 0 {
+		t.Errorf("Expected no sportsbook signals, got %v", signals)
+	}
+}
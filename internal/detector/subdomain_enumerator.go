@@ -0,0 +1,261 @@
+package detector
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/resolver"
+)
+
+//go:embed data/subdomains.yaml
+var defaultSubdomainWordlist []byte
+
+// hostRegex matches host names in page content
+var hostRegex = regexp.MustCompile(`(?i)\b(?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,24}\b`)
+
+// SubdomainEnumerator finds the resolving subdomains of a domain from a
+// wordlist, certificate transparency logs and links on the page. Names that
+// only get the answers a random label gets are wildcard answers and are
+// dropped.
+type SubdomainEnumerator struct {
+	Resolver    resolver.Resolver
+	Wordlist    []string
+	Concurrency int
+	// CTURL is a crt.sh-compatible search URL; empty disables CT lookups
+	CTURL string
+	HTTP  *http.Client
+	// CDNStatus names the CDN serving a host at its addresses, "none" or
+	// "unknown"; when nil the CDN status is not checked
+	CDNStatus func(host string, addresses []string) string
+}
+
+// NewSubdomainEnumerator creates an enumerator resolving the names of a
+// wordlist through r
+func NewSubdomainEnumerator(r resolver.Resolver, wordlist []string) *SubdomainEnumerator {
+	return &SubdomainEnumerator{
+		Resolver:    r,
+		Wordlist:    wordlist,
+		Concurrency: 20,
+		HTTP:        &http.Client{Timeout: resolver.DefaultTimeout},
+	}
+}
+
+// LoadSubdomainWordlist reads a wordlist: a YAML file with a subdomains list,
+// or a text file with one name per line. If path is empty the built-in
+// wordlist is used.
+func LoadSubdomainWordlist(path string) ([]string, error) {
+	if path == "" {
+		return ParseSubdomainWordlist(defaultSubdomainWordlist, true)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subdomain wordlist: %v", err)
+	}
+	return ParseSubdomainWordlist(data, strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml"))
+}
+
+// ParseSubdomainWordlist parses a YAML or plain text wordlist
+func ParseSubdomainWordlist(data []byte, isYAML bool) ([]string, error) {
+	var names []string
+	if isYAML {
+		var wordlist struct {
+			Subdomains []string `yaml:"subdomains"`
+		}
+		if err := yaml.Unmarshal(data, &wordlist); err != nil {
+			return nil, fmt.Errorf("failed to parse subdomain wordlist: %v", err)
+		}
+		names = wordlist.Subdomains
+	} else {
+		scanner := bufio.NewScanner(strings.NewReader(string(data)))
+		for scanner.Scan() {
+			names = append(names, scanner.Text())
+		}
+	}
+
+	seen := make(map[string]bool)
+	var wordlist []string
+	for _, name := range names {
+		name = strings.ToLower(strings.Trim(strings.TrimSpace(name), "."))
+		if name == "" || strings.HasPrefix(name, "#") || seen[name] {
+			continue
+		}
+		seen[name] = true
+		wordlist = append(wordlist, name)
+	}
+	return wordlist, nil
+}
+
+// Enumerate returns the resolving subdomains of domain, ordered by name.
+// pageNames are host names found on the domain's pages.
+func (se *SubdomainEnumerator) Enumerate(ctx context.Context, domain string, pageNames []string) []models.Subdomain {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+
+	sources := make(map[string][]string)
+	var names []string
+	add := func(name, source string) {
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+		if name == domain || !strings.HasSuffix(name, "."+domain) || strings.Contains(name, "*") {
+			return
+		}
+		if _, ok := sources[name]; !ok {
+			names = append(names, name)
+		}
+		sources[name] = appendUniqueString(sources[name], source)
+	}
+	for _, word := range se.Wordlist {
+		add(word+"."+domain, models.SubdomainSourceWordlist)
+	}
+	if se.CTURL != "" {
+		ctNames, err := se.CTNames(ctx, domain)
+		if err != nil {
+			fmt.Printf("Error querying certificate transparency for %s: %v\n", domain, err)
+		}
+		for _, name := range ctNames {
+			add(name, models.SubdomainSourceCT)
+		}
+	}
+	for _, name := range pageNames {
+		add(name, models.SubdomainSourcePage)
+	}
+
+	wildcard := se.WildcardAddresses(ctx, domain)
+
+	found := make([]*models.Subdomain, len(names))
+	concurrency := se.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				addresses, err := resolver.LookupIPv4(ctx, se.Resolver, names[i])
+				if err != nil || len(addresses) == 0 || onlyWildcard(addresses, wildcard) {
+					continue
+				}
+				subdomain := &models.Subdomain{Name: names[i], Sources: sources[names[i]], Addresses: addresses}
+				if se.CDNStatus != nil {
+					subdomain.CDN = se.CDNStatus(names[i], addresses)
+				}
+				found[i] = subdomain
+			}
+		}()
+	}
+	for i := range names {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var subdomains []models.Subdomain
+	for _, subdomain := range found {
+		if subdomain != nil {
+			subdomains = append(subdomains, *subdomain)
+		}
+	}
+	sort.Slice(subdomains, func(i, j int) bool { return subdomains[i].Name < subdomains[j].Name })
+	return subdomains
+}
+
+// WildcardAddresses returns the addresses random labels under domain
+// resolve to; empty when the domain has no wildcard record
+func (se *SubdomainEnumerator) WildcardAddresses(ctx context.Context, domain string) map[string]bool {
+	addresses := make(map[string]bool)
+	// Two probes, in case the wildcard rotates its answers
+	for i := 0; i < 2; i++ {
+		probe := fmt.Sprintf("fogger-%08x.%s", rand.Uint32(), domain)
+		answers, err := resolver.LookupIPv4(ctx, se.Resolver, probe)
+		if err != nil {
+			continue
+		}
+		for _, address := range answers {
+			addresses[address] = true
+		}
+	}
+	return addresses
+}
+
+// CTNames returns the names under domain in certificates logged to
+// certificate transparency, from a crt.sh-compatible search
+func (se *SubdomainEnumerator) CTNames(ctx context.Context, domain string) ([]string, error) {
+	query := url.Values{"q": {"%." + domain}, "output": {"json"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, se.CTURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := se.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, se.CTURL)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return nil, err
+	}
+	var entries []struct {
+		NameValue string `json:"name_value"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid certificate transparency response: %v", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		// name_value holds the certificate's names, one per line
+		for _, name := range strings.Split(entry.NameValue, "\n") {
+			name = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), "*.")
+			if name != "" {
+				names = appendUniqueString(names, name)
+			}
+		}
+	}
+	return names, nil
+}
+
+// ExtractSubdomains returns the host names under domain found in page content
+func ExtractSubdomains(content, domain string) []string {
+	domain = strings.ToLower(domain)
+	var names []string
+	for _, host := range hostRegex.FindAllString(content, -1) {
+		host = strings.ToLower(host)
+		if strings.HasSuffix(host, "."+domain) {
+			names = appendUniqueString(names, host)
+		}
+	}
+	return names
+}
+
+// onlyWildcard reports whether every address is a wildcard answer
+func onlyWildcard(addresses []string, wildcard map[string]bool) bool {
+	if len(wildcard) == 0 {
+		return false
+	}
+	for _, address := range addresses {
+		if !wildcard[address] {
+			return false
+		}
+	}
+	return true
+}
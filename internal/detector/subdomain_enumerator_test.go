@@ -0,0 +1,102 @@
+package detector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/resolver"
+	"github.com/genesis410/fogger/internal/resolver/resolvertest"
+)
+
+// TestEnumerateSubdomains tests wordlist, CT and page names, wildcard
+// filtering and the CDN status of each subdomain
+func TestEnumerateSubdomains(t *testing.T) {
+	server, err := resolvertest.NewServer([]models.DNSRecord{
+		{Name: "*.gacor88.xyz", Type: "A", TTL: 300, Value: "203.0.113.1"},
+		{Name: "mail.gacor88.xyz", Type: "A", TTL: 300, Value: "198.51.100.5"},
+		{Name: "panel.gacor88.xyz", Type: "A", TTL: 300, Value: "198.51.100.6"},
+		{Name: "rtp.gacor88.xyz", Type: "A", TTL: 300, Value: "104.16.1.1"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start DNS server: %v", err)
+	}
+	defer server.Close()
+
+	ct := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") != "%.gacor88.xyz" {
+			t.Errorf("Unexpected CT query %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`[{"name_value":"gacor88.xyz\n*.gacor88.xyz"},{"name_value":"panel.gacor88.xyz"}]`))
+	}))
+	defer ct.Close()
+
+	enumerator := NewSubdomainEnumerator(resolver.NewServer(server.Addr, false, time.Second), []string{"mail", "api", "ftp"})
+	enumerator.CTURL = ct.URL + "/"
+	enumerator.CDNStatus = func(host string, addresses []string) string {
+		if host == "rtp.gacor88.xyz" {
+			return "cloudflare"
+		}
+		return "none"
+	}
+
+	page := ExtractSubdomains(`<a href="https://rtp.gacor88.xyz/live">RTP</a> <a href="https://other.example">x</a>`, "gacor88.xyz")
+	subdomains := enumerator.Enumerate(context.Background(), "gacor88.xyz", page)
+
+	// api and ftp only get the wildcard answer
+	if len(subdomains) != 3 {
+		t.Fatalf("Expected mail, panel and rtp, got %+v", subdomains)
+	}
+	expected := []struct{ name, source, cdn string }{
+		{"mail.gacor88.xyz", models.SubdomainSourceWordlist, "none"},
+		{"panel.gacor88.xyz", models.SubdomainSourceCT, "none"},
+		{"rtp.gacor88.xyz", models.SubdomainSourcePage, "cloudflare"},
+	}
+	for i, want := range expected {
+		got := subdomains[i]
+		if got.Name != want.name || got.Sources[0] != want.source || got.CDN != want.cdn || len(got.Addresses) != 1 {
+			t.Errorf("Expected %s from %s with CDN %s, got %+v", want.name, want.source, want.cdn, got)
+		}
+	}
+}
+
+// TestParseSubdomainWordlist tests YAML and text wordlists and the built-in one
+func TestParseSubdomainWordlist(t *testing.T) {
+	text, err := ParseSubdomainWordlist([]byte("# comment\nMail\n\napi\napi.\n"), false)
+	if err != nil || len(text) != 2 || text[0] != "mail" || text[1] != "api" {
+		t.Errorf("Expected mail and api from the text wordlist, got %v (%v)", text, err)
+	}
+
+	builtIn, err := LoadSubdomainWordlist("")
+	if err != nil {
+		t.Fatalf("Unexpected error loading built-in wordlist: %v", err)
+	}
+	seen := make(map[string]bool)
+	for _, name := range builtIn {
+		if seen[name] {
+			t.Errorf("Duplicate name %s in built-in wordlist", name)
+		}
+		seen[name] = true
+	}
+	if !seen["api"] || !seen["mail"] {
+		t.Errorf("Expected api and mail in the built-in wordlist, got %v", builtIn)
+	}
+}
+
+// TestOriginIPSubdomains tests that only subdomains served directly count as origin IPs
+func TestOriginIPSubdomains(t *testing.T) {
+	od := NewOriginIPDetector()
+	od.Subdomains = []models.Subdomain{
+		{Name: "mail.gacor88.xyz", Addresses: []string{"198.51.100.5"}, CDN: "none"},
+		{Name: "rtp.gacor88.xyz", Addresses: []string{"104.16.1.1"}, CDN: "cloudflare"},
+		{Name: "old.gacor88.xyz", Addresses: []string{"198.51.100.9"}, CDN: "unknown"},
+	}
+
+	ips, evidence := od.checkSubdomains("gacor88.xyz")
+	if len(ips) != 1 || ips[0] != "198.51.100.5" || len(evidence) != 1 {
+		t.Errorf("Expected only the mail server address, got %v", ips)
+	}
+}
@@ -0,0 +1,248 @@
+package detector
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// TogelDetector detects togel (lottery) sites: result tables for the
+// pasaran (markets), keluaran and prediction pages, and bet-type jargon
+type TogelDetector struct {
+	// MarketRegex matches market names in page text: names only used for
+	// togel, and country names next to togel words ("togel china",
+	// "taiwan pools")
+	MarketRegex *regexp.Regexp
+	// CountryRegex matches bare country names, which only count as markets
+	// in rows of a result table
+	CountryRegex    *regexp.Regexp
+	ResultRegex     *regexp.Regexp
+	KeluaranPhrases []string
+	PrediksiPhrases []string
+	BetTypes        []string
+	// DigitBetTypes only count as bet types on pages naming togel or a market
+	DigitBetTypes []string
+	TableHeaders  []string
+}
+
+// togelCountries are market names that are ordinary words outside togel pages
+const togelCountries = `hongkong|hong kong|singapore|singapura|sydney|macau|cambodia|taiwan|china|japan`
+
+// NewTogelDetector creates a new togel detector
+func NewTogelDetector() *TogelDetector {
+	return &TogelDetector{
+		MarketRegex: regexp.MustCompile(`(?i)\b(?:(hk pools|hk lotto|sgp pools|sgp|sdy pools|sdy|toto macau|pcso|bullseye|magnum 4d|toto 4d)` +
+			`|(?:togel|pasaran|keluaran|result|live draw)\s+(` + togelCountries + `)` +
+			`|(` + togelCountries + `)\s+(?:pools|lotto|prize|result))\b`),
+		CountryRegex: regexp.MustCompile(`(?i)\b(` + togelCountries + `)\b`),
+		ResultRegex:  regexp.MustCompile(`^\d{4,6}$|^\d(?:\s\d){3,5}$`),
+		KeluaranPhrases: []string{
+			"keluaran hari ini", "keluaran", "pengeluaran", "data keluaran", "result togel",
+			"live draw", "hasil undian", "nomor keluar",
+		},
+		PrediksiPhrases: []string{
+			"prediksi", "angka main", "angka jitu", "angka ikut", "bocoran", "syair",
+			"paito", "buku mimpi", "erek-erek", "rumus togel", "bbfs",
+		},
+		BetTypes: []string{
+			"colok bebas", "colok macau", "colok naga", "colok jitu",
+			"shio", "50-50", "kembang kempis", "tengah tepi", "silang homo",
+			"diskon 4d", "hadiah 4d", "bet 100 perak", "bb campuran",
+		},
+		DigitBetTypes: []string{"4d", "3d", "2d"},
+		TableHeaders:  []string{"result", "keluaran", "tanggal", "pasaran", "periode", "hari", "prize"},
+	}
+}
+
+// DetectTogel produces togel signals from the page content
+func (td *TogelDetector) DetectTogel(content string) []models.Signal {
+	var signals []models.Signal
+
+	doc := parseHTML(content)
+	text := strings.ToLower(visibleText(doc))
+
+	// Result tables listing drawn numbers per market and date
+	markets := make(map[string]bool)
+	if tables := td.countResultTables(doc, markets); tables > 0 {
+		signals = append(signals, verticalSignal(models.VerticalTogel, "TOGEL_RESULT_TABLE",
+			"Found togel result table", 0.85,
+			"Found table of drawn 4D numbers with market or date columns"))
+	}
+
+	// Pasaran list
+	for _, m := range td.MarketRegex.FindAllStringSubmatch(text, -1) {
+		markets[normalizeMarket(firstGroup(m))] = true
+	}
+	if len(markets) >= 3 {
+		signals = append(signals, verticalSignal(models.VerticalTogel, "TOGEL_MARKETS",
+			"Found togel markets: "+strings.Join(sortedKeys(markets), ", "), 0.7,
+			"Found togel market names in page text"))
+	}
+
+	if phrase := firstPhrase(text, td.KeluaranPhrases); phrase != "" && len(markets) > 0 {
+		signals = append(signals, verticalSignal(models.VerticalTogel, "TOGEL_KELUARAN",
+			"Found togel result (keluaran) page", 0.75,
+			"Found phrase '"+phrase+"' next to togel market names"))
+	}
+
+	// "Prediksi" and "bocoran" are also used for football, stocks and news
+	if phrase := firstPhrase(text, td.PrediksiPhrases); phrase != "" && len(markets) > 0 {
+		signals = append(signals, verticalSignal(models.VerticalTogel, "TOGEL_PREDICTION",
+			"Found togel prediction content", 0.7,
+			"Found prediction phrase '"+phrase+"' next to togel market names"))
+	}
+
+	var betTypes []string
+	for _, betType := range td.BetTypes {
+		if containsWord(text, betType) {
+			betTypes = append(betTypes, betType)
+		}
+	}
+	if len(markets) > 0 || containsWord(text, "togel") {
+		for _, betType := range td.DigitBetTypes {
+			if containsWord(text, betType) {
+				betTypes = append(betTypes, betType)
+			}
+		}
+	}
+	if len(betTypes) >= 3 {
+		signals = append(signals, verticalSignal(models.VerticalTogel, "TOGEL_BET_TYPES",
+			"Found togel bet types: "+strings.Join(betTypes, ", "), 0.75,
+			"Found togel bet-type jargon in page text"))
+	}
+
+	return signals
+}
+
+// countResultTables counts tables with at least three cells holding drawn
+// numbers and a header or cell naming a market, date or result column. The
+// markets named in result tables are added to markets.
+func (td *TogelDetector) countResultTables(doc *html.Node, markets map[string]bool) int {
+	count := 0
+	walkElements(doc, func(n *html.Node) bool {
+		if n.Data != "table" {
+			return true
+		}
+
+		numbers := 0
+		labelled := false
+		tableMarkets := make(map[string]bool)
+		walkElements(n, func(cell *html.Node) bool {
+			if cell.Data != "td" && cell.Data != "th" {
+				return true
+			}
+			cellText := strings.ToLower(nodeText(cell))
+			if td.ResultRegex.MatchString(cellText) {
+				numbers++
+			}
+			if m := td.MarketRegex.FindStringSubmatch(cellText); m != nil {
+				tableMarkets[normalizeMarket(firstGroup(m))] = true
+			} else if m := td.CountryRegex.FindStringSubmatch(cellText); m != nil {
+				tableMarkets[normalizeMarket(m[1])] = true
+			}
+			if len(tableMarkets) > 0 || firstPhrase(cellText, td.TableHeaders) != "" {
+				labelled = true
+			}
+			return false
+		})
+
+		if numbers >= 3 && labelled {
+			count++
+			for market := range tableMarkets {
+				markets[market] = true
+			}
+		}
+		return false
+	})
+	return count
+}
+
+// verticalSignal builds a UX signal tagged with a gambling vertical
+func verticalSignal(vertical, signalID, description string, confidence float64, reference string) models.Signal {
+	return models.Signal{
+		SignalID:    signalID,
+		Category:    "UX",
+		Description: description,
+		Confidence:  confidence,
+		Vertical:    vertical,
+		Evidence: []models.Evidence{
+			{
+				Type:      "html",
+				Reference: reference,
+				Timestamp: time.Now(),
+			},
+		},
+	}
+}
+
+// normalizeMarket maps market name variants to one name
+func normalizeMarket(market string) string {
+	switch market = strings.ToLower(market); market {
+	case "hk pools", "hk lotto", "hong kong":
+		return "hongkong"
+	case "sgp", "sgp pools", "singapura":
+		return "singapore"
+	case "sdy", "sdy pools":
+		return "sydney"
+	case "toto macau":
+		return "macau"
+	default:
+		return market
+	}
+}
+
+// firstGroup returns the first non-empty capture group of a match
+func firstGroup(match []string) string {
+	for _, group := range match[1:] {
+		if group != "" {
+			return group
+		}
+	}
+	return ""
+}
+
+// firstPhrase returns the first phrase contained in text
+func firstPhrase(text string, phrases []string) string {
+	for _, phrase := range phrases {
+		if strings.Contains(text, phrase) {
+			return phrase
+		}
+	}
+	return ""
+}
+
+// containsWord reports whether text contains word delimited by non-alphanumerics
+func containsWord(text, word string) bool {
+	for start := 0; ; {
+		idx := strings.Index(text[start:], word)
+		if idx < 0 {
+			return false
+		}
+		idx += start
+		end := idx + len(word)
+		if (idx == 0 || !isAlnum(text[idx-1])) && (end == len(text) || !isAlnum(text[end])) {
+			return true
+		}
+		start = idx + 1
+	}
+}
+
+// isAlnum reports whether b is an ASCII letter or digit
+func isAlnum(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// sortedKeys returns the keys of a set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,87 @@
+package detector
+
+import (
+	"testing"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// TestDetectTogel tests togel result tables, markets, predictions and bet types
+func TestDetectTogel(t *testing.T) {
+	td := NewTogelDetector()
+
+	testContent := `
+	<html>
+	<body>
+		<h1>Data Keluaran Hari Ini</h1>
+		<table>
+			<tr><th>Pasaran</th><th>Tanggal</th><th>Result</th></tr>
+			<tr><td>Hongkong</td><td>17-10-2026</td><td>4821</td></tr>
+			<tr><td>Singapore</td><td>17-10-2026</td><td>0937</td></tr>
+			<tr><td>Sydney</td><td>17-10-2026</td><td>6 1 5 2</td></tr>
+		</table>
+		<p>Prediksi HK malam ini dan angka main jitu.</p>
+		<p>Diskon 4D, 3D, 2D dan colok bebas tersedia.</p>
+		<script>var markets = ["cambodia", "taiwan"];</script>
+	</body>
+	</html>
+	`
+
+	signals := td.DetectTogel(testContent)
+
+	found := make(map[string]bool)
+	for _, signal := range signals {
+		found[signal.SignalID] = true
+		if signal.Vertical != models.VerticalTogel {
+			t.Errorf("Expected %s to be tagged with the togel vertical, got %q", signal.SignalID, signal.Vertical)
+		}
+	}
+
+	for _, id := range []string{"TOGEL_RESULT_TABLE", "TOGEL_MARKETS", "TOGEL_KELUARAN", "TOGEL_PREDICTION", "TOGEL_BET_TYPES"} {
+		if !found[id] {
+			t.Errorf("Expected %s signal, got %v", id, signals)
+		}
+	}
+}
+
+// TestDetectTogelSlotPage tests that a slot page produces no togel signals
+func TestDetectTogelSlotPage(t *testing.T) {
+	td := NewTogelDetector()
+
+	testContent := `
+	<html>
+	<body>
+		<h1>Slot Gacor Hari Ini</h1>
+		<p>Main Gates of Olympus dan Mahjong Ways 2, RTP live 97%.</p>
+		<table>
+			<tr><td>Deposit</td><td>10000</td></tr>
+			<tr><td>Withdraw</td><td>50000</td></tr>
+		</table>
+	</body>
+	</html>
+	`
+
+	if signals := td.DetectTogel(testContent); len(signals) != 0 {
+		t.Errorf("Expected no togel signals on a slot page, got %v", signals)
+	}
+}
+
+// TestDetectTogelNewsPage tests that country names, predictions and 2D/3D
+// outside togel context produce no togel signals
+func TestDetectTogelNewsPage(t *testing.T) {
+	td := NewTogelDetector()
+
+	testContent := `
+	<html>
+	<body>
+		<h1>Prediksi Skor Jepang vs China</h1>
+		<p>Bocoran susunan pemain timnas Japan, China, Taiwan dan Singapore di kualifikasi.</p>
+		<p>Pameran teknologi 2D, 3D dan 4D di Sydney.</p>
+	</body>
+	</html>
+	`
+
+	if signals := td.DetectTogel(testContent); len(signals) != 0 {
+		t.Errorf("Expected no togel signals on a news page, got %v", signals)
+	}
+}
@@ -0,0 +1,141 @@
+package detector
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// Analytics and tracker resource types
+const (
+	ResourceGoogleAnalytics = "google_analytics"
+	ResourceGTM             = "gtm"
+	ResourceFacebookPixel   = "facebook_pixel"
+	ResourceYandexMetrica   = "yandex_metrica"
+	ResourceHistats         = "histats"
+	ResourceStatCounter     = "statcounter"
+	ResourceTikTokPixel     = "tiktok_pixel"
+)
+
+// TrackerDetector extracts analytics and tracker IDs. An operator usually
+// registers a single account per tracker and pastes the same snippet into
+// every mirror, which makes these IDs strong attribution pivots.
+type TrackerDetector struct {
+	Patterns    map[string][]*regexp.Regexp
+	ScriptRegex *regexp.Regexp
+}
+
+// NewTrackerDetector creates a new tracker ID detector
+func NewTrackerDetector() *TrackerDetector {
+	td := &TrackerDetector{
+		Patterns: make(map[string][]*regexp.Regexp),
+	}
+
+	td.compileTrackerPatterns()
+
+	// Tracker snippets live in script and noscript (pixel fallback) elements
+	td.ScriptRegex = regexp.MustCompile(`(?is)<(?:script|noscript)\b[^>]*>.*?</(?:script|noscript)>|<script\b[^>]*>`)
+
+	return td
+}
+
+// compileTrackerPatterns compiles the snippet patterns for each tracker.
+// Every pattern captures the tracker ID in its first group.
+func (td *TrackerDetector) compileTrackerPatterns() {
+	td.Patterns[ResourceGoogleAnalytics] = []*regexp.Regexp{
+		regexp.MustCompile(`\b(UA-[0-9]{4,10}-[0-9]{1,4})\b`),
+		regexp.MustCompile(`(?i)(?:gtag\(\s*['"]config['"]\s*,\s*['"]|gtag/js\?id=)(G-[A-Z0-9]{6,12})`),
+	}
+	td.Patterns[ResourceGTM] = []*regexp.Regexp{
+		regexp.MustCompile(`\b(GTM-[A-Z0-9]{4,9})\b`),
+	}
+	td.Patterns[ResourceFacebookPixel] = []*regexp.Regexp{
+		regexp.MustCompile(`fbq\(\s*['"]init['"]\s*,\s*['"]?([0-9]{10,20})`),
+		regexp.MustCompile(`(?i)facebook\.com/tr/?\?id=([0-9]{10,20})`),
+	}
+	td.Patterns[ResourceYandexMetrica] = []*regexp.Regexp{
+		regexp.MustCompile(`\bym\(\s*([0-9]{5,12})\s*,\s*['"]init['"]`),
+		regexp.MustCompile(`(?i)mc\.yandex\.(?:ru|com)/watch/([0-9]{5,12})`),
+		regexp.MustCompile(`\byaCounter([0-9]{5,12})\b`),
+	}
+	td.Patterns[ResourceHistats] = []*regexp.Regexp{
+		regexp.MustCompile(`Histats\.start['"]?\s*,\s*['"]1,([0-9]{5,9}),`),
+		regexp.MustCompile(`(?i)histats\.com/0\.gif\?([0-9]{5,9})`),
+	}
+	td.Patterns[ResourceStatCounter] = []*regexp.Regexp{
+		regexp.MustCompile(`\bsc_project\s*=\s*([0-9]{5,10})`),
+		regexp.MustCompile(`(?i)c\.statcounter\.com/([0-9]{5,10})/`),
+	}
+	td.Patterns[ResourceTikTokPixel] = []*regexp.Regexp{
+		regexp.MustCompile(`ttq\.load\(\s*['"]([A-Z0-9]{15,25})['"]`),
+	}
+}
+
+// ExtractTrackers returns the tracker IDs found in the page's script and noscript elements
+func (td *TrackerDetector) ExtractTrackers(content string) []models.Resource {
+	scripts := strings.Join(td.ScriptRegex.FindAllString(content, -1), "\n")
+	return extractPatternResources(td.Patterns, scripts, normalizeTrackerID)
+}
+
+// DetectTrackers produces one signal per tracker ID found in content
+func (td *TrackerDetector) DetectTrackers(content string) []models.Signal {
+	var signals []models.Signal
+
+	for _, resource := range td.ExtractTrackers(content) {
+		signal := models.Signal{
+			SignalID:    "tracker_" + resource.Type,
+			Category:    "INFRA",
+			Description: "Found " + trackerLabel(resource.Type) + " ID: " + resource.Value,
+			Confidence:  0.5,
+			Evidence: []models.Evidence{
+				{
+					Type:      "html",
+					Reference: "Found " + trackerLabel(resource.Type) + " ID '" + resource.Value + "' in tracking snippet",
+					Timestamp: time.Now(),
+				},
+			},
+		}
+		signals = append(signals, signal)
+	}
+
+	return signals
+}
+
+// normalizeTrackerID converts a tracker ID into its canonical form
+func normalizeTrackerID(resourceType, raw string) string {
+	return strings.ToUpper(strings.TrimSpace(raw))
+}
+
+// trackerLabel returns a human-readable name for a tracker
+func trackerLabel(resourceType string) string {
+	switch resourceType {
+	case ResourceGoogleAnalytics:
+		return "Google Analytics"
+	case ResourceGTM:
+		return "Google Tag Manager"
+	case ResourceFacebookPixel:
+		return "Facebook Pixel"
+	case ResourceYandexMetrica:
+		return "Yandex Metrica"
+	case ResourceHistats:
+		return "Histats"
+	case ResourceStatCounter:
+		return "StatCounter"
+	case ResourceTikTokPixel:
+		return "TikTok Pixel"
+	default:
+		return resourceType
+	}
+}
+
+// IsTrackerResource reports whether a resource type is an analytics or tracker ID
+func IsTrackerResource(resourceType string) bool {
+	switch resourceType {
+	case ResourceGoogleAnalytics, ResourceGTM, ResourceFacebookPixel, ResourceYandexMetrica,
+		ResourceHistats, ResourceStatCounter, ResourceTikTokPixel:
+		return true
+	}
+	return false
+}
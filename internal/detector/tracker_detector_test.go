@@ -0,0 +1,55 @@
+package detector
+
+import (
+	"testing"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// TestExtractTrackers tests tracker ID extraction from script and noscript elements
+func TestExtractTrackers(t *testing.T) {
+	td := NewTrackerDetector()
+
+	testContent := `
+	<html>
+	<head>
+		<script async src="https://www.googletagmanager.com/gtag/js?id=G-AB12CD34EF"></script>
+		<script>gtag('config', 'G-AB12CD34EF'); ga('create', 'UA-1234567-2', 'auto');</script>
+		<script>(function(w,d,s,l,i){})(window,document,'script','dataLayer','GTM-K9XYZ12');</script>
+		<script>fbq('init', '123456789012345');</script>
+		<noscript><img src="https://www.facebook.com/tr?id=123456789012345&ev=PageView"></noscript>
+		<script>ym(87654321, "init", {});</script>
+		<script>_Hasync.push(['Histats.start', '1,4512345,4,0,0,0,00010000']);</script>
+	</head>
+	<body>
+		<p>Our code UA-9999999-1 appears in text only</p>
+	</body>
+	</html>
+	`
+
+	resources := td.ExtractTrackers(testContent)
+
+	expected := []models.Resource{
+		{Type: ResourceGoogleAnalytics, Value: "G-AB12CD34EF"},
+		{Type: ResourceGoogleAnalytics, Value: "UA-1234567-2"},
+		{Type: ResourceGTM, Value: "GTM-K9XYZ12"},
+		{Type: ResourceFacebookPixel, Value: "123456789012345"},
+		{Type: ResourceYandexMetrica, Value: "87654321"},
+		{Type: ResourceHistats, Value: "4512345"},
+	}
+
+	found := make(map[models.Resource]bool)
+	for _, resource := range resources {
+		found[resource] = true
+	}
+
+	for _, resource := range expected {
+		if !found[resource] {
+			t.Errorf("Expected to extract %s ID %s, got %v", resource.Type, resource.Value, resources)
+		}
+	}
+
+	if found[models.Resource{Type: ResourceGoogleAnalytics, Value: "UA-9999999-1"}] {
+		t.Error("Expected IDs outside script and noscript elements to be ignored")
+	}
+}
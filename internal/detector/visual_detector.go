@@ -0,0 +1,540 @@
+package detector
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"math/bits"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/bmp"
+	_ "golang.org/x/image/webp"
+	"golang.org/x/net/html"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// Visual resource types
+const (
+	ResourceFaviconPHash = "favicon_phash"
+	ResourceFaviconDHash = "favicon_dhash"
+	ResourceFaviconMMH3  = "favicon_mmh3"
+	ResourceImagePHash   = "image_phash"
+	ResourceImageDHash   = "image_dhash"
+)
+
+// maxImageDimension is the largest width or height decoded. Larger images
+// are rejected from their header before any pixel is allocated.
+const maxImageDimension = 4096
+
+// PerceptualHashThreshold is the maximum Hamming distance between two 64-bit
+// perceptual hashes for the images to count as near-duplicates
+const PerceptualHashThreshold = 10
+
+// VisualDetector computes perceptual hashes of the favicon, logos and
+// banners of a page. Mirrors reuse the same artwork even when their markup
+// differs, so near-identical hashes link sites to one operator.
+type VisualDetector struct {
+	ImageKeywords []string
+}
+
+// NewVisualDetector creates a new visual detector
+func NewVisualDetector() *VisualDetector {
+	return &VisualDetector{
+		ImageKeywords: []string{"logo", "banner", "slide", "promo", "header", "brand"},
+	}
+}
+
+// ExtractImageURLs returns the favicon URL and up to maxImages image URLs of
+// a page, resolved against baseURL. Logos and banners are listed first.
+func (vd *VisualDetector) ExtractImageURLs(content, baseURL string, maxImages int) (string, []string) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", nil
+	}
+
+	favicon := ""
+	type candidate struct {
+		url      string
+		priority int
+	}
+	var candidates []candidate
+	seen := make(map[string]bool)
+
+	walkElements(parseHTML(content), func(n *html.Node) bool {
+		switch n.Data {
+		case "link":
+			rel := strings.ToLower(nodeAttr(n, "rel"))
+			if favicon == "" && strings.Contains(rel, "icon") {
+				favicon = resolveImageURL(base, nodeAttr(n, "href"))
+			}
+		case "img":
+			src := nodeAttr(n, "data-src")
+			if src == "" {
+				src = nodeAttr(n, "src")
+			}
+			imageURL := resolveImageURL(base, src)
+			if imageURL == "" || seen[imageURL] || strings.HasSuffix(strings.ToLower(urlPath(imageURL)), ".svg") {
+				return true
+			}
+			seen[imageURL] = true
+
+			priority := 1
+			hints := strings.ToLower(src + " " + nodeAttr(n, "class") + " " + nodeAttr(n, "alt") + " " + nodeAttr(n, "id"))
+			for _, keyword := range vd.ImageKeywords {
+				if strings.Contains(hints, keyword) {
+					priority = 0
+					break
+				}
+			}
+			candidates = append(candidates, candidate{url: imageURL, priority: priority})
+		}
+		return true
+	})
+
+	if favicon == "" {
+		favicon = base.ResolveReference(&url.URL{Path: "/favicon.ico"}).String()
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].priority < candidates[j].priority
+	})
+
+	var images []string
+	for _, c := range candidates {
+		if len(images) >= maxImages {
+			break
+		}
+		images = append(images, c.url)
+	}
+
+	return favicon, images
+}
+
+// FaviconResources returns the perceptual hashes and the Shodan mmh3 hash of a favicon
+func (vd *VisualDetector) FaviconResources(data []byte) []models.Resource {
+	if len(data) == 0 {
+		return nil
+	}
+
+	resources := []models.Resource{
+		{Type: ResourceFaviconMMH3, Value: strconv.Itoa(int(FaviconMMH3(data)))},
+	}
+
+	if img, err := DecodeImage(data); err == nil {
+		resources = append(resources, perceptualHashResources(img, ResourceFaviconPHash, ResourceFaviconDHash)...)
+	}
+
+	return resources
+}
+
+// ImageResources returns the perceptual hashes of an image
+func (vd *VisualDetector) ImageResources(data []byte) ([]models.Resource, error) {
+	img, err := DecodeImage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tiny images are spacers and icons, not artwork
+	bounds := img.Bounds()
+	if bounds.Dx() < 16 || bounds.Dy() < 16 {
+		return nil, nil
+	}
+
+	return perceptualHashResources(img, ResourceImagePHash, ResourceImageDHash), nil
+}
+
+// perceptualHashResources returns the pHash and dHash of an image as
+// resources. Blank and single-colour images, and hashes with almost all bits
+// equal, carry no information and would link unrelated sites.
+func perceptualHashResources(img image.Image, pHashType, dHashType string) []models.Resource {
+	if isFlatImage(img) {
+		return nil
+	}
+
+	var resources []models.Resource
+	if hash := PHash(img); !isLowInformationHash(hash) {
+		resources = append(resources, models.Resource{Type: pHashType, Value: formatHash(hash)})
+	}
+	if hash := DHash(img); !isLowInformationHash(hash) {
+		resources = append(resources, models.Resource{Type: dHashType, Value: formatHash(hash)})
+	}
+	return resources
+}
+
+// isFlatImage reports whether an image is close to a single colour
+func isFlatImage(img image.Image) bool {
+	pixels := grayscaleResize(img, 32, 32)
+
+	minimum, maximum := math.Inf(1), math.Inf(-1)
+	for _, row := range pixels {
+		for _, p := range row {
+			minimum = math.Min(minimum, p)
+			maximum = math.Max(maximum, p)
+		}
+	}
+	return maximum-minimum < 8
+}
+
+// isLowInformationHash reports whether a 64-bit hash has fewer than 4 bits
+// set or unset
+func isLowInformationHash(hash uint64) bool {
+	ones := bits.OnesCount64(hash)
+	return ones < 4 || ones > 60
+}
+
+// IsPerceptualHashResource reports whether a resource type holds a perceptual
+// hash that is compared by Hamming distance rather than equality
+func IsPerceptualHashResource(resourceType string) bool {
+	switch resourceType {
+	case ResourceFaviconPHash, ResourceFaviconDHash, ResourceImagePHash, ResourceImageDHash:
+		return true
+	}
+	return false
+}
+
+// HammingDistance returns the number of differing bits between two hex
+// encoded hashes, or -1 if they cannot be compared
+func HammingDistance(a, b string) int {
+	x, errA := strconv.ParseUint(a, 16, 64)
+	y, errB := strconv.ParseUint(b, 16, 64)
+	if errA != nil || errB != nil || len(a) != len(b) {
+		return -1
+	}
+	return bits.OnesCount64(x ^ y)
+}
+
+// DecodeImage decodes PNG, JPEG, GIF, WebP, BMP and ICO images. Images
+// larger than maxImageDimension in either direction are rejected.
+func DecodeImage(data []byte) (image.Image, error) {
+	if isICO(data) {
+		return decodeICO(data)
+	}
+	if err := checkImageSize(data); err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %v", err)
+	}
+	return img, nil
+}
+
+// checkImageSize reads the dimensions from an image header and rejects
+// images too large to decode safely
+func checkImageSize(data []byte) error {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %v", err)
+	}
+	return checkImageDimensions(config.Width, config.Height)
+}
+
+// checkImageDimensions rejects images larger than maxImageDimension
+func checkImageDimensions(width, height int) error {
+	if width > maxImageDimension || height > maxImageDimension {
+		return fmt.Errorf("image of %dx%d exceeds the %dx%d limit", width, height, maxImageDimension, maxImageDimension)
+	}
+	return nil
+}
+
+// DHash computes the 64-bit difference hash: a 9x8 grayscale thumbnail where
+// each bit records whether a pixel is brighter than its right neighbour
+func DHash(img image.Image) uint64 {
+	pixels := grayscaleResize(img, 9, 8)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if pixels[y][x] > pixels[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// PHash computes the 64-bit perceptual hash: the low 8x8 frequencies of the
+// DCT of a 32x32 grayscale thumbnail, each compared against their median
+func PHash(img image.Image) uint64 {
+	const size = 32
+	pixels := grayscaleResize(img, size, size)
+	coefficients := dct2D(pixels)
+
+	var low []float64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			low = append(low, coefficients[y][x])
+		}
+	}
+
+	// The DC term only reflects overall brightness; leave it out of the median
+	sorted := append([]float64{}, low[1:]...)
+	sort.Float64s(sorted)
+	median := (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+
+	var hash uint64
+	for _, c := range low {
+		hash <<= 1
+		if c > median {
+			hash |= 1
+		}
+	}
+	return hash
+}
+
+// FaviconMMH3 computes the favicon hash used by Shodan (http.favicon.hash):
+// MurmurHash3 of the base64 encoding wrapped at 76 characters with newlines
+func FaviconMMH3(data []byte) int32 {
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	var sb strings.Builder
+	for i := 0; i < len(encoded); i += 76 {
+		end := i + 76
+		if end > len(encoded) {
+			end = len(encoded)
+		}
+		sb.WriteString(encoded[i:end])
+		sb.WriteByte('\n')
+	}
+
+	return int32(murmur3(sb.String(), 0))
+}
+
+// murmur3 computes the 32-bit x86 MurmurHash3 of s
+func murmur3(s string, seed uint32) uint32 {
+	const (
+		c1 = 0xcc9e2d51
+		c2 = 0x1b873593
+	)
+
+	data := []byte(s)
+	h := seed
+	nblocks := len(data) / 4
+	for i := 0; i < nblocks; i++ {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+
+	tail := data[nblocks*4:]
+	var k uint32
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+
+	return h
+}
+
+// grayscaleResize scales img to width x height luminance values by averaging
+// the source pixels that fall into each target cell
+func grayscaleResize(img image.Image, width, height int) [][]float64 {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	pixels := make([][]float64, height)
+	for ty := 0; ty < height; ty++ {
+		pixels[ty] = make([]float64, width)
+		y0 := bounds.Min.Y + ty*srcH/height
+		y1 := bounds.Min.Y + (ty+1)*srcH/height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+
+		for tx := 0; tx < width; tx++ {
+			x0 := bounds.Min.X + tx*srcW/width
+			x1 := bounds.Min.X + (tx+1)*srcW/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			sum, count := 0.0, 0
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					r, g, b, a := img.At(x, y).RGBA()
+					// Transparent areas are treated as white, as browsers usually render them
+					alpha := float64(a) / 0xffff
+					lum := 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+					sum += lum/0xffff*255 + (1-alpha)*255
+					count++
+				}
+			}
+			pixels[ty][tx] = sum / float64(count)
+		}
+	}
+
+	return pixels
+}
+
+// dct2D computes the 2D type-II discrete cosine transform of a square matrix
+func dct2D(pixels [][]float64) [][]float64 {
+	n := len(pixels)
+
+	cosines := make([][]float64, n)
+	for k := 0; k < n; k++ {
+		cosines[k] = make([]float64, n)
+		for i := 0; i < n; i++ {
+			cosines[k][i] = math.Cos(math.Pi / float64(n) * (float64(i) + 0.5) * float64(k))
+		}
+	}
+
+	rows := make([][]float64, n)
+	for y := 0; y < n; y++ {
+		rows[y] = make([]float64, n)
+		for k := 0; k < n; k++ {
+			sum := 0.0
+			for x := 0; x < n; x++ {
+				sum += pixels[y][x] * cosines[k][x]
+			}
+			rows[y][k] = sum
+		}
+	}
+
+	result := make([][]float64, n)
+	for k := 0; k < n; k++ {
+		result[k] = make([]float64, n)
+		for x := 0; x < n; x++ {
+			sum := 0.0
+			for y := 0; y < n; y++ {
+				sum += rows[y][x] * cosines[k][y]
+			}
+			result[k][x] = sum
+		}
+	}
+
+	return result
+}
+
+// isICO reports whether data starts with an ICO file header
+func isICO(data []byte) bool {
+	return len(data) >= 6 && data[0] == 0 && data[1] == 0 && data[2] == 1 && data[3] == 0
+}
+
+// decodeICO decodes the largest image of an ICO file. Entries are either
+// embedded PNG files or BMP bitmaps without a file header.
+func decodeICO(data []byte) (image.Image, error) {
+	count := int(binary.LittleEndian.Uint16(data[4:6]))
+	if count == 0 || len(data) < 6+count*16 {
+		return nil, fmt.Errorf("invalid ICO header")
+	}
+
+	bestSize, bestOffset, bestLength := -1, 0, 0
+	for i := 0; i < count; i++ {
+		entry := data[6+i*16 : 6+(i+1)*16]
+		width := int(entry[0])
+		if width == 0 {
+			width = 256
+		}
+		length := int(binary.LittleEndian.Uint32(entry[8:12]))
+		offset := int(binary.LittleEndian.Uint32(entry[12:16]))
+		if offset <= 0 || length <= 0 || offset+length > len(data) {
+			continue
+		}
+		if width > bestSize {
+			bestSize, bestOffset, bestLength = width, offset, length
+		}
+	}
+	if bestSize < 0 {
+		return nil, fmt.Errorf("no valid image in ICO file")
+	}
+
+	imageData := data[bestOffset : bestOffset+bestLength]
+	if bytes.HasPrefix(imageData, []byte("\x89PNG")) {
+		if err := checkImageSize(imageData); err != nil {
+			return nil, err
+		}
+		img, _, err := image.Decode(bytes.NewReader(imageData))
+		return img, err
+	}
+
+	return decodeICOBitmap(imageData)
+}
+
+// decodeICOBitmap decodes an ICO bitmap entry by prepending the BMP file
+// header it lacks. The stored height covers both the color bitmap and the
+// transparency mask, so it is halved.
+func decodeICOBitmap(dib []byte) (image.Image, error) {
+	if len(dib) < 40 {
+		return nil, fmt.Errorf("invalid ICO bitmap")
+	}
+
+	dib = append([]byte{}, dib...)
+	headerSize := binary.LittleEndian.Uint32(dib[0:4])
+	width := int32(binary.LittleEndian.Uint32(dib[4:8]))
+	height := int32(binary.LittleEndian.Uint32(dib[8:12]))
+	if width < 0 || height < 0 {
+		return nil, fmt.Errorf("invalid ICO bitmap size")
+	}
+	if err := checkImageDimensions(int(width), int(height/2)); err != nil {
+		return nil, err
+	}
+	binary.LittleEndian.PutUint32(dib[8:12], uint32(height/2))
+
+	bitCount := binary.LittleEndian.Uint16(dib[14:16])
+	paletteSize := uint32(0)
+	if bitCount <= 8 {
+		colors := binary.LittleEndian.Uint32(dib[32:36])
+		if colors == 0 {
+			colors = 1 << bitCount
+		}
+		paletteSize = colors * 4
+	}
+
+	fileHeader := make([]byte, 14)
+	copy(fileHeader[0:2], "BM")
+	binary.LittleEndian.PutUint32(fileHeader[2:6], uint32(14+len(dib)))
+	binary.LittleEndian.PutUint32(fileHeader[10:14], 14+headerSize+paletteSize)
+
+	return bmp.Decode(bytes.NewReader(append(fileHeader, dib...)))
+}
+
+// resolveImageURL resolves an image reference against the page URL, skipping inline data
+func resolveImageURL(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(strings.ToLower(ref), "data:") {
+		return ""
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	return base.ResolveReference(refURL).String()
+}
+
+// formatHash encodes a 64-bit hash as 16 hex characters
+func formatHash(hash uint64) string {
+	return fmt.Sprintf("%016x", hash)
+}
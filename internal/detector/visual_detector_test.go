@@ -0,0 +1,146 @@
+package detector
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"golang.org/x/image/bmp"
+)
+
+// TestPerceptualHashes tests that resized copies of an image hash as near-duplicates
+func TestPerceptualHashes(t *testing.T) {
+	original := testBanner(320, 100, 0)
+	resized := testBanner(160, 50, 0)
+	different := testBanner(320, 100, 1)
+
+	for name, hash := range map[string]func(image.Image) uint64{"pHash": PHash, "dHash": DHash} {
+		a, b, c := formatHash(hash(original)), formatHash(hash(resized)), formatHash(hash(different))
+
+		if d := HammingDistance(a, b); d < 0 || d > PerceptualHashThreshold {
+			t.Errorf("Expected %s of a resized copy to be a near-duplicate, got distance %d", name, d)
+		}
+		if d := HammingDistance(a, c); d <= PerceptualHashThreshold {
+			t.Errorf("Expected %s of a different image to differ, got distance %d", name, d)
+		}
+	}
+}
+
+// TestFaviconMMH3 tests the MurmurHash3 implementation and favicon hash encoding
+func TestFaviconMMH3(t *testing.T) {
+	if h := murmur3("", 0); h != 0 {
+		t.Errorf("Expected murmur3 of empty string to be 0, got %d", h)
+	}
+	if h := int32(murmur3("hello", 0)); h != 613153351 {
+		t.Errorf("Expected murmur3(\"hello\") to be 613153351, got %d", h)
+	}
+
+	// Base64 of 60 bytes is 80 characters, wrapped after 76 as Python's encodebytes does
+	data := bytes.Repeat([]byte{0xff}, 60)
+	expected := int32(murmur3("////////////////////////////////////////////////////////////////////////////\n////\n", 0))
+	if h := FaviconMMH3(data); h != expected {
+		t.Errorf("Expected favicon hash %d, got %d", expected, h)
+	}
+}
+
+// TestDecodeICO tests decoding of PNG and bitmap ICO entries
+func TestDecodeICO(t *testing.T) {
+	icon := testBanner(32, 32, 0)
+
+	var pngData bytes.Buffer
+	if err := png.Encode(&pngData, icon); err != nil {
+		t.Fatal(err)
+	}
+
+	var bmpData bytes.Buffer
+	if err := bmp.Encode(&bmpData, icon); err != nil {
+		t.Fatal(err)
+	}
+	dib := append([]byte{}, bmpData.Bytes()[14:]...)
+	binary.LittleEndian.PutUint32(dib[8:12], 64)
+	dib = append(dib, make([]byte, 32*4)...) // transparency mask
+
+	for name, entry := range map[string][]byte{"png": pngData.Bytes(), "bmp": dib} {
+		img, err := DecodeImage(testICO(entry))
+		if err != nil {
+			t.Errorf("Expected %s ICO entry to decode, got error: %v", name, err)
+			continue
+		}
+		if img.Bounds().Dx() != 32 || img.Bounds().Dy() != 32 {
+			t.Errorf("Expected 32x32 %s icon, got %v", name, img.Bounds())
+		}
+		if d := HammingDistance(formatHash(DHash(img)), formatHash(DHash(icon))); d != 0 {
+			t.Errorf("Expected %s icon to hash like the source image, got distance %d", name, d)
+		}
+	}
+}
+
+// testBanner draws a banner with a diagonal gradient and a block whose
+// position depends on variant
+func testBanner(width, height, variant int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := uint8((x*255/width + y*255/height) / 2)
+			if variant == 1 {
+				v = 255 - v
+			}
+			inBlock := x > width/4 && x < width/2 && y > height/4 && y < height*3/4
+			if inBlock {
+				v = 255 - v/4
+			}
+			img.Set(x, y, color.RGBA{R: v, G: v / 2, B: 255 - v, A: 255})
+		}
+	}
+	return img
+}
+
+// testICO wraps a single image entry in an ICO container
+func testICO(entry []byte) []byte {
+	header := []byte{0, 0, 1, 0, 1, 0}
+	dir := make([]byte, 16)
+	dir[0], dir[1] = 32, 32
+	binary.LittleEndian.PutUint32(dir[8:12], uint32(len(entry)))
+	binary.LittleEndian.PutUint32(dir[12:16], 22)
+	return append(append(header, dir...), entry...)
+}
+
+// TestImageResourcesSkipUninformative tests that oversized images are
+// rejected from their header and blank images give no hashes
+func TestImageResourcesSkipUninformative(t *testing.T) {
+	vd := NewVisualDetector()
+
+	var oversized bytes.Buffer
+	if err := png.Encode(&oversized, image.NewGray(image.Rect(0, 0, maxImageDimension+1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vd.ImageResources(oversized.Bytes()); err == nil {
+		t.Error("Expected an error for an image over the size limit")
+	}
+
+	blank := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			blank.Set(x, y, color.RGBA{200, 30, 30, 255})
+		}
+	}
+	var blankData bytes.Buffer
+	if err := png.Encode(&blankData, blank); err != nil {
+		t.Fatal(err)
+	}
+	resources, err := vd.ImageResources(blankData.Bytes())
+	if err != nil || len(resources) != 0 {
+		t.Errorf("Expected no hashes for a single-colour image, got %v (%v)", resources, err)
+	}
+
+	var bannerData bytes.Buffer
+	if err := png.Encode(&bannerData, testBanner(320, 100, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if resources, err := vd.ImageResources(bannerData.Bytes()); err != nil || len(resources) != 2 {
+		t.Errorf("Expected both hashes of a banner, got %v (%v)", resources, err)
+	}
+}
@@ -1,19 +1,212 @@
 package models
 
 import (
+	"strings"
 	"time"
 )
 
 // Domain represents a domain entity with analysis results
 type Domain struct {
-	Domain      string    `json:"domain"`
-	FirstSeen   time.Time `json:"first_seen"`
-	LastSeen    time.Time `json:"last_seen"`
-	CDNProvider string    `json:"cdn_provider"`
-	JLIScore    float64   `json:"jli_score"`
-	JLILevel    string    `json:"jli_level"`
-	ClusterID   *string   `json:"cluster_id"`
-	Signals     []Signal  `json:"signals"`
+	Domain         string          `json:"domain"`
+	FirstSeen      time.Time       `json:"first_seen"`
+	LastSeen       time.Time       `json:"last_seen"`
+	CDNProvider    string          `json:"cdn_provider"`
+	CDNMatches     []CDNMatch      `json:"cdn_matches,omitempty"`
+	CNAMEChains    []CNAMEChain    `json:"cname_chains,omitempty"`
+	JLIScore       float64         `json:"jli_score"`
+	JLILevel       string          `json:"jli_level"`
+	ClusterID      *string         `json:"cluster_id"`
+	Signals        []Signal        `json:"signals"`
+	Resources      []Resource      `json:"resources,omitempty"`
+	GameProviders  []string        `json:"game_providers,omitempty"`
+	Platform       string          `json:"platform,omitempty"`
+	Relations      []Relation      `json:"relations,omitempty"`
+	RedirectChains []RedirectChain `json:"redirect_chains,omitempty"`
+	Signature      *PageSignature  `json:"signature,omitempty"`
+	Vertical       string          `json:"vertical,omitempty"`
+	Verticals      []string        `json:"verticals,omitempty"`
+	InjectedURLs   []string        `json:"injected_urls,omitempty"`
+	DNSRecords     []DNSRecord     `json:"dns_records,omitempty"`
+	BlockStatus    *BlockStatus    `json:"block_status,omitempty"`
+	Registration   *Registration   `json:"registration,omitempty"`
+	Subdomains     []Subdomain     `json:"subdomains,omitempty"`
+}
+
+// Registration holds the registry data of a domain from RDAP or WHOIS
+type Registration struct {
+	Domain      string     `json:"domain"`
+	Registrar   string     `json:"registrar,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	Status      []string   `json:"status,omitempty"`
+	Nameservers []string   `json:"nameservers,omitempty"`
+	// PrivacyService names the proxy service hiding the registrant, if any
+	PrivacyService string `json:"privacy_service,omitempty"`
+	// Source is "rdap" or "whois"; Server is the service that answered
+	Source    string    `json:"source"`
+	Server    string    `json:"server"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Age returns how long ago the domain was registered, or 0 if unknown
+func (r *Registration) Age(now time.Time) time.Duration {
+	if r == nil || r.CreatedAt == nil {
+		return 0
+	}
+	return now.Sub(*r.CreatedAt)
+}
+
+// Block statuses of a domain at Indonesian ISPs
+const (
+	BlockStatusBlocked    = "blocked"
+	BlockStatusNotBlocked = "not_blocked"
+	BlockStatusUnknown    = "unknown"
+)
+
+// Ways an ISP blocks a domain
+const (
+	BlockMethodSinkhole  = "dns_sinkhole"
+	BlockMethodBlockPage = "block_page"
+	BlockMethodNXDomain  = "dns_nxdomain"
+)
+
+// BlockStatus records whether ISPs block a domain (Internet Positif /
+// TrustPositif) and whether the site stays reachable around the block
+type BlockStatus struct {
+	Status          string    `json:"status"`
+	Method          string    `json:"method,omitempty"`
+	Evidence        string    `json:"evidence,omitempty"`
+	ISPResolver     string    `json:"isp_resolver"`
+	NeutralResolver string    `json:"neutral_resolver"`
+	ISPAnswers      []string  `json:"isp_answers,omitempty"`
+	NeutralAnswers  []string  `json:"neutral_answers,omitempty"`
+	CheckedAt       time.Time `json:"checked_at"`
+	// BlockedSince is when the domain was first seen blocked
+	BlockedSince *time.Time `json:"blocked_since,omitempty"`
+	// Reachable reports whether the site answered at its real address
+	Reachable       bool       `json:"reachable"`
+	LastReachableAt *time.Time `json:"last_reachable_at,omitempty"`
+}
+
+// CarryHistory keeps the block and reachability times of an earlier check of
+// the same domain
+func (b *BlockStatus) CarryHistory(previous *BlockStatus) {
+	if previous == nil {
+		return
+	}
+	if b.Status == BlockStatusBlocked && previous.Status == BlockStatusBlocked && previous.BlockedSince != nil {
+		b.BlockedSince = previous.BlockedSince
+	}
+	if !b.Reachable && previous.LastReachableAt != nil {
+		b.LastReachableAt = previous.LastReachableAt
+	}
+}
+
+// ReachableAfterBlock returns how long the site stayed reachable after it
+// was first seen blocked
+func (b *BlockStatus) ReachableAfterBlock() time.Duration {
+	if b.BlockedSince == nil || b.LastReachableAt == nil || b.LastReachableAt.Before(*b.BlockedSince) {
+		return 0
+	}
+	return b.LastReachableAt.Sub(*b.BlockedSince)
+}
+
+// DNSRecord is one DNS record collected for a domain
+type DNSRecord struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	TTL   uint32 `json:"ttl"`
+	Value string `json:"value"`
+	// Provider is the CDN or cloud whose published ranges hold an A or
+	// AAAA address; CDN is set when it is a CDN edge address
+	Provider string `json:"provider,omitempty"`
+	CDN      bool   `json:"cdn,omitempty"`
+}
+
+// CDN match kinds: what a CDN fingerprint was matched against
+const (
+	CDNMatchHeader    = "header"
+	CDNMatchCookie    = "cookie"
+	CDNMatchCNAME     = "cname"
+	CDNMatchIPRange   = "ip_range"
+	CDNMatchTLSIssuer = "tls_issuer"
+	CDNMatchErrorPage = "error_page"
+)
+
+// CDNMatch is one CDN fingerprint found for a domain, with the confidence
+// it gives that the provider serves the domain
+type CDNMatch struct {
+	Provider   string  `json:"provider"`
+	Kind       string  `json:"kind"`
+	Reference  string  `json:"reference"`
+	Confidence float64 `json:"confidence"`
+}
+
+// CNAMEChain is the chain of CNAME targets a name resolves through, in order
+type CNAMEChain struct {
+	Name    string   `json:"name"`
+	Targets []string `json:"targets"`
+}
+
+// String formats the chain as "name -> target -> ..."
+func (c CNAMEChain) String() string {
+	return strings.Join(append([]string{c.Name}, c.Targets...), " -> ")
+}
+
+// Subdomain sources
+const (
+	SubdomainSourceWordlist = "wordlist"
+	SubdomainSourceCT       = "ct"
+	SubdomainSourcePage     = "page"
+)
+
+// Subdomain is a resolving subdomain of a scanned domain
+type Subdomain struct {
+	Name string `json:"name"`
+	// Sources lists where the name was found: the wordlist, certificate
+	// transparency logs or links on the page
+	Sources   []string `json:"sources"`
+	Addresses []string `json:"addresses"`
+	// CDN is the CDN serving the subdomain, by the published ranges of its
+	// addresses or its HTTP responses; "none" when it is served directly
+	CDN string `json:"cdn"`
+}
+
+// PageSignature holds locality-sensitive signatures of a page, used to find
+// near-duplicate pages across domains
+type PageSignature struct {
+	TextSimHash string   `json:"text_simhash,omitempty"`
+	DOMSimHash  string   `json:"dom_simhash,omitempty"`
+	MinHash     []uint32 `json:"minhash"`
+}
+
+// RedirectChain records how a shortlink resolved to its destination
+type RedirectChain struct {
+	URL         string   `json:"url"`
+	Hops        []string `json:"hops"`
+	Destination string   `json:"destination"`
+}
+
+// Relation types
+const (
+	RelationMirrorOf  = "MIRROR_OF"
+	RelationSiblingOf = "SIBLING_OF"
+)
+
+// Relation represents a directed edge between two domains
+type Relation struct {
+	Source   string     `json:"source"`
+	Target   string     `json:"target"`
+	Type     string     `json:"type"`
+	Evidence []Evidence `json:"evidence"`
+}
+
+// Resource represents a typed identifier that can be shared across domains
+// run by the same operator (contact handles, tracker IDs, wallets, etc.)
+type Resource struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
 }
 
 // Signal represents an atomic signal found during analysis
@@ -23,12 +216,45 @@ type Signal struct {
 	Description string     `json:"description"`
 	Confidence  float64    `json:"confidence"`
 	Evidence    []Evidence `json:"evidence"`
+	Vertical    string     `json:"vertical,omitempty"`
+	Threat      string     `json:"threat,omitempty"`
+}
+
+// SignalCategories are the signal categories the scoring engine knows
+var SignalCategories = map[string]bool{
+	"UX": true, "PAYMENT": true, "INFRA": true, "DNS": true, "CDN": true,
 }
 
+// Gambling verticals (product lines) a signal or domain can belong to
+const (
+	VerticalSlot       = "slot"
+	VerticalTogel      = "togel"
+	VerticalCasino     = "casino"
+	VerticalSportsbook = "sportsbook"
+)
+
+// Threat types a site can be classified as. Signals without a threat are
+// gambling signals or shared infrastructure signals.
+const (
+	ThreatJudol      = "judol"
+	ThreatPinjol     = "pinjol"
+	ThreatInvestment = "investment_scam"
+)
+
+// Host classifications of a site carrying gambling content: an operator runs
+// the gambling site, a compromised host is a legitimate site with injected pages
+const (
+	HostOperator    = "OPERATOR"
+	HostCompromised = "COMPROMISED_HOST"
+)
+
 // Evidence represents human-auditable evidence for a signal
 type Evidence struct {
-	Type      string    `json:"type"`
-	Reference string    `json:"reference"`
+	Type      string `json:"type"`
+	Reference string `json:"reference"`
+	// Source names what produced the evidence when it matters, such as the
+	// DNS resolver that answered
+	Source    string    `json:"source,omitempty"`
 	Timestamp time.Time `json:"timestamp"`
 }
 
@@ -52,6 +278,20 @@ type AnalysisResult struct {
 	JLILevel      string            `json:"jli_level"`
 	CategoryBreakdown map[string]CategoryBreakdown `json:"category_breakdown"`
 	ProfileUsed   string            `json:"profile_used"`
+	Modules       []ModuleRun       `json:"modules,omitempty"`
+	FLIScore      float64           `json:"fli_score"`
+	FLILevel      string            `json:"fli_level"`
+	ThreatType    string            `json:"threat_type,omitempty"`
+	HostClassification string       `json:"host_classification,omitempty"`
+}
+
+// ModuleRun records how a detection module fared on one scan
+type ModuleRun struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration_ns"`
+	Signals  int           `json:"signals"`
+	Skipped  bool          `json:"skipped,omitempty"`
+	Error    string        `json:"error,omitempty"`
 }
 
 // CategoryBreakdown holds the breakdown of scores by category
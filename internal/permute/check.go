@@ -0,0 +1,114 @@
+package permute
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/genesis410/fogger/internal/resolver"
+)
+
+// Hit is a candidate that resolves
+type Hit struct {
+	Candidate
+	Addresses []string `json:"addresses"`
+}
+
+// Checker resolves candidates concurrently
+type Checker struct {
+	Resolver    resolver.Resolver
+	Concurrency int
+}
+
+// NewChecker creates a checker running up to concurrency lookups at once
+func NewChecker(r resolver.Resolver, concurrency int) *Checker {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &Checker{Resolver: r, Concurrency: concurrency}
+}
+
+// Resolve returns the candidates that resolve, in candidate order. Answers
+// a public suffix gives for any name (wildcard TLDs, resolvers rewriting
+// NXDOMAIN) are not hits.
+func (c *Checker) Resolve(ctx context.Context, candidates []Candidate) []Hit {
+	wildcards := c.wildcards(ctx, candidates)
+
+	hits := make([]*Hit, len(candidates))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < c.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				candidate := candidates[i]
+				addresses, err := resolver.LookupIPv4(ctx, c.Resolver, candidate.Domain)
+				if err != nil || len(addresses) == 0 {
+					continue
+				}
+				suffix, _ := publicsuffix.PublicSuffix(candidate.Domain)
+				if allIn(addresses, wildcards[suffix]) {
+					continue
+				}
+				hits[i] = &Hit{Candidate: candidate, Addresses: addresses}
+			}
+		}()
+	}
+	for i := range candidates {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var live []Hit
+	for _, hit := range hits {
+		if hit != nil {
+			live = append(live, *hit)
+		}
+	}
+	return live
+}
+
+// wildcards returns the addresses each public suffix of the candidates
+// answers for a random name
+func (c *Checker) wildcards(ctx context.Context, candidates []Candidate) map[string]map[string]bool {
+	wildcards := make(map[string]map[string]bool)
+	for _, candidate := range candidates {
+		suffix, _ := publicsuffix.PublicSuffix(candidate.Domain)
+		if _, ok := wildcards[suffix]; ok {
+			continue
+		}
+		addresses := make(map[string]bool)
+		if answers, err := resolver.LookupIPv4(ctx, c.Resolver, randomLabel()+"."+suffix); err == nil {
+			for _, address := range answers {
+				addresses[address] = true
+			}
+		}
+		wildcards[suffix] = addresses
+	}
+	return wildcards
+}
+
+// allIn reports whether every address is in set
+func allIn(addresses []string, set map[string]bool) bool {
+	if len(set) == 0 {
+		return false
+	}
+	for _, address := range addresses {
+		if !set[address] {
+			return false
+		}
+	}
+	return true
+}
+
+// randomLabel returns a label no one has registered
+func randomLabel() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "fogger-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,255 @@
+// Package permute generates candidate mirror domains from the naming pattern
+// of a seed domain (slot88a.com -> slot88b.com, slot89a.com, slot88a.xyz,
+// ...) and checks which of them resolve, to find the next mirrors of a
+// rotation set before they are reported.
+package permute
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/genesis410/fogger/internal/detector"
+)
+
+// Candidate kinds: how a candidate was derived from the seed
+const (
+	KindNumber = "number"
+	KindSuffix = "suffix"
+	KindTLD    = "tld"
+	KindHyphen = "hyphen"
+	KindTypo   = "typo"
+)
+
+// homoglyphs are the characters operators and typosquatters swap for
+// look-alikes
+var homoglyphs = map[byte][]byte{
+	'o': {'0'}, '0': {'o'},
+	'i': {'1', 'l'}, 'l': {'1', 'i'}, '1': {'l', 'i'},
+	'e': {'3'}, '3': {'e'},
+	'a': {'4'}, '4': {'a'},
+	's': {'5'}, '5': {'s'},
+	'g': {'9'}, '9': {'g'},
+}
+
+// Candidate is a generated domain
+type Candidate struct {
+	Domain string `json:"domain"`
+	Kind   string `json:"kind"`
+}
+
+// variant is a label derived from the seed label
+type variant struct {
+	label string
+	kind  string
+}
+
+// Generator derives candidate domains from a seed
+type Generator struct {
+	// TLDs are tried for the seed label and its rotation variants
+	TLDs []string
+	// NumberRange is how far numbers and numeric counters are stepped up and down
+	NumberRange int
+	// SuffixLetters are the letter counters tried after the number
+	SuffixLetters string
+	// MaxCandidates caps the number of candidates; 0 means no cap
+	MaxCandidates int
+}
+
+// NewGenerator creates a generator trying the given TLDs
+func NewGenerator(tlds []string, maxCandidates int) *Generator {
+	return &Generator{
+		TLDs:          tlds,
+		NumberRange:   5,
+		SuffixLetters: "abcdefghij",
+		MaxCandidates: maxCandidates,
+	}
+}
+
+// Generate returns the candidates for a seed, most likely first: rotation
+// variants under the seed's TLD, the seed label under other TLDs,
+// hyphenations, typos, then rotation variants under other TLDs
+func (g *Generator) Generate(seed string) ([]Candidate, error) {
+	name, err := detector.ParseDomainName(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{name.Domain: true}
+	var candidates []Candidate
+	add := func(label, tld, kind string) {
+		if g.MaxCandidates > 0 && len(candidates) >= g.MaxCandidates {
+			return
+		}
+		domain := label + "." + tld
+		if !validLabel(label) || seen[domain] {
+			return
+		}
+		seen[domain] = true
+		candidates = append(candidates, Candidate{Domain: domain, Kind: kind})
+	}
+
+	variants := g.rotationVariants(name)
+	for _, v := range variants {
+		add(v.label, name.TLD, v.kind)
+	}
+	for _, tld := range g.TLDs {
+		add(name.Label, strings.TrimPrefix(strings.ToLower(tld), "."), KindTLD)
+	}
+	for _, label := range hyphenations(name.Label) {
+		add(label, name.TLD, KindHyphen)
+	}
+	for _, label := range typos(name.Label) {
+		add(label, name.TLD, KindTypo)
+	}
+	for _, tld := range g.TLDs {
+		for _, v := range variants {
+			add(v.label, strings.TrimPrefix(strings.ToLower(tld), "."), v.kind)
+		}
+	}
+
+	return candidates, nil
+}
+
+// rotationVariants returns the labels of a rotation set around the seed:
+// stepped numbers and other counters after the number
+func (g *Generator) rotationVariants(name detector.DomainName) []variant {
+	if name.Number == "" {
+		return nil
+	}
+	label := name.Label
+	start := strings.Index(label, name.Number)
+	head, tail := label[:start], label[start+len(name.Number):]
+	// The separator between the number and the counter ("-" in gacor77-1)
+	separator := strings.TrimSuffix(tail, name.Suffix)
+
+	var variants []variant
+	for _, number := range steps(name.Number, g.NumberRange, 0) {
+		variants = append(variants, variant{label: head + number + tail, kind: KindNumber})
+	}
+
+	switch {
+	case name.Suffix == "":
+		for _, letter := range g.SuffixLetters {
+			variants = append(variants, variant{label: head + name.Number + string(letter), kind: KindSuffix})
+		}
+		for i := 1; i <= 3; i++ {
+			variants = append(variants, variant{label: head + name.Number + "-" + strconv.Itoa(i), kind: KindSuffix})
+		}
+	case isDigits(name.Suffix):
+		for _, counter := range steps(name.Suffix, g.NumberRange, 1) {
+			variants = append(variants, variant{label: head + name.Number + separator + counter, kind: KindSuffix})
+		}
+	case len(name.Suffix) == 1:
+		for _, letter := range g.SuffixLetters {
+			if string(letter) != name.Suffix {
+				variants = append(variants, variant{label: head + name.Number + separator + string(letter), kind: KindSuffix})
+			}
+		}
+		// The unsuffixed label is often the first domain of the set
+		variants = append(variants, variant{label: head + name.Number, kind: KindSuffix})
+	}
+	return variants
+}
+
+// steps returns the numbers up to n above and below number, keeping its
+// zero padding and not going below min
+func steps(number string, n, min int) []string {
+	value, err := strconv.Atoi(number)
+	if err != nil {
+		return nil
+	}
+	var numbers []string
+	for d := 1; d <= n; d++ {
+		for _, v := range []int{value + d, value - d} {
+			if v < min {
+				continue
+			}
+			s := strconv.Itoa(v)
+			if len(s) < len(number) {
+				s = strings.Repeat("0", len(number)-len(s)) + s
+			}
+			numbers = append(numbers, s)
+		}
+	}
+	return numbers
+}
+
+// hyphenations returns the label with hyphens added between its letter and
+// digit runs, and with its hyphens removed
+func hyphenations(label string) []string {
+	if strings.Contains(label, "-") {
+		return []string{strings.ReplaceAll(label, "-", "")}
+	}
+
+	var boundaries []int
+	for i := 1; i < len(label); i++ {
+		if isDigit(label[i-1]) != isDigit(label[i]) {
+			boundaries = append(boundaries, i)
+		}
+	}
+
+	var labels []string
+	for _, i := range boundaries {
+		labels = append(labels, label[:i]+"-"+label[i:])
+	}
+	if len(boundaries) > 1 {
+		hyphenated := label
+		for j := len(boundaries) - 1; j >= 0; j-- {
+			hyphenated = hyphenated[:boundaries[j]] + "-" + hyphenated[boundaries[j]:]
+		}
+		labels = append(labels, hyphenated)
+	}
+	return labels
+}
+
+// typos returns dnstwist-style typos of a label: omission, repetition,
+// transposition and homoglyph replacement
+func typos(label string) []string {
+	var labels []string
+	for i := 0; i < len(label); i++ {
+		labels = append(labels, label[:i]+label[i+1:])
+	}
+	for i := 0; i < len(label); i++ {
+		if label[i] != '-' {
+			labels = append(labels, label[:i+1]+label[i:])
+		}
+	}
+	for i := 0; i+1 < len(label); i++ {
+		if label[i] != label[i+1] {
+			labels = append(labels, label[:i]+string(label[i+1])+string(label[i])+label[i+2:])
+		}
+	}
+	for i := 0; i < len(label); i++ {
+		for _, glyph := range homoglyphs[label[i]] {
+			labels = append(labels, label[:i]+string(glyph)+label[i+1:])
+		}
+	}
+	return labels
+}
+
+// validLabel reports whether label is a valid DNS label
+func validLabel(label string) bool {
+	if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	for i := 0; i < len(label); i++ {
+		c := label[i]
+		if !(c >= 'a' && c <= 'z') && !isDigit(c) && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,97 @@
+package permute
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/resolver"
+	"github.com/genesis410/fogger/internal/resolver/resolvertest"
+)
+
+// TestGenerate tests the candidate kinds generated from a rotation name
+func TestGenerate(t *testing.T) {
+	candidates, err := NewGenerator([]string{"xyz", ".VIP"}, 0).Generate("www.slot88a.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	kinds := make(map[string]string)
+	for _, candidate := range candidates {
+		if _, dup := kinds[candidate.Domain]; dup {
+			t.Errorf("Duplicate candidate %s", candidate.Domain)
+		}
+		kinds[candidate.Domain] = candidate.Kind
+	}
+
+	expected := map[string]string{
+		"slot89a.com":  KindNumber,
+		"slot83a.com":  KindNumber,
+		"slot88b.com":  KindSuffix,
+		"slot88.com":   KindSuffix,
+		"slot88a.xyz":  KindTLD,
+		"slot88a.vip":  KindTLD,
+		"slot-88a.com": KindHyphen,
+		"slot88-a.com": KindHyphen,
+		"s1ot88a.com":  KindTypo,
+		"slto88a.com":  KindTypo,
+		"slot88b.xyz":  KindSuffix,
+	}
+	for domain, kind := range expected {
+		if kinds[domain] != kind {
+			t.Errorf("Expected %s as a %s candidate, got %q", domain, kind, kinds[domain])
+		}
+	}
+	if _, ok := kinds["slot88a.com"]; ok {
+		t.Error("Expected the seed itself not to be a candidate")
+	}
+	if candidates[0].Kind != KindNumber {
+		t.Errorf("Expected rotation variants under the seed's TLD first, got %v", candidates[0])
+	}
+
+	counters, _ := NewGenerator(nil, 3).Generate("gacor77-1.xyz")
+	if len(counters) != 3 || counters[0].Domain != "gacor78-1.xyz" {
+		t.Errorf("Expected 3 candidates starting with gacor78-1.xyz, got %v", counters)
+	}
+	suffixes, _ := NewGenerator(nil, 0).Generate("gacor77-1.xyz")
+	found := false
+	for _, candidate := range suffixes {
+		found = found || (candidate.Domain == "gacor77-2.xyz" && candidate.Kind == KindSuffix)
+	}
+	if !found {
+		t.Error("Expected gacor77-2.xyz as a suffix candidate")
+	}
+}
+
+// TestResolveFiltersWildcards tests that candidates under a wildcard TLD
+// answering the wildcard address are not hits
+func TestResolveFiltersWildcards(t *testing.T) {
+	server, err := resolvertest.NewServer([]models.DNSRecord{
+		{Name: "slot89a.com", Type: "A", TTL: 60, Value: "203.0.113.10"},
+		{Name: "*.xyz", Type: "A", TTL: 60, Value: "198.51.100.1"},
+		{Name: "slot88b.xyz", Type: "A", TTL: 60, Value: "203.0.113.11"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start DNS server: %v", err)
+	}
+	defer server.Close()
+
+	r, err := resolver.New(server.Addr, time.Second)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	hits := NewChecker(r, 4).Resolve(context.Background(), []Candidate{
+		{Domain: "slot89a.com", Kind: KindNumber},
+		{Domain: "slot90a.com", Kind: KindNumber},
+		{Domain: "slot88a.xyz", Kind: KindTLD},
+		{Domain: "slot88b.xyz", Kind: KindSuffix},
+	})
+	if len(hits) != 2 || hits[0].Domain != "slot89a.com" || hits[1].Domain != "slot88b.xyz" {
+		t.Fatalf("Expected slot89a.com and slot88b.xyz as hits, got %v", hits)
+	}
+	if hits[0].Addresses[0] != "203.0.113.10" {
+		t.Errorf("Expected the address of slot89a.com, got %v", hits[0].Addresses)
+	}
+}
@@ -0,0 +1,237 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// DefaultTimeout is used for plugins without a configured timeout
+const DefaultTimeout = 10 * time.Second
+
+// maxOutput bounds how much of a plugin's stdout and stderr is read
+const maxOutput = 4 << 20
+
+// Plugin is an external detector executable speaking the plugin protocol.
+// It implements detector.Detector, so it runs like any built-in module.
+type Plugin struct {
+	name     string
+	command  string
+	args     []string
+	category string
+	timeout  time.Duration
+}
+
+// New creates a plugin from its configuration
+func New(cfg config.PluginConfig) (*Plugin, error) {
+	if cfg.Name == "" {
+		return nil, errors.New("plugin has no name")
+	}
+	if cfg.Command == "" {
+		return nil, fmt.Errorf("plugin %s has no command", cfg.Name)
+	}
+
+	p := &Plugin{
+		name:     cfg.Name,
+		command:  cfg.Command,
+		args:     cfg.Args,
+		category: strings.ToUpper(cfg.Category),
+		timeout:  time.Duration(cfg.Timeout) * time.Second,
+	}
+	if p.category == "" {
+		p.category = "UX"
+	}
+	if !models.SignalCategories[p.category] {
+		return nil, fmt.Errorf("plugin %s has unknown category %s", cfg.Name, cfg.Category)
+	}
+	if p.timeout <= 0 {
+		p.timeout = DefaultTimeout
+	}
+	return p, nil
+}
+
+// RegisterConfigured registers every configured plugin as a detection module.
+// Plugins that fail to load, or whose name is taken by a built-in module or
+// an earlier plugin, are skipped and reported in the returned error.
+func RegisterConfigured(plugins []config.PluginConfig) error {
+	return registerConfigured(detector.DefaultRegistry, plugins)
+}
+
+// registerConfigured registers the configured plugins in r
+func registerConfigured(r *detector.Registry, plugins []config.PluginConfig) error {
+	taken := make(map[string]bool)
+	for _, name := range r.Names() {
+		taken[name] = true
+	}
+
+	var errs []string
+	for _, cfg := range plugins {
+		p, err := New(cfg)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if taken[cfg.Name] || taken[p.Name()] {
+			errs = append(errs, fmt.Sprintf("plugin %s: name is already used by a module", cfg.Name))
+			continue
+		}
+		taken[p.Name()] = true
+		r.Register(p)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to load plugins: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Name returns the module name, prefixed to keep plugins apart from built-in modules
+func (p *Plugin) Name() string { return "plugin:" + p.name }
+
+// Category returns the default category of the plugin's signals
+func (p *Plugin) Category() string { return p.category }
+
+// Inputs returns the page parts the plugin needs
+func (p *Plugin) Inputs() []string { return []string{detector.InputBody} }
+
+// Describe asks the plugin for its name, version and description
+func (p *Plugin) Describe(ctx context.Context) (*Response, error) {
+	return p.call(ctx, &Request{ProtocolVersion: ProtocolVersion, Type: RequestDescribe})
+}
+
+// Analyze sends the page to the plugin and returns the signals it reports.
+// Signals without an ID or evidence are dropped and reported in the error.
+func (p *Plugin) Analyze(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+	response, err := p.call(ctx, &Request{ProtocolVersion: ProtocolVersion, Type: RequestAnalyze, Page: NewPage(page)})
+	if err != nil {
+		return nil, err
+	}
+
+	var signals []models.Signal
+	var rejected []string
+	for i, signal := range response.Signals {
+		if signal.SignalID == "" {
+			rejected = append(rejected, fmt.Sprintf("signal %d has no signal_id", i))
+			continue
+		}
+		if len(signal.Evidence) == 0 {
+			rejected = append(rejected, signal.SignalID+" has no evidence")
+			continue
+		}
+		if signal.Category == "" {
+			signal.Category = p.category
+		}
+		if !models.SignalCategories[signal.Category] {
+			rejected = append(rejected, signal.SignalID+" has unknown category "+signal.Category)
+			continue
+		}
+		signal.Confidence = clamp(signal.Confidence)
+		for j := range signal.Evidence {
+			if signal.Evidence[j].Type == "" {
+				signal.Evidence[j].Type = "plugin"
+			}
+			if signal.Evidence[j].Timestamp.IsZero() {
+				signal.Evidence[j].Timestamp = time.Now()
+			}
+		}
+		signals = append(signals, signal)
+	}
+
+	if len(rejected) > 0 {
+		return signals, fmt.Errorf("plugin %s returned invalid signals: %s", p.name, strings.Join(rejected, "; "))
+	}
+	return signals, nil
+}
+
+// call runs the plugin executable with one request and decodes its response.
+// The process is killed when the timeout expires.
+func (p *Plugin) call(ctx context.Context, request *Request) (*Response, error) {
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin request: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.command, p.args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &limitedWriter{w: &stdout, n: maxOutput}
+	cmd.Stderr = &limitedWriter{w: &stderr, n: maxOutput}
+	// Don't wait on children of a killed plugin that still hold its stdout
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("plugin %s timed out after %s", p.name, p.timeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s failed: %v%s", p.name, err, stderrSuffix(stderr.String()))
+	}
+
+	response := &Response{}
+	if err := json.Unmarshal(stdout.Bytes(), response); err != nil {
+		return nil, fmt.Errorf("plugin %s returned invalid JSON: %v%s", p.name, err, stderrSuffix(stderr.String()))
+	}
+	if response.ProtocolVersion != ProtocolVersion {
+		return nil, fmt.Errorf("plugin %s speaks protocol version %d, expected %d", p.name, response.ProtocolVersion, ProtocolVersion)
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("plugin %s: %s", p.name, response.Error)
+	}
+
+	return response, nil
+}
+
+// limitedWriter discards everything written past n bytes
+type limitedWriter struct {
+	w io.Writer
+	n int
+}
+
+func (l *limitedWriter) Write(data []byte) (int, error) {
+	size := len(data)
+	if l.n <= 0 {
+		return size, nil
+	}
+	if len(data) > l.n {
+		data = data[:l.n]
+	}
+	written, err := l.w.Write(data)
+	l.n -= written
+	if err != nil {
+		return written, err
+	}
+	return size, nil
+}
+
+// stderrSuffix formats the last line of a plugin's stderr for an error message
+func stderrSuffix(stderr string) string {
+	stderr = strings.TrimSpace(stderr)
+	if stderr == "" {
+		return ""
+	}
+	lines := strings.Split(stderr, "\n")
+	return " (stderr: " + lines[len(lines)-1] + ")"
+}
+
+// clamp limits a confidence to [0, 1]
+func clamp(confidence float64) float64 {
+	if confidence < 0 {
+		return 0
+	}
+	if confidence > 1 {
+		return 1
+	}
+	return confidence
+}
@@ -0,0 +1,160 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// TestHelperPlugin is not a real test: the tests below run the test binary
+// as the plugin executable, acting out the mode passed as the last argument
+func TestHelperPlugin(t *testing.T) {
+	if os.Getenv("FOGGER_PLUGIN_HELPER") != "1" {
+		return
+	}
+	mode := os.Args[len(os.Args)-1]
+
+	var request Request
+	if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+		fmt.Fprintln(os.Stderr, "bad request")
+		os.Exit(2)
+	}
+
+	switch mode {
+	case "ok":
+		if request.Type == RequestDescribe {
+			fmt.Print(`{"protocol_version": 1, "name": "helper", "version": "1.2.3"}`)
+			break
+		}
+		fmt.Printf(`{"protocol_version": 1, "signals": [
+			{"signal_id": "HELPER_SIGNAL", "description": "saw %s", "confidence": 1.7,
+			 "evidence": [{"reference": "body has %d bytes"}]},
+			{"signal_id": "NO_EVIDENCE", "confidence": 0.5}
+		]}`, request.Page.Domain, len(request.Page.Body))
+	case "crash":
+		fmt.Fprintln(os.Stderr, "panic: classifier exploded")
+		os.Exit(3)
+	case "sleep":
+		time.Sleep(10 * time.Second)
+	case "badjson":
+		fmt.Print("not json")
+	case "version":
+		fmt.Print(`{"protocol_version": 99}`)
+	}
+	os.Exit(0)
+}
+
+// helperPlugin returns a plugin that runs the test binary in the given
+// mode; a timeout of 0 uses DefaultTimeout
+func helperPlugin(t *testing.T, name, mode string, timeout int) *Plugin {
+	t.Setenv("FOGGER_PLUGIN_HELPER", "1")
+	p, err := New(config.PluginConfig{
+		Name:    name,
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^TestHelperPlugin$", "--", mode},
+		Timeout: timeout,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating plugin: %v", err)
+	}
+	return p
+}
+
+// TestPluginAnalyze tests the analyze exchange and validation of returned signals
+func TestPluginAnalyze(t *testing.T) {
+	p := helperPlugin(t, "helper", "ok", 0)
+
+	description, err := p.Describe(context.Background())
+	if err != nil || description.Version != "1.2.3" {
+		t.Fatalf("Expected describe to return version 1.2.3, got %+v, %v", description, err)
+	}
+
+	signals, err := p.Analyze(context.Background(), &detector.Page{Domain: "gacor88.com", Body: "<html></html>"})
+	if err == nil || !strings.Contains(err.Error(), "NO_EVIDENCE has no evidence") {
+		t.Errorf("Expected the signal without evidence to be rejected, got %v", err)
+	}
+	if len(signals) != 1 {
+		t.Fatalf("Expected one valid signal, got %v", signals)
+	}
+
+	signal := signals[0]
+	if signal.Description != "saw gacor88.com" || signal.Category != "UX" || signal.Confidence != 1 {
+		t.Errorf("Expected the page domain, default category and clamped confidence, got %+v", signal)
+	}
+	if signal.Evidence[0].Type != "plugin" || signal.Evidence[0].Timestamp.IsZero() {
+		t.Errorf("Expected evidence type and timestamp to be filled in, got %+v", signal.Evidence[0])
+	}
+}
+
+// TestPluginFailures tests that crashes, hangs and protocol errors are reported as errors
+func TestPluginFailures(t *testing.T) {
+	page := &detector.Page{Domain: "gacor88.com", Body: "<html></html>"}
+
+	// Each plugin run starts the test binary, which can take over a second
+	// under the race detector: only the hanging plugin gets a short timeout
+	tests := []struct {
+		mode     string
+		timeout  int
+		expected string
+	}{
+		{"crash", 0, "classifier exploded"},
+		{"sleep", 1, "timed out"},
+		{"badjson", 0, "invalid JSON"},
+		{"version", 0, "protocol version 99"},
+	}
+
+	for _, test := range tests {
+		p := helperPlugin(t, "helper", test.mode, test.timeout)
+		signals, err := p.Analyze(context.Background(), page)
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s: expected error containing %q, got %v", test.mode, test.expected, err)
+		}
+		if len(signals) != 0 {
+			t.Errorf("%s: expected no signals, got %v", test.mode, signals)
+		}
+	}
+}
+
+// TestPluginAsModule tests that a failing plugin does not stop the other modules
+func TestPluginAsModule(t *testing.T) {
+	registry := detector.NewRegistry()
+	registry.Register(helperPlugin(t, "helper", "crash", 0))
+	registry.Register(helperPlugin(t, "second", "ok", 0))
+
+	signals, runs := registry.Run(context.Background(), &detector.Page{Domain: "gacor88.com", Body: "<html></html>"})
+	if len(runs) != 2 || runs[0].Error == "" || runs[0].Name != "plugin:helper" {
+		t.Errorf("Expected the crashing plugin's error to be recorded, got %+v", runs)
+	}
+	if len(signals) != 1 || signals[0].SignalID != "HELPER_SIGNAL" {
+		t.Errorf("Expected the second plugin's signal, got %v", signals)
+	}
+}
+
+// TestRegisterConfiguredNames tests that plugins named like a built-in
+// module or an earlier plugin are reported instead of panicking
+func TestRegisterConfiguredNames(t *testing.T) {
+	registry := detector.NewRegistry()
+	registry.Register(detector.NewFuncDetector("cdn", "CDN", nil, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		return nil, nil
+	}))
+
+	err := registerConfigured(registry, []config.PluginConfig{
+		{Name: "classifier", Command: "classifier"},
+		{Name: "classifier", Command: "classifier-v2"},
+		{Name: "cdn", Command: "cdn-check"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "plugin classifier: name is already used") || !strings.Contains(err.Error(), "plugin cdn:") {
+		t.Errorf("Expected the duplicate and built-in names to be reported, got %v", err)
+	}
+	if names := registry.Names(); len(names) != 2 || names[0] != "cdn" || names[1] != "plugin:classifier" {
+		t.Errorf("Expected only the first classifier plugin to be registered, got %v", names)
+	}
+}
@@ -0,0 +1,67 @@
+package plugin
+
+import (
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// ProtocolVersion is the plugin protocol version fogger speaks. A plugin
+// must answer with the same version.
+//
+// Protocol: fogger starts the plugin executable once per request, writes a
+// single Request as JSON to its stdin and closes it. The plugin writes a
+// single Response as JSON to stdout and exits with status 0. Anything the
+// plugin writes to stderr is only used in error messages.
+const ProtocolVersion = 1
+
+// Request types
+const (
+	RequestDescribe = "describe"
+	RequestAnalyze  = "analyze"
+)
+
+// Request is sent to a plugin on stdin
+type Request struct {
+	ProtocolVersion int    `json:"protocol_version"`
+	Type            string `json:"type"`
+	Page            *Page  `json:"page,omitempty"`
+}
+
+// Response is read from a plugin's stdout. Describe requests fill Name,
+// Version and Description; analyze requests fill Signals.
+type Response struct {
+	ProtocolVersion int             `json:"protocol_version"`
+	Name            string          `json:"name,omitempty"`
+	Version         string          `json:"version,omitempty"`
+	Description     string          `json:"description,omitempty"`
+	Signals         []models.Signal `json:"signals,omitempty"`
+	Error           string          `json:"error,omitempty"`
+}
+
+// Page is the page model sent with analyze requests
+type Page struct {
+	Domain        string              `json:"domain"`
+	URL           string              `json:"url"`
+	StatusCode    int                 `json:"status_code"`
+	Headers       map[string][]string `json:"headers"`
+	Body          string              `json:"body"`
+	CDNProvider   string              `json:"cdn_provider"`
+	Resources     []models.Resource   `json:"resources"`
+	GameProviders []string            `json:"game_providers"`
+	Platform      string              `json:"platform"`
+}
+
+// NewPage builds the page model of a scanned page
+func NewPage(page *detector.Page) *Page {
+	return &Page{
+		Domain:        page.Domain,
+		URL:           page.URL,
+		StatusCode:    page.StatusCode,
+		Headers:       page.Headers,
+		Body:          page.Body,
+		CDNProvider:   page.CDNProvider,
+		Resources:     page.Resources,
+		GameProviders: page.GameProviders,
+		Platform:      page.Platform,
+	}
+}
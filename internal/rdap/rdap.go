@@ -0,0 +1,332 @@
+// Package rdap looks up the registration data of domains (creation and
+// expiry dates, registrar, status codes, nameservers) over RDAP, falling
+// back to WHOIS for registries without an RDAP service.
+package rdap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// DefaultBootstrapURL is the IANA registry of RDAP services per TLD
+const DefaultBootstrapURL = "https://data.iana.org/rdap/dns.json"
+
+// DefaultTimeout bounds a lookup when the client has no timeout
+const DefaultTimeout = 10 * time.Second
+
+// privacyMarkers are lowercase phrases in the registrant of domains
+// registered through a privacy or proxy service. Plain GDPR redaction
+// ("REDACTED FOR PRIVACY") is the registry default and does not count.
+var privacyMarkers = []string{
+	"whoisguard", "withheld for privacy", "domains by proxy", "privacyguardian",
+	"contact privacy", "privacy protect", "privacy service", "whois privacy",
+	"perfect privacy", "proxy protection", "identity protection", "super privacy",
+	"domain protection services", "private by design", "whoisprotection",
+}
+
+// bootstrap caches the RDAP services of each bootstrap registry, by URL
+var bootstrap = struct {
+	sync.Mutex
+	services map[string]map[string]string
+}{services: make(map[string]map[string]string)}
+
+// Client looks up domain registrations
+type Client struct {
+	// BaseURL is the RDAP service queried for every domain; when empty the
+	// service of the domain's TLD comes from the bootstrap registry
+	BaseURL      string
+	BootstrapURL string
+	// WhoisServer is queried when RDAP fails; when empty it is the server
+	// whois.iana.org refers to for the TLD
+	WhoisServer string
+	HTTP        *http.Client
+	Timeout     time.Duration
+}
+
+// New creates a client. Empty baseURL and whoisServer select the services
+// registered for each TLD.
+func New(baseURL, whoisServer string, timeout time.Duration) *Client {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Client{
+		BaseURL:      baseURL,
+		BootstrapURL: DefaultBootstrapURL,
+		WhoisServer:  whoisServer,
+		HTTP:         &http.Client{Timeout: timeout},
+		Timeout:      timeout,
+	}
+}
+
+// RegistrableDomain returns the domain a host is registered under
+// (www.gacor88.co.id -> gacor88.co.id)
+func RegistrableDomain(host string) (string, error) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	return publicsuffix.EffectiveTLDPlusOne(host)
+}
+
+// Lookup returns the registration of a domain from RDAP, or from WHOIS when
+// RDAP fails
+func (c *Client) Lookup(ctx context.Context, domain string) (*models.Registration, error) {
+	registration, rdapErr := c.LookupRDAP(ctx, domain)
+	if rdapErr == nil {
+		return registration, nil
+	}
+	registration, whoisErr := c.LookupWhois(ctx, domain)
+	if whoisErr == nil {
+		return registration, nil
+	}
+	return nil, fmt.Errorf("RDAP lookup failed: %v; WHOIS lookup failed: %v", rdapErr, whoisErr)
+}
+
+// LookupRDAP returns the registration of a domain from its RDAP service
+func (c *Client) LookupRDAP(ctx context.Context, domain string) (*models.Registration, error) {
+	base := c.BaseURL
+	if base == "" {
+		var err error
+		if base, err = c.service(ctx, domain); err != nil {
+			return nil, err
+		}
+	}
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	data, err := c.get(ctx, base+"domain/"+domain, "application/rdap+json")
+	if err != nil {
+		return nil, err
+	}
+	registration, err := ParseRDAP(data)
+	if err != nil {
+		return nil, err
+	}
+	if registration.Domain == "" {
+		registration.Domain = domain
+	}
+	registration.Server = base
+	registration.FetchedAt = time.Now()
+	return registration, nil
+}
+
+// service returns the RDAP base URL for the TLD of domain
+func (c *Client) service(ctx context.Context, domain string) (string, error) {
+	bootstrap.Lock()
+	defer bootstrap.Unlock()
+
+	services, ok := bootstrap.services[c.BootstrapURL]
+	if !ok {
+		ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+		data, err := c.get(ctx, c.BootstrapURL, "application/json")
+		if err != nil {
+			return "", fmt.Errorf("failed to load RDAP bootstrap: %v", err)
+		}
+		if services, err = parseBootstrap(data); err != nil {
+			return "", err
+		}
+		bootstrap.services[c.BootstrapURL] = services
+	}
+
+	tld := domain[strings.LastIndex(domain, ".")+1:]
+	if base, ok := services[strings.ToLower(tld)]; ok {
+		return base, nil
+	}
+	return "", fmt.Errorf("no RDAP service for .%s", tld)
+}
+
+// get fetches a URL, failing on any status but 200
+func (c *Client) get(ctx context.Context, url, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s not found", url)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d fetching %s", resp.StatusCode, url)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+}
+
+// parseBootstrap maps each TLD of an RDAP bootstrap registry to its first service URL
+func parseBootstrap(data []byte) (map[string]string, error) {
+	var registry struct {
+		Services [][][]string `json:"services"`
+	}
+	if err := json.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("invalid RDAP bootstrap: %v", err)
+	}
+
+	services := make(map[string]string)
+	for _, service := range registry.Services {
+		if len(service) < 2 || len(service[1]) == 0 {
+			continue
+		}
+		for _, tld := range service[0] {
+			services[strings.ToLower(tld)] = service[1][0]
+		}
+	}
+	return services, nil
+}
+
+// rdapDomain is the part of an RDAP domain response that is kept
+type rdapDomain struct {
+	LDHName string   `json:"ldhName"`
+	Status  []string `json:"status"`
+	Events  []struct {
+		Action string `json:"eventAction"`
+		Date   string `json:"eventDate"`
+	} `json:"events"`
+	Entities    []rdapEntity `json:"entities"`
+	Nameservers []struct {
+		LDHName string `json:"ldhName"`
+	} `json:"nameservers"`
+}
+
+// rdapEntity is a registrar, registrant or other contact of a domain
+type rdapEntity struct {
+	Roles    []string        `json:"roles"`
+	VCard    json.RawMessage `json:"vcardArray"`
+	Entities []rdapEntity    `json:"entities"`
+}
+
+// ParseRDAP parses an RDAP domain response
+func ParseRDAP(data []byte) (*models.Registration, error) {
+	var domain rdapDomain
+	if err := json.Unmarshal(data, &domain); err != nil {
+		return nil, fmt.Errorf("invalid RDAP response: %v", err)
+	}
+
+	registration := &models.Registration{
+		Domain: strings.ToLower(domain.LDHName),
+		Status: domain.Status,
+		Source: "rdap",
+	}
+	for _, event := range domain.Events {
+		date, ok := parseDate(event.Date)
+		if !ok {
+			continue
+		}
+		switch event.Action {
+		case "registration":
+			registration.CreatedAt = &date
+		case "expiration":
+			registration.ExpiresAt = &date
+		case "last changed":
+			registration.UpdatedAt = &date
+		}
+	}
+	for _, ns := range domain.Nameservers {
+		registration.Nameservers = append(registration.Nameservers, strings.ToLower(strings.TrimSuffix(ns.LDHName, ".")))
+	}
+
+	var walk func(entities []rdapEntity)
+	walk = func(entities []rdapEntity) {
+		for _, entity := range entities {
+			names := vcardNames(entity.VCard)
+			for _, role := range entity.Roles {
+				switch role {
+				case "registrar":
+					if registration.Registrar == "" && len(names) > 0 {
+						registration.Registrar = names[0]
+					}
+				case "registrant", "administrative", "technical":
+					if registration.PrivacyService == "" {
+						registration.PrivacyService = privacyService(names...)
+					}
+				}
+			}
+			walk(entity.Entities)
+		}
+	}
+	walk(domain.Entities)
+
+	return registration, nil
+}
+
+// vcardNames returns the fn and org values of a jCard
+func vcardNames(raw json.RawMessage) []string {
+	var vcard []json.RawMessage
+	if len(raw) == 0 || json.Unmarshal(raw, &vcard) != nil || len(vcard) < 2 {
+		return nil
+	}
+	var properties [][]json.RawMessage
+	if json.Unmarshal(vcard[1], &properties) != nil {
+		return nil
+	}
+
+	var names []string
+	for _, property := range properties {
+		if len(property) < 4 {
+			continue
+		}
+		var name, value string
+		if json.Unmarshal(property[0], &name) != nil || (name != "fn" && name != "org") {
+			continue
+		}
+		if json.Unmarshal(property[3], &value) == nil && strings.TrimSpace(value) != "" {
+			names = append(names, strings.TrimSpace(value))
+		}
+	}
+	return names
+}
+
+// privacyService returns the first name that belongs to a privacy service
+func privacyService(names ...string) string {
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		for _, marker := range privacyMarkers {
+			if strings.Contains(lower, marker) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// dateLayouts are the date formats used by RDAP and WHOIS servers
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02",
+	"02-Jan-2006",
+	"2006.01.02",
+	"2006/01/02",
+	"02.01.2006",
+}
+
+// parseDate parses a date in any of the registry formats
+func parseDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,113 @@
+package rdap
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/rdap/rdaptest"
+)
+
+// TestLookupRDAPBootstrap tests finding the RDAP service through the
+// bootstrap registry and parsing the domain response
+func TestLookupRDAPBootstrap(t *testing.T) {
+	created := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	expires := created.AddDate(1, 0, 0)
+	server := rdaptest.NewServer(map[string]*models.Registration{
+		"gacor88.xyz": {
+			Registrar:      "Gname.com Pte. Ltd.",
+			CreatedAt:      &created,
+			ExpiresAt:      &expires,
+			Status:         []string{"client transfer prohibited"},
+			Nameservers:    []string{"ADA.NS.CLOUDFLARE.COM"},
+			PrivacyService: "Withheld for Privacy ehf",
+		},
+	})
+	defer server.Close()
+
+	client := New("", "", time.Second)
+	client.BootstrapURL = server.URL + "dns.json"
+	registration, err := client.LookupRDAP(context.Background(), "gacor88.xyz")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if registration.Domain != "gacor88.xyz" || registration.Registrar != "Gname.com Pte. Ltd." || registration.Source != "rdap" {
+		t.Errorf("Expected the registrar from RDAP, got %+v", registration)
+	}
+	if registration.CreatedAt == nil || !registration.CreatedAt.Equal(created) || registration.ExpiresAt == nil || !registration.ExpiresAt.Equal(expires) {
+		t.Errorf("Expected the registration and expiration events, got %v and %v", registration.CreatedAt, registration.ExpiresAt)
+	}
+	if len(registration.Nameservers) != 1 || registration.Nameservers[0] != "ada.ns.cloudflare.com" {
+		t.Errorf("Expected the nameservers, got %v", registration.Nameservers)
+	}
+	if registration.PrivacyService != "Withheld for Privacy ehf" {
+		t.Errorf("Expected the privacy service, got %q", registration.PrivacyService)
+	}
+}
+
+// TestLookupFallsBackToWhois tests the WHOIS fallback when RDAP has no answer
+func TestLookupFallsBackToWhois(t *testing.T) {
+	rdapServer := rdaptest.NewServer(nil)
+	defer rdapServer.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			buf := make([]byte, 256)
+			n, _ := conn.Read(buf)
+			if strings.TrimSpace(string(buf[:n])) == "gacor88.id" {
+				conn.Write([]byte("Domain Name: gacor88.id\r\n" +
+					"Created On: 2025-06-01 08:00:00\r\n" +
+					"Expiration Date: 2026-06-01 08:00:00\r\n" +
+					"Status: clientTransferProhibited\r\n" +
+					"Sponsoring Registrar Organization: ignored\r\n" +
+					"Sponsoring Registrar: PT Registrar Contoh\r\n" +
+					"Name Server: NS1.CONTOH.ID\r\n" +
+					"Registrant Organization: REDACTED FOR PRIVACY\r\n"))
+			}
+			conn.Close()
+		}
+	}()
+
+	registration, err := New(rdapServer.URL, listener.Addr().String(), time.Second).Lookup(context.Background(), "gacor88.id")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if registration.Source != "whois" || registration.Registrar != "PT Registrar Contoh" {
+		t.Errorf("Expected the registrar from WHOIS, got %+v", registration)
+	}
+	if registration.CreatedAt == nil || registration.CreatedAt.Format("2006-01-02") != "2025-06-01" {
+		t.Errorf("Expected the creation date, got %v", registration.CreatedAt)
+	}
+	if len(registration.Nameservers) != 1 || registration.Nameservers[0] != "ns1.contoh.id" {
+		t.Errorf("Expected the nameservers, got %v", registration.Nameservers)
+	}
+	if registration.PrivacyService != "" {
+		t.Errorf("Expected GDPR redaction not to count as a privacy service, got %q", registration.PrivacyService)
+	}
+}
+
+// TestRegistrableDomain tests finding the registered domain of a host
+func TestRegistrableDomain(t *testing.T) {
+	for host, expected := range map[string]string{
+		"www.gacor88.com":    "gacor88.com",
+		"slot.gacor88.co.id": "gacor88.co.id",
+		"gacor88.xyz.":       "gacor88.xyz",
+	} {
+		if domain, err := RegistrableDomain(host); err != nil || domain != expected {
+			t.Errorf("Expected %s for %s, got %s (%v)", expected, host, domain, err)
+		}
+	}
+}
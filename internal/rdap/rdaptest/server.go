@@ -0,0 +1,127 @@
+// Package rdaptest provides a local RDAP server answering from fixed
+// registrations, for testing code that looks up domain registrations.
+package rdaptest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// Server is an RDAP server on 127.0.0.1. It also serves a bootstrap
+// registry at /dns.json listing itself for every TLD of its registrations.
+type Server struct {
+	// URL is the RDAP base URL of the server
+	URL string
+
+	mu            sync.Mutex
+	registrations map[string]*models.Registration
+	requests      []string
+	server        *httptest.Server
+}
+
+// NewServer starts a server answering from registrations, keyed by domain
+func NewServer(registrations map[string]*models.Registration) *Server {
+	s := &Server{registrations: registrations}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+	s.URL = s.server.URL + "/"
+	return s
+}
+
+// Close stops the server
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Requests returns the paths requested so far
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.URL.Path)
+	s.mu.Unlock()
+
+	if r.URL.Path == "/dns.json" {
+		tlds := make(map[string]bool)
+		for domain := range s.registrations {
+			tlds[domain[strings.LastIndex(domain, ".")+1:]] = true
+		}
+		var names []string
+		for tld := range tlds {
+			names = append(names, tld)
+		}
+		writeJSON(w, map[string]interface{}{
+			"version":  "1.0",
+			"services": [][][]string{{names, {s.URL}}},
+		})
+		return
+	}
+
+	domain := strings.ToLower(strings.TrimPrefix(r.URL.Path, "/domain/"))
+	registration, ok := s.registrations[domain]
+	if !strings.HasPrefix(r.URL.Path, "/domain/") || !ok {
+		w.Header().Set("Content-Type", "application/rdap+json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errorCode":404,"title":"Not Found"}`))
+		return
+	}
+	writeJSON(w, response(domain, registration))
+}
+
+// response builds the RDAP domain response of a registration
+func response(domain string, registration *models.Registration) map[string]interface{} {
+	var events []map[string]string
+	for action, date := range map[string]*time.Time{
+		"registration": registration.CreatedAt,
+		"expiration":   registration.ExpiresAt,
+		"last changed": registration.UpdatedAt,
+	} {
+		if date != nil {
+			events = append(events, map[string]string{"eventAction": action, "eventDate": date.Format(time.RFC3339)})
+		}
+	}
+
+	var nameservers []map[string]string
+	for _, ns := range registration.Nameservers {
+		nameservers = append(nameservers, map[string]string{"objectClassName": "nameserver", "ldhName": ns})
+	}
+
+	registrant := "REDACTED FOR PRIVACY"
+	if registration.PrivacyService != "" {
+		registrant = registration.PrivacyService
+	}
+
+	return map[string]interface{}{
+		"objectClassName": "domain",
+		"ldhName":         strings.ToUpper(domain),
+		"status":          registration.Status,
+		"events":          events,
+		"nameservers":     nameservers,
+		"entities": []map[string]interface{}{
+			{"objectClassName": "entity", "roles": []string{"registrar"}, "vcardArray": vcard(registration.Registrar)},
+			{"objectClassName": "entity", "roles": []string{"registrant"}, "vcardArray": vcard(registrant)},
+		},
+	}
+}
+
+// vcard builds a jCard with a formatted name
+func vcard(name string) []interface{} {
+	return []interface{}{"vcard", []interface{}{
+		[]interface{}{"version", map[string]string{}, "text", "4.0"},
+		[]interface{}{"fn", map[string]string{}, "text", name},
+	}}
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/rdap+json")
+	json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,176 @@
+package rdap
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// ianaWhois is asked which WHOIS server serves a TLD
+const ianaWhois = "whois.iana.org:43"
+
+// whoisFields maps lowercase WHOIS keys to the registration fields they fill
+var whoisFields = map[string]string{
+	"creation date":                          "created",
+	"created":                                "created",
+	"created on":                             "created",
+	"registered on":                          "created",
+	"registration time":                      "created",
+	"domain registration date":               "created",
+	"registry expiry date":                   "expires",
+	"registrar registration expiration date": "expires",
+	"expiry date":                            "expires",
+	"expiration date":                        "expires",
+	"expires on":                             "expires",
+	"paid-till":                              "expires",
+	"updated date":                           "updated",
+	"last updated":                           "updated",
+	"last modified":                          "updated",
+	"registrar":                              "registrar",
+	"sponsoring registrar":                   "registrar",
+	"registrar name":                         "registrar",
+	"domain status":                          "status",
+	"status":                                 "status",
+	"name server":                            "ns",
+	"nserver":                                "ns",
+	"registrant organization":                "registrant",
+	"registrant name":                        "registrant",
+	"registrant":                             "registrant",
+}
+
+// LookupWhois returns the registration of a domain from WHOIS
+func (c *Client) LookupWhois(ctx context.Context, domain string) (*models.Registration, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	server := c.WhoisServer
+	if server == "" {
+		tld := domain[strings.LastIndex(domain, ".")+1:]
+		referral, err := whoisQuery(ctx, ianaWhois, tld)
+		if err != nil {
+			return nil, err
+		}
+		if server = whoisReferral(referral); server == "" {
+			return nil, fmt.Errorf("no WHOIS server for .%s", tld)
+		}
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "43")
+	}
+
+	text, err := whoisQuery(ctx, server, domain)
+	if err != nil {
+		return nil, err
+	}
+	registration := ParseWhois(text)
+	if registration.CreatedAt == nil && registration.Registrar == "" {
+		return nil, fmt.Errorf("no registration data for %s from %s", domain, server)
+	}
+	registration.Domain = domain
+	registration.Server = server
+	registration.FetchedAt = time.Now()
+	return registration, nil
+}
+
+// whoisQuery sends a query to a WHOIS server and returns the answer
+func whoisQuery(ctx context.Context, server, query string) (string, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", server)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write([]byte(query + "\r\n")); err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(io.LimitReader(conn, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// whoisReferral returns the server an IANA answer refers to
+func whoisReferral(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "refer" || key == "whois" {
+			if value = strings.TrimSpace(value); value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// ParseWhois parses the "Key: value" lines of a WHOIS answer
+func ParseWhois(text string) *models.Registration {
+	registration := &models.Registration{Source: "whois"}
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		field := whoisFields[strings.ToLower(strings.TrimSpace(key))]
+		value = strings.TrimSpace(value)
+		if field == "" || value == "" {
+			continue
+		}
+
+		switch field {
+		case "created", "expires", "updated":
+			date, ok := parseDate(value)
+			if !ok {
+				continue
+			}
+			switch {
+			case field == "created" && registration.CreatedAt == nil:
+				registration.CreatedAt = &date
+			case field == "expires" && registration.ExpiresAt == nil:
+				registration.ExpiresAt = &date
+			case field == "updated" && registration.UpdatedAt == nil:
+				registration.UpdatedAt = &date
+			}
+		case "registrar":
+			if registration.Registrar == "" {
+				registration.Registrar = value
+			}
+		case "status":
+			// "clientTransferProhibited https://icann.org/epp#..."
+			registration.Status = appendUnique(registration.Status, strings.Fields(value)[0])
+		case "ns":
+			registration.Nameservers = appendUnique(registration.Nameservers, strings.ToLower(strings.TrimSuffix(strings.Fields(value)[0], ".")))
+		case "registrant":
+			if registration.PrivacyService == "" {
+				registration.PrivacyService = privacyService(value)
+			}
+		}
+	}
+	return registration
+}
+
+// appendUnique appends value to values unless it is already present
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,269 @@
+// Package resolver queries DNS servers directly, returning the full records
+// (with TTLs, SOA and CAA data) that the standard library resolver hides.
+// Queries go to the system nameserver, an explicit server over UDP or TCP,
+// or a DNS-over-HTTPS or DNS-over-TLS service, so results need not depend on
+// an ISP resolver that rewrites blocked domains.
+package resolver
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// DefaultServer is queried when no nameserver can be read from the system
+// configuration
+const DefaultServer = "8.8.8.8:53"
+
+// DefaultTimeout bounds a query when the resolver has no timeout
+const DefaultTimeout = 5 * time.Second
+
+// ednsBufferSize is the UDP payload size advertised with EDNS0
+const ednsBufferSize = 1232
+
+// typeCAA is the CAA record type, which dnsmessage does not name
+const typeCAA dnsmessage.Type = 257
+
+// recordTypes maps record type names to their DNS types
+var recordTypes = map[string]dnsmessage.Type{
+	"A":     dnsmessage.TypeA,
+	"AAAA":  dnsmessage.TypeAAAA,
+	"CNAME": dnsmessage.TypeCNAME,
+	"NS":    dnsmessage.TypeNS,
+	"SOA":   dnsmessage.TypeSOA,
+	"MX":    dnsmessage.TypeMX,
+	"TXT":   dnsmessage.TypeTXT,
+	"SRV":   dnsmessage.TypeSRV,
+	"CAA":   typeCAA,
+}
+
+// Resolver looks up DNS records
+type Resolver interface {
+	// Name identifies the resolver in evidence, e.g. "udp://8.8.8.8:53"
+	Name() string
+	// Lookup returns the records of one type for name, along with the CNAME
+	// records leading to them. A name without records of that type, or that
+	// does not exist, yields no records and no error.
+	Lookup(ctx context.Context, name, recordType string) ([]models.DNSRecord, error)
+}
+
+// exchanger sends one packed query and returns the packed response
+type exchanger interface {
+	exchange(ctx context.Context, query []byte, id uint16) ([]byte, error)
+}
+
+// New creates a resolver from a spec:
+//
+//	"" or "system"                 the first nameserver of /etc/resolv.conf
+//	"8.8.8.8", "udp://8.8.8.8:53"  a server over UDP, retried over TCP on truncation
+//	"tcp://8.8.8.8"                a server over TCP only
+//	"https://dns.google/dns-query" DNS-over-HTTPS (RFC 8484)
+//	"tls://1.1.1.1", "tls://dns.google:853"  DNS-over-TLS
+func New(spec string, timeout time.Duration) (Resolver, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	spec = strings.TrimSpace(spec)
+	if spec == "" || spec == "system" {
+		return NewSystem(timeout), nil
+	}
+	if !strings.Contains(spec, "://") {
+		return NewServer(spec, false, timeout), nil
+	}
+
+	parsed, err := url.Parse(spec)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid resolver %q", spec)
+	}
+	switch parsed.Scheme {
+	case "udp":
+		return NewServer(parsed.Host, false, timeout), nil
+	case "tcp":
+		return NewServer(parsed.Host, true, timeout), nil
+	case "https":
+		return NewDoH(spec, timeout), nil
+	case "tls":
+		return NewDoT(parsed.Host, timeout), nil
+	default:
+		return nil, fmt.Errorf("unsupported resolver scheme %q (use udp, tcp, https or tls)", parsed.Scheme)
+	}
+}
+
+// SystemServer returns the first nameserver of /etc/resolv.conf, or
+// DefaultServer if there is none
+func SystemServer() string {
+	file, err := os.Open("/etc/resolv.conf")
+	if err != nil {
+		return DefaultServer
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			return net.JoinHostPort(fields[1], "53")
+		}
+	}
+	return DefaultServer
+}
+
+// withPort adds port to an address without one
+func withPort(address, port string) string {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return net.JoinHostPort(strings.Trim(address, "[]"), port)
+	}
+	return address
+}
+
+// lookup runs a query through an exchanger and converts the answer
+func lookup(ctx context.Context, ex exchanger, timeout time.Duration, name, recordType string) ([]models.DNSRecord, error) {
+	qtype, ok := recordTypes[strings.ToUpper(recordType)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported record type %s", recordType)
+	}
+	qname, err := dnsmessage.NewName(fqdn(name))
+	if err != nil {
+		return nil, fmt.Errorf("invalid name %s: %v", name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	query := newQuery(qname, qtype)
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+	data, err := ex.exchange(ctx, packed, query.Header.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dnsmessage.Message{}
+	if err := response.Unpack(data); err != nil {
+		return nil, fmt.Errorf("invalid response: %v", err)
+	}
+	if response.Header.ID != query.Header.ID {
+		return nil, fmt.Errorf("response ID %d does not match query ID %d", response.Header.ID, query.Header.ID)
+	}
+	return answerRecords(response, qtype)
+}
+
+// newQuery builds a recursive query with an EDNS0 record for larger answers
+func newQuery(name dnsmessage.Name, qtype dnsmessage.Type) *dnsmessage.Message {
+	var opt dnsmessage.ResourceHeader
+	opt.SetEDNS0(ednsBufferSize, dnsmessage.RCodeSuccess, false)
+
+	return &dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: uint16(rand.Intn(1 << 16)), RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: qtype, Class: dnsmessage.ClassINET}},
+		Additionals: []dnsmessage.Resource{
+			{Header: opt, Body: &dnsmessage.OPTResource{}},
+		},
+	}
+}
+
+// answerRecords converts the answers of a response. CNAME records that lead
+// to the answer are returned along with the records of the queried type.
+func answerRecords(response *dnsmessage.Message, qtype dnsmessage.Type) ([]models.DNSRecord, error) {
+	switch response.RCode {
+	case dnsmessage.RCodeSuccess, dnsmessage.RCodeNameError:
+	default:
+		return nil, fmt.Errorf("server answered %s", response.RCode)
+	}
+
+	var records []models.DNSRecord
+	for _, answer := range response.Answers {
+		record := models.DNSRecord{
+			Name: strings.TrimSuffix(answer.Header.Name.String(), "."),
+			TTL:  answer.Header.TTL,
+		}
+		switch body := answer.Body.(type) {
+		case *dnsmessage.AResource:
+			record.Type, record.Value = "A", net.IP(body.A[:]).String()
+		case *dnsmessage.AAAAResource:
+			record.Type, record.Value = "AAAA", net.IP(body.AAAA[:]).String()
+		case *dnsmessage.CNAMEResource:
+			record.Type, record.Value = "CNAME", hostName(body.CNAME)
+		case *dnsmessage.NSResource:
+			record.Type, record.Value = "NS", hostName(body.NS)
+		case *dnsmessage.MXResource:
+			record.Type, record.Value = "MX", strconv.Itoa(int(body.Pref))+" "+hostName(body.MX)
+		case *dnsmessage.TXTResource:
+			record.Type, record.Value = "TXT", strings.Join(body.TXT, "")
+		case *dnsmessage.SRVResource:
+			record.Type = "SRV"
+			record.Value = fmt.Sprintf("%d %d %d %s", body.Priority, body.Weight, body.Port, hostName(body.Target))
+		case *dnsmessage.SOAResource:
+			record.Type = "SOA"
+			record.Value = fmt.Sprintf("%s %s %d %d %d %d %d", hostName(body.NS), hostName(body.MBox),
+				body.Serial, body.Refresh, body.Retry, body.Expire, body.MinTTL)
+		case *dnsmessage.UnknownResource:
+			if body.Type != typeCAA {
+				continue
+			}
+			value, ok := caaValue(body.Data)
+			if !ok {
+				continue
+			}
+			record.Type, record.Value = "CAA", value
+		default:
+			continue
+		}
+		if answer.Header.Type != qtype && answer.Header.Type != dnsmessage.TypeCNAME {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// caaValue formats CAA record data as "flags tag value"
+func caaValue(data []byte) (string, bool) {
+	if len(data) < 2 || len(data) < 2+int(data[1]) {
+		return "", false
+	}
+	tagEnd := 2 + int(data[1])
+	return fmt.Sprintf("%d %s %q", data[0], data[2:tagEnd], data[tagEnd:]), true
+}
+
+// hostName returns a DNS name without its trailing dot
+func hostName(name dnsmessage.Name) string {
+	return strings.TrimSuffix(name.String(), ".")
+}
+
+// fqdn returns name as a fully qualified DNS name
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// LookupIPv4 returns the IPv4 addresses of name
+func LookupIPv4(ctx context.Context, r Resolver, name string) ([]string, error) {
+	records, err := r.Lookup(ctx, name, "A")
+	if err != nil {
+		return nil, err
+	}
+	var ips []string
+	for _, record := range records {
+		if record.Type == "A" {
+			ips = append(ips, record.Value)
+		}
+	}
+	return ips, nil
+}
@@ -0,0 +1,179 @@
+package resolver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/resolver/resolvertest"
+)
+
+// TestLookupRecordTypes tests each collected record type against a local server
+func TestLookupRecordTypes(t *testing.T) {
+	server, err := resolvertest.NewServer([]models.DNSRecord{
+		{Name: "gacor88.xyz", Type: "A", TTL: 60, Value: "203.0.113.10"},
+		{Name: "gacor88.xyz", Type: "AAAA", TTL: 60, Value: "2001:db8::10"},
+		{Name: "gacor88.xyz", Type: "NS", TTL: 86400, Value: "ns1.example-dns.net"},
+		{Name: "gacor88.xyz", Type: "SOA", TTL: 3600, Value: "ns1.example-dns.net hostmaster.gacor88.xyz 2025061501 7200 3600 1209600 300"},
+		{Name: "gacor88.xyz", Type: "MX", TTL: 3600, Value: "10 mail.gacor88.xyz"},
+		{Name: "gacor88.xyz", Type: "TXT", TTL: 3600, Value: "v=spf1 -all"},
+		{Name: "gacor88.xyz", Type: "CAA", TTL: 3600, Value: `0 issue "letsencrypt.org"`},
+		{Name: "www.gacor88.xyz", Type: "CNAME", TTL: 300, Value: "gacor88.xyz"},
+		{Name: "_sip._tcp.gacor88.xyz", Type: "SRV", TTL: 300, Value: "10 5 5060 sip.gacor88.xyz"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start DNS server: %v", err)
+	}
+	defer server.Close()
+
+	client := NewServer(server.Addr, false, time.Second)
+	for recordType, expected := range map[string]string{
+		"A":     "203.0.113.10",
+		"AAAA":  "2001:db8::10",
+		"NS":    "ns1.example-dns.net",
+		"SOA":   "ns1.example-dns.net hostmaster.gacor88.xyz 2025061501 7200 3600 1209600 300",
+		"MX":    "10 mail.gacor88.xyz",
+		"TXT":   "v=spf1 -all",
+		"CAA":   `0 issue "letsencrypt.org"`,
+		"CNAME": "",
+	} {
+		records, err := client.Lookup(context.Background(), "gacor88.xyz", recordType)
+		if err != nil {
+			t.Errorf("Unexpected error looking up %s: %v", recordType, err)
+			continue
+		}
+		if expected == "" {
+			if len(records) != 0 {
+				t.Errorf("Expected no %s records, got %v", recordType, records)
+			}
+			continue
+		}
+		if len(records) != 1 || records[0].Type != recordType || records[0].Value != expected {
+			t.Errorf("Expected %s record %q, got %v", recordType, expected, records)
+		}
+	}
+
+	records, err := client.Lookup(context.Background(), "www.gacor88.xyz", "A")
+	if err != nil || len(records) != 1 || records[0].Type != "CNAME" || records[0].TTL != 300 {
+		t.Errorf("Expected the CNAME of www with its TTL, got %v (%v)", records, err)
+	}
+
+	records, err = client.Lookup(context.Background(), "_sip._tcp.gacor88.xyz", "SRV")
+	if err != nil || len(records) != 1 || records[0].Value != "10 5 5060 sip.gacor88.xyz" {
+		t.Errorf("Expected the SRV record as priority weight port target, got %v (%v)", records, err)
+	}
+
+	records, err = client.Lookup(context.Background(), "missing.gacor88.xyz", "A")
+	if err != nil || len(records) != 0 {
+		t.Errorf("Expected no records and no error for a missing name, got %v (%v)", records, err)
+	}
+}
+
+// TestLookupTruncatedRetriesTCP tests that a truncated UDP answer is retried over TCP
+func TestLookupTruncatedRetriesTCP(t *testing.T) {
+	server, err := resolvertest.NewServer([]models.DNSRecord{
+		{Name: "gacor88.xyz", Type: "TXT", TTL: 300, Value: "google-site-verification=abc"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start DNS server: %v", err)
+	}
+	defer server.Close()
+	server.SetTruncateUDP(true)
+
+	records, err := NewServer(server.Addr, false, time.Second).Lookup(context.Background(), "gacor88.xyz", "TXT")
+	if err != nil || len(records) != 1 || records[0].Value != "google-site-verification=abc" {
+		t.Errorf("Expected the TXT record over TCP, got %v (%v)", records, err)
+	}
+	if queries := server.Queries(); len(queries) != 2 {
+		t.Errorf("Expected a UDP and a TCP query, got %v", queries)
+	}
+}
+
+// TestNewSpecs tests that resolver specs select the matching transport
+func TestNewSpecs(t *testing.T) {
+	for spec, expected := range map[string]string{
+		"8.8.8.8":                        "udp://8.8.8.8:53",
+		"udp://1.1.1.1:5353":             "udp://1.1.1.1:5353",
+		"tcp://9.9.9.9":                  "tcp://9.9.9.9:53",
+		"tls://1.1.1.1":                  "tls://1.1.1.1:853",
+		"tls://dns.google:8853":          "tls://dns.google:8853",
+		"https://dns.google/dns-query":   "https://dns.google/dns-query",
+		"https://1.1.1.1/dns-query?ct=1": "https://1.1.1.1/dns-query?ct=1",
+	} {
+		r, err := New(spec, time.Second)
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %v", spec, err)
+			continue
+		}
+		if r.Name() != expected {
+			t.Errorf("Expected %s to name %s, got %s", spec, expected, r.Name())
+		}
+	}
+
+	r, err := New("system", time.Second)
+	if err != nil || r.Name() != "system://"+SystemServer() {
+		t.Errorf("Expected the system resolver, got %v (%v)", r, err)
+	}
+
+	for _, spec := range []string{"quic://dns.adguard.com", "tls://"} {
+		if _, err := New(spec, time.Second); err == nil {
+			t.Errorf("Expected an error for %s", spec)
+		}
+	}
+}
+
+// TestLookupDoH tests DNS-over-HTTPS lookups against a local server
+func TestLookupDoH(t *testing.T) {
+	server, err := resolvertest.NewServer([]models.DNSRecord{
+		{Name: "gacor88.xyz", Type: "A", TTL: 60, Value: "203.0.113.10"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start DNS server: %v", err)
+	}
+	defer server.Close()
+
+	doh := httptest.NewTLSServer(server)
+	defer doh.Close()
+
+	r := NewDoH(doh.URL+"/dns-query", time.Second)
+	r.Client = doh.Client()
+	records, err := r.Lookup(context.Background(), "gacor88.xyz", "A")
+	if err != nil || len(records) != 1 || records[0].Value != "203.0.113.10" {
+		t.Errorf("Expected the A record over DoH, got %v (%v)", records, err)
+	}
+}
+
+// TestLookupDoT tests DNS-over-TLS lookups against a local server
+func TestLookupDoT(t *testing.T) {
+	server, err := resolvertest.NewServer([]models.DNSRecord{
+		{Name: "gacor88.xyz", Type: "NS", TTL: 86400, Value: "ns1.example-dns.net"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start DNS server: %v", err)
+	}
+	defer server.Close()
+
+	// Borrow the test certificate of an httptest server
+	cert := httptest.NewTLSServer(nil)
+	defer cert.Close()
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: cert.TLS.Certificates})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go server.Serve(listener)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert.Certificate())
+	r := NewDoT(listener.Addr().String(), time.Second)
+	r.TLSConfig = &tls.Config{RootCAs: roots, ServerName: "example.com"}
+
+	records, err := r.Lookup(context.Background(), "gacor88.xyz", "NS")
+	if err != nil || len(records) != 1 || records[0].Value != "ns1.example-dns.net" {
+		t.Errorf("Expected the NS record over DoT, got %v (%v)", records, err)
+	}
+}
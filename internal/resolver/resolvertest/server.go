@@ -0,0 +1,344 @@
+// Package resolvertest provides a local DNS server answering from fixed
+// records, for testing code that queries DNS. The server also answers
+// DNS-over-HTTPS requests as an http.Handler and DNS-over-TLS queries on a
+// TLS listener passed to Serve.
+package resolvertest
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// Server is a DNS server on 127.0.0.1 serving the same records over UDP and
+// TCP. A record named "*.example.com" answers every name below example.com
+// that has no records of its own.
+type Server struct {
+	// Addr is the host:port the server listens on
+	Addr string
+
+	mu          sync.Mutex
+	records     []models.DNSRecord
+	queries     []string
+	truncateUDP bool
+	conn        net.PacketConn
+	listener    net.Listener
+}
+
+// NewServer starts a server answering from records
+func NewServer(records []models.DNSRecord) (*Server, error) {
+	s := &Server{records: records}
+
+	var err error
+	for attempt := 0; attempt < 10; attempt++ {
+		if s.conn, err = net.ListenPacket("udp", "127.0.0.1:0"); err != nil {
+			return nil, err
+		}
+		if s.listener, err = net.Listen("tcp", s.conn.LocalAddr().String()); err == nil {
+			break
+		}
+		s.conn.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on a free UDP and TCP port: %v", err)
+	}
+	s.Addr = s.conn.LocalAddr().String()
+
+	go s.serveUDP()
+	go s.Serve(s.listener)
+	return s, nil
+}
+
+// Close stops the server
+func (s *Server) Close() {
+	s.conn.Close()
+	s.listener.Close()
+}
+
+// SetRecords replaces the records the server answers from
+func (s *Server) SetRecords(records []models.DNSRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = records
+}
+
+// SetTruncateUDP makes every UDP answer empty and truncated, forcing TCP
+func (s *Server) SetTruncateUDP(truncate bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.truncateUDP = truncate
+}
+
+// Queries returns the questions received so far as "TYPE name"
+func (s *Server) Queries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.queries...)
+}
+
+func (s *Server) serveUDP() {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		truncate := s.truncateUDP
+		s.mu.Unlock()
+		if response := s.answer(buf[:n], truncate); response != nil {
+			s.conn.WriteTo(response, addr)
+		}
+	}
+}
+
+// Serve answers length-prefixed queries on listener until it is closed; a
+// listener from tls.Listen serves DNS-over-TLS
+func (s *Server) Serve(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			var length uint16
+			if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+				return
+			}
+			query := make([]byte, length)
+			if _, err := io.ReadFull(conn, query); err != nil {
+				return
+			}
+			response := s.answer(query, false)
+			if response == nil {
+				return
+			}
+			frame := make([]byte, 2, 2+len(response))
+			binary.BigEndian.PutUint16(frame, uint16(len(response)))
+			conn.Write(append(frame, response...))
+		}()
+	}
+}
+
+// ServeHTTP answers DNS-over-HTTPS POST requests (RFC 8484)
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/dns-message" {
+		http.Error(w, "expected a POST of application/dns-message", http.StatusBadRequest)
+		return
+	}
+	query, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := s.answer(query, false)
+	if response == nil {
+		http.Error(w, "invalid query", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/dns-message")
+	w.Write(response)
+}
+
+// answer builds the response to a packed query
+func (s *Server) answer(query []byte, truncate bool) []byte {
+	var request dnsmessage.Message
+	if err := request.Unpack(query); err != nil || len(request.Questions) == 0 {
+		return nil
+	}
+	question := request.Questions[0]
+	name := strings.ToLower(strings.TrimSuffix(question.Name.String(), "."))
+	qtype := typeName(question.Type)
+
+	s.mu.Lock()
+	s.queries = append(s.queries, qtype+" "+name)
+	records := s.records
+	s.mu.Unlock()
+
+	response := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:                 request.Header.ID,
+			Response:           true,
+			Authoritative:      true,
+			RecursionDesired:   request.Header.RecursionDesired,
+			RecursionAvailable: true,
+		},
+		Questions: request.Questions,
+	}
+	if truncate {
+		response.Header.Truncated = true
+		packed, _ := response.Pack()
+		return packed
+	}
+
+	matches := matchingRecords(records, name)
+	if len(matches) == 0 {
+		response.Header.RCode = dnsmessage.RCodeNameError
+	}
+	for _, record := range matches {
+		if record.Type != qtype && record.Type != "CNAME" {
+			continue
+		}
+		resource, err := newResource(question.Name, record)
+		if err != nil {
+			continue
+		}
+		response.Answers = append(response.Answers, resource)
+	}
+
+	packed, err := response.Pack()
+	if err != nil {
+		return nil
+	}
+	return packed
+}
+
+// matchingRecords returns the records of name, or of the closest wildcard
+// when name has none
+func matchingRecords(records []models.DNSRecord, name string) []models.DNSRecord {
+	var exact []models.DNSRecord
+	for _, record := range records {
+		if strings.EqualFold(record.Name, name) {
+			exact = append(exact, record)
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+
+	for labels := strings.Split(name, "."); len(labels) > 1; labels = labels[1:] {
+		wildcard := "*." + strings.Join(labels[1:], ".")
+		var matches []models.DNSRecord
+		for _, record := range records {
+			if strings.EqualFold(record.Name, wildcard) {
+				matches = append(matches, record)
+			}
+		}
+		if len(matches) > 0 {
+			return matches
+		}
+	}
+	return nil
+}
+
+// newResource encodes a record as an answer for name
+func newResource(name dnsmessage.Name, record models.DNSRecord) (dnsmessage.Resource, error) {
+	header := dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassINET, TTL: record.TTL}
+	fields := strings.Fields(record.Value)
+
+	var body dnsmessage.ResourceBody
+	switch record.Type {
+	case "A":
+		ip := net.ParseIP(record.Value).To4()
+		if ip == nil {
+			return dnsmessage.Resource{}, fmt.Errorf("invalid A record %s", record.Value)
+		}
+		var a [4]byte
+		copy(a[:], ip)
+		body = &dnsmessage.AResource{A: a}
+	case "AAAA":
+		ip := net.ParseIP(record.Value).To16()
+		if ip == nil {
+			return dnsmessage.Resource{}, fmt.Errorf("invalid AAAA record %s", record.Value)
+		}
+		var aaaa [16]byte
+		copy(aaaa[:], ip)
+		body = &dnsmessage.AAAAResource{AAAA: aaaa}
+	case "CNAME":
+		target, err := dnsmessage.NewName(fqdn(record.Value))
+		if err != nil {
+			return dnsmessage.Resource{}, err
+		}
+		body = &dnsmessage.CNAMEResource{CNAME: target}
+	case "NS":
+		target, err := dnsmessage.NewName(fqdn(record.Value))
+		if err != nil {
+			return dnsmessage.Resource{}, err
+		}
+		body = &dnsmessage.NSResource{NS: target}
+	case "MX":
+		if len(fields) != 2 {
+			return dnsmessage.Resource{}, fmt.Errorf("invalid MX record %s", record.Value)
+		}
+		pref, _ := strconv.Atoi(fields[0])
+		target, err := dnsmessage.NewName(fqdn(fields[1]))
+		if err != nil {
+			return dnsmessage.Resource{}, err
+		}
+		body = &dnsmessage.MXResource{Pref: uint16(pref), MX: target}
+	case "TXT":
+		body = &dnsmessage.TXTResource{TXT: []string{record.Value}}
+	case "SRV":
+		if len(fields) != 4 {
+			return dnsmessage.Resource{}, fmt.Errorf("invalid SRV record %s", record.Value)
+		}
+		var numbers [3]uint16
+		for i := range numbers {
+			n, _ := strconv.ParseUint(fields[i], 10, 16)
+			numbers[i] = uint16(n)
+		}
+		target, err := dnsmessage.NewName(fqdn(fields[3]))
+		if err != nil {
+			return dnsmessage.Resource{}, err
+		}
+		body = &dnsmessage.SRVResource{Priority: numbers[0], Weight: numbers[1], Port: numbers[2], Target: target}
+	case "SOA":
+		if len(fields) != 7 {
+			return dnsmessage.Resource{}, fmt.Errorf("invalid SOA record %s", record.Value)
+		}
+		ns, err := dnsmessage.NewName(fqdn(fields[0]))
+		if err != nil {
+			return dnsmessage.Resource{}, err
+		}
+		mbox, err := dnsmessage.NewName(fqdn(fields[1]))
+		if err != nil {
+			return dnsmessage.Resource{}, err
+		}
+		var numbers [5]uint32
+		for i := range numbers {
+			n, _ := strconv.ParseUint(fields[2+i], 10, 32)
+			numbers[i] = uint32(n)
+		}
+		body = &dnsmessage.SOAResource{NS: ns, MBox: mbox, Serial: numbers[0], Refresh: numbers[1],
+			Retry: numbers[2], Expire: numbers[3], MinTTL: numbers[4]}
+	case "CAA":
+		if len(fields) < 3 {
+			return dnsmessage.Resource{}, fmt.Errorf("invalid CAA record %s", record.Value)
+		}
+		flags, _ := strconv.Atoi(fields[0])
+		tag := fields[1]
+		value := strings.Trim(strings.Join(fields[2:], " "), `"`)
+		data := append([]byte{byte(flags), byte(len(tag))}, tag...)
+		body = &dnsmessage.UnknownResource{Type: 257, Data: append(data, value...)}
+	default:
+		return dnsmessage.Resource{}, fmt.Errorf("unsupported record type %s", record.Type)
+	}
+
+	return dnsmessage.Resource{Header: header, Body: body}, nil
+}
+
+// typeName returns the name of a record type
+func typeName(t dnsmessage.Type) string {
+	if t == 257 {
+		return "CAA"
+	}
+	return strings.TrimPrefix(t.String(), "Type")
+}
+
+// fqdn returns name as a fully qualified DNS name
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
@@ -0,0 +1,228 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// Server queries a DNS server over UDP, retrying over TCP when the answer is
+// truncated, or over TCP only
+type Server struct {
+	Address string
+	TCPOnly bool
+	Timeout time.Duration
+	// name overrides the resolver name, for the system resolver
+	name string
+}
+
+// NewServer creates a resolver for a server address (host or host:port)
+func NewServer(address string, tcpOnly bool, timeout time.Duration) *Server {
+	return &Server{Address: withPort(address, "53"), TCPOnly: tcpOnly, Timeout: timeout}
+}
+
+// NewSystem creates a resolver for the first nameserver of /etc/resolv.conf,
+// which is usually the ISP resolver
+func NewSystem(timeout time.Duration) *Server {
+	s := NewServer(SystemServer(), false, timeout)
+	s.name = "system://" + s.Address
+	return s
+}
+
+// Name returns the resolver name
+func (s *Server) Name() string {
+	if s.name != "" {
+		return s.name
+	}
+	if s.TCPOnly {
+		return "tcp://" + s.Address
+	}
+	return "udp://" + s.Address
+}
+
+// Lookup returns the records of one type for name
+func (s *Server) Lookup(ctx context.Context, name, recordType string) ([]models.DNSRecord, error) {
+	records, err := lookup(ctx, s, s.Timeout, name, recordType)
+	if err != nil {
+		return nil, fmt.Errorf("%s query for %s to %s failed: %v", recordType, name, s.Name(), err)
+	}
+	return records, nil
+}
+
+func (s *Server) exchange(ctx context.Context, query []byte, id uint16) ([]byte, error) {
+	if !s.TCPOnly {
+		response, err := exchangeUDP(ctx, s.Address, query, id)
+		if err != nil {
+			return nil, err
+		}
+		var parser dnsmessage.Parser
+		if header, err := parser.Start(response); err != nil || !header.Truncated {
+			return response, nil
+		}
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.Address)
+	if err != nil {
+		return nil, err
+	}
+	return exchangeStream(ctx, conn, query)
+}
+
+// DoT queries a DNS-over-TLS service (RFC 7858)
+type DoT struct {
+	Address string
+	Timeout time.Duration
+	// TLSConfig overrides the TLS settings; tests use it to trust a local server
+	TLSConfig *tls.Config
+}
+
+// NewDoT creates a DNS-over-TLS resolver for address (host or host:port)
+func NewDoT(address string, timeout time.Duration) *DoT {
+	return &DoT{Address: withPort(address, "853"), Timeout: timeout}
+}
+
+// Name returns the resolver name
+func (d *DoT) Name() string { return "tls://" + d.Address }
+
+// Lookup returns the records of one type for name
+func (d *DoT) Lookup(ctx context.Context, name, recordType string) ([]models.DNSRecord, error) {
+	records, err := lookup(ctx, d, d.Timeout, name, recordType)
+	if err != nil {
+		return nil, fmt.Errorf("%s query for %s to %s failed: %v", recordType, name, d.Name(), err)
+	}
+	return records, nil
+}
+
+func (d *DoT) exchange(ctx context.Context, query []byte, id uint16) ([]byte, error) {
+	config := d.TLSConfig
+	if config == nil {
+		host, _, _ := net.SplitHostPort(d.Address)
+		config = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+	}
+	dialer := &tls.Dialer{Config: config}
+	conn, err := dialer.DialContext(ctx, "tcp", d.Address)
+	if err != nil {
+		return nil, err
+	}
+	return exchangeStream(ctx, conn, query)
+}
+
+// DoH queries a DNS-over-HTTPS service (RFC 8484) with POST requests
+type DoH struct {
+	URL     string
+	Timeout time.Duration
+	Client  *http.Client
+}
+
+// NewDoH creates a DNS-over-HTTPS resolver for a service URL
+func NewDoH(serviceURL string, timeout time.Duration) *DoH {
+	return &DoH{URL: serviceURL, Timeout: timeout, Client: &http.Client{Timeout: timeout}}
+}
+
+// Name returns the resolver name
+func (d *DoH) Name() string { return d.URL }
+
+// Lookup returns the records of one type for name
+func (d *DoH) Lookup(ctx context.Context, name, recordType string) ([]models.DNSRecord, error) {
+	records, err := lookup(ctx, d, d.Timeout, name, recordType)
+	if err != nil {
+		return nil, fmt.Errorf("%s query for %s to %s failed: %v", recordType, name, d.Name(), err)
+	}
+	return records, nil
+}
+
+func (d *DoH) exchange(ctx context.Context, query []byte, id uint16) ([]byte, error) {
+	// RFC 8484 recommends ID 0 so responses can be cached
+	binary.BigEndian.PutUint16(query, 0)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/dns-message")
+	request.Header.Set("Accept", "application/dns-message")
+
+	resp, err := d.Client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	response, err := io.ReadAll(io.LimitReader(resp.Body, 65535))
+	if err != nil {
+		return nil, err
+	}
+	if len(response) < 2 {
+		return nil, fmt.Errorf("short response")
+	}
+	// Restore the query ID so the response is matched like any other
+	binary.BigEndian.PutUint16(response, id)
+	return response, nil
+}
+
+// exchangeUDP sends a query in one datagram and waits for the matching answer
+func exchangeUDP(ctx context.Context, address string, query []byte, id uint16) ([]byte, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		// Ignore stray datagrams that don't answer this query
+		var parser dnsmessage.Parser
+		if header, err := parser.Start(buf[:n]); err == nil && header.ID == id && header.Response {
+			return buf[:n], nil
+		}
+	}
+}
+
+// exchangeStream sends a length-prefixed query over a TCP or TLS connection
+// and reads the length-prefixed answer
+func exchangeStream(ctx context.Context, conn net.Conn, query []byte) ([]byte, error) {
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	frame := make([]byte, 2, 2+len(query))
+	binary.BigEndian.PutUint16(frame, uint16(len(query)))
+	if _, err := conn.Write(append(frame, query...)); err != nil {
+		return nil, err
+	}
+	var length uint16
+	if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+	response := make([]byte, length)
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
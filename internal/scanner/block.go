@@ -0,0 +1,45 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/resolver"
+)
+
+// checkBlockStatus works out whether ISPs block a domain, comparing the
+// configured ISP and neutral resolvers. Block and reachability times carry
+// over from the last saved result of the domain.
+func checkBlockStatus(ctx context.Context, domain string, timeout time.Duration) (*models.BlockStatus, error) {
+	cfg := config.Get().BlockCheck
+
+	isp, err := resolver.New(cfg.ISPResolver, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ISP resolver: %v", err)
+	}
+	neutral, err := resolver.New(cfg.NeutralResolver, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid neutral resolver: %v", err)
+	}
+
+	// Bound the lookups and page fetches together
+	ctx, cancel := context.WithTimeout(ctx, 3*timeout)
+	defer cancel()
+
+	blockDetector := detector.NewBlockDetector(isp, neutral, timeout)
+	for _, ip := range cfg.BlockIPs {
+		blockDetector.BlockIPs[ip] = true
+	}
+	status, err := blockDetector.CheckBlock(ctx, dnsName(domain))
+	if err != nil {
+		return status, err
+	}
+	if previous := previousResult(domain); previous != nil {
+		status.CarryHistory(previous.Domain.BlockStatus)
+	}
+	return status, nil
+}
@@ -0,0 +1,45 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/detector"
+)
+
+// loadCDNRanges loads the CDN ranges at catalogs.cdn_ranges, else those
+// saved by `fogger cdn-ranges update`, else the built-in ranges
+func loadCDNRanges() *detector.IPRanges {
+	path := config.Get().Catalogs.CDNRanges
+	if path == "" {
+		if saved, err := detector.DefaultCDNRangesPath(); err == nil {
+			if _, err := os.Stat(saved); err == nil {
+				path = saved
+			}
+		}
+	}
+
+	catalog, err := detector.LoadCDNRanges(path)
+	if err == nil {
+		var ranges *detector.IPRanges
+		if ranges, err = detector.NewIPRanges(catalog); err == nil {
+			return ranges
+		}
+	}
+	fmt.Printf("Error loading CDN ranges, using built-in ranges: %v\n", err)
+	catalog, _ = detector.LoadCDNRanges("")
+	ranges, _ := detector.NewIPRanges(catalog)
+	return ranges
+}
+
+// loadCDNEngine creates the CDN engine from the fingerprints at
+// catalogs.cdn_fingerprints, or the built-in ones, and the CDN ranges
+func loadCDNEngine() *detector.CDNEngine {
+	catalog, err := detector.LoadCDNFingerprints(config.Get().Catalogs.CDNFingerprints)
+	if err != nil {
+		fmt.Printf("Error loading CDN fingerprints, using built-in fingerprints: %v\n", err)
+		catalog, _ = detector.LoadCDNFingerprints("")
+	}
+	return detector.NewCDNEngine(catalog, loadCDNRanges())
+}
@@ -0,0 +1,53 @@
+package scanner
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/resolver/resolvertest"
+)
+
+// TestCDNFromCNAMEChain tests that a CDN stripping its headers is found
+// through the CNAME chain of the www name, and that the chain is recorded
+func TestCDNFromCNAMEChain(t *testing.T) {
+	server, err := resolvertest.NewServer([]models.DNSRecord{
+		{Name: "gacor88.xyz", Type: "A", TTL: 300, Value: "198.51.100.5"},
+		{Name: "www.gacor88.xyz", Type: "CNAME", TTL: 300, Value: "edge.gacor88.xyz"},
+		{Name: "edge.gacor88.xyz", Type: "CNAME", TTL: 300, Value: "gacor88.xyz.edgekey.net"},
+		{Name: "gacor88.xyz.edgekey.net", Type: "CNAME", TTL: 300, Value: "e1234.a.akamaiedge.net"},
+		{Name: "e1234.a.akamaiedge.net", Type: "A", TTL: 20, Value: "23.45.67.89"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start DNS server: %v", err)
+	}
+	defer server.Close()
+
+	previous := config.Get().DNS.Server
+	config.Get().DNS.Server = server.Addr
+	defer func() { config.Get().DNS.Server = previous }()
+
+	resp := &http.Response{Header: http.Header{"Server": {"nginx"}}}
+	page := &detector.Page{Domain: "https://gacor88.xyz/", Timeout: time.Second, CDNObservation: cdnObservation(resp, "", "")}
+	detectCNAMEChains(context.Background(), page)
+	chains := page.CDNObservation.CNAMEChains
+	if len(chains) != 1 || chains[0].Name != "www.gacor88.xyz" || len(chains[0].Targets) != 3 {
+		t.Fatalf("Expected the three-hop chain of www.gacor88.xyz, got %+v", chains)
+	}
+
+	cdn := page.CDN
+	if cdn.Name != "akamai" || page.CDNProvider != "akamai" || len(cdn.CNAMEChains) != 1 {
+		t.Fatalf("Expected akamai from the CNAME chain, got %+v", cdn)
+	}
+	expected := "www.gacor88.xyz -> edge.gacor88.xyz -> gacor88.xyz.edgekey.net -> e1234.a.akamaiedge.net"
+	for _, match := range cdn.Matches {
+		if match.Kind != models.CDNMatchCNAME || !strings.Contains(match.Reference, expected) {
+			t.Errorf("Expected CNAME matches recording the chain, got %+v", match)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package scanner
+
+import (
+	"os"
+	"time"
+
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/store"
+)
+
+// detectChurnSignals compares a domain's name against the stored domains
+// and the mirrors it advertises and records its siblings as SIBLING_OF
+// relations. The store is only read; the commands that save the result
+// link the siblings in it.
+func detectChurnSignals(domain string, relations []models.Relation) ([]models.Signal, []models.Relation) {
+	name, err := detector.ParseDomainName(dnsName(domain))
+	if err != nil {
+		return nil, nil
+	}
+
+	var known []string
+	for _, relation := range relations {
+		if relation.Type == models.RelationMirrorOf {
+			known = append(known, relation.Target)
+		}
+	}
+
+	// Stored domains are compared only when a store exists
+	if path, err := store.DefaultPath(); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			if db, err := store.Open(path); err == nil {
+				for _, result := range db.Results() {
+					if storedName, err := detector.ParseDomainName(dnsName(result.Domain.Domain)); err == nil {
+						known = append(known, storedName.Domain)
+					}
+				}
+			}
+		}
+	}
+
+	signals, siblings := detector.NewChurnDetector().DetectChurn(name, known)
+
+	var siblingRelations []models.Relation
+	for _, sibling := range siblings {
+		siblingRelations = append(siblingRelations, models.Relation{
+			Source: name.Domain,
+			Target: sibling,
+			Type:   models.RelationSiblingOf,
+			Evidence: []models.Evidence{
+				{
+					Type:      "domain_name",
+					Reference: "Name " + name.Domain + " matches " + sibling,
+					Timestamp: time.Now(),
+				},
+			},
+		})
+	}
+
+	return signals, siblingRelations
+}
@@ -0,0 +1,47 @@
+package scanner
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/store"
+)
+
+// TestChurnModuleRelatesSiblings tests that sibling names among stored and
+// advertised domains become relations without writing the store
+func TestChurnModuleRelatesSiblings(t *testing.T) {
+	cfg := config.Get()
+	previousStore := cfg.Store.Path
+	cfg.Store.Path = filepath.Join(t.TempDir(), "store.json")
+	defer func() { cfg.Store.Path = previousStore }()
+
+	db, err := store.Open(cfg.Store.Path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	db.PutResult(&models.AnalysisResult{Domain: models.Domain{Domain: "www.slot88a.com"}})
+	db.PutResult(&models.AnalysisResult{Domain: models.Domain{Domain: "news.example"}})
+	if err := db.Save(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	signals, relations := detectChurnSignals("https://slot88b.net/", []models.Relation{
+		{Source: "slot88b.net", Target: "slot88c.xyz", Type: models.RelationMirrorOf},
+	})
+	if len(signals) != 2 {
+		t.Errorf("Expected rotation and sibling signals, got %v", signals)
+	}
+	if len(relations) != 2 || relations[0].Type != models.RelationSiblingOf || relations[0].Target != "slot88a.com" {
+		t.Errorf("Expected SIBLING_OF relations to slot88a.com and slot88c.xyz, got %v", relations)
+	}
+
+	reopened, err := store.Open(cfg.Store.Path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if siblings := reopened.Siblings("slot88a.com"); len(siblings) != 0 {
+		t.Errorf("Expected the module not to link siblings in the store, got %v", siblings)
+	}
+}
@@ -0,0 +1,148 @@
+package scanner
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// maxSitemaps bounds how many sitemaps are read per site
+const maxSitemaps = 5
+
+// compromiseCheckEnabled reports whether the compromised host check runs for
+// a domain: always when enabled, otherwise only on the configured suffixes
+// of institutions that are frequent injection targets
+func compromiseCheckEnabled(domain string) bool {
+	cfg := config.Get().Compromise
+	if cfg.Enabled {
+		return true
+	}
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	for _, suffix := range cfg.Suffixes {
+		suffix = strings.ToLower(strings.Trim(suffix, "."))
+		if suffix != "" && (domain == suffix || strings.HasSuffix(domain, "."+suffix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectCompromiseSignals looks for judol pages injected into a legitimate
+// site: hidden and off-topic links on the page, gambling URLs listed in
+// robots.txt and the sitemap, and injected pages confirmed by fetching them.
+// It returns the signals and the injected URLs.
+func detectCompromiseSignals(client *http.Client, pageURL, body string) ([]models.Signal, []string) {
+	compromiseDetector := detector.NewCompromiseDetector()
+	report := compromiseDetector.AnalyzePage(body, pageURL)
+	if report.MainContentGambling {
+		return nil, nil
+	}
+
+	base, err := url.Parse(pageURL)
+	if err != nil || base.Host == "" {
+		return compromiseDetector.DetectCompromise(report), report.InjectedURLs()
+	}
+	root := &url.URL{Scheme: base.Scheme, Host: base.Host, Path: "/"}
+
+	// robots.txt entries and the sitemaps it points at
+	sitemaps := []string{root.ResolveReference(&url.URL{Path: "/sitemap.xml"}).String()}
+	if data, err := fetchAsset(client, root.ResolveReference(&url.URL{Path: "/robots.txt"}).String()); err == nil {
+		paths, robotsSitemaps := detector.RobotsEntries(data)
+		for _, path := range paths {
+			entry := resolveSiteURL(root, path)
+			if entry != "" && compromiseDetector.IsGamblingURL(path) {
+				report.ListedURLs = appendListedURL(report.ListedURLs, entry, detector.ListedInRobots)
+			}
+		}
+		for _, sitemap := range robotsSitemaps {
+			sitemaps = appendUnique(sitemaps, sitemap)
+		}
+	}
+
+	for i := 0; i < len(sitemaps) && i < maxSitemaps; i++ {
+		data, err := fetchAsset(client, sitemaps[i])
+		if err != nil {
+			continue
+		}
+		pages, nested, err := detector.SitemapURLs(data)
+		if err != nil {
+			continue
+		}
+		for _, page := range pages {
+			if parsed, err := url.Parse(page); err == nil && compromiseDetector.IsGamblingURL(parsed.Path+"?"+parsed.RawQuery) {
+				report.ListedURLs = appendListedURL(report.ListedURLs, page, detector.ListedInSitemap)
+			}
+		}
+		for _, sitemap := range nested {
+			sitemaps = appendUnique(sitemaps, sitemap)
+		}
+	}
+
+	// Fetch candidate pages on the site to confirm they serve gambling content
+	var candidates []string
+	for _, path := range report.InjectedPaths {
+		candidates = appendUnique(candidates, path)
+	}
+	for _, listed := range report.ListedURLs {
+		candidates = appendUnique(candidates, listed.URL)
+	}
+	probes := 0
+	for _, candidate := range candidates {
+		if probes >= config.Get().Compromise.MaxProbes {
+			break
+		}
+		if strings.ContainsAny(candidate, "*$") || !sameSite(candidate, base) {
+			continue
+		}
+		probes++
+		data, err := fetchAsset(client, candidate)
+		if err == nil && compromiseDetector.IsGamblingContent(string(data)) {
+			report.ConfirmedPages = append(report.ConfirmedPages, candidate)
+		}
+	}
+
+	return compromiseDetector.DetectCompromise(report), report.InjectedURLs()
+}
+
+// resolveSiteURL resolves a robots.txt path against the site root
+func resolveSiteURL(root *url.URL, path string) string {
+	ref, err := url.Parse(path)
+	if err != nil {
+		return ""
+	}
+	return root.ResolveReference(ref).String()
+}
+
+// sameSite reports whether rawURL is on the host of base, ignoring www.
+func sameSite(rawURL string, base *url.URL) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.") ==
+		strings.TrimPrefix(strings.ToLower(base.Hostname()), "www.")
+}
+
+// appendListedURL appends a listed URL unless it is already present
+func appendListedURL(listed []detector.ListedURL, rawURL, source string) []detector.ListedURL {
+	for _, l := range listed {
+		if l.URL == rawURL {
+			return listed
+		}
+	}
+	return append(listed, detector.ListedURL{URL: rawURL, Source: source})
+}
+
+// appendUnique appends value to values unless it is already present
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,73 @@
+package scanner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestCompromisedHostCrawl tests injected pages found through hidden links, robots.txt and the sitemap
+func TestCompromisedHostCrawl(t *testing.T) {
+	const gamblingPage = `<html><body><h1>Slot Gacor Maxwin</h1><p>Situs slot online, RTP live dan bocoran slot.</p></body></html>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			w.Write([]byte("User-agent: *\nDisallow: /admin/\nAllow: /slot-demo/\nSitemap: /sitemap-posts.xml\n"))
+		case "/sitemap.xml":
+			w.Write([]byte(`<sitemapindex><sitemap><loc>` + "http://" + r.Host + `/sitemap-pages.xml</loc></sitemap></sitemapindex>`))
+		case "/sitemap-pages.xml":
+			w.Write([]byte(`<urlset><url><loc>http://` + r.Host + `/profil/</loc></url><url><loc>http://` + r.Host + `/slot-maxwin/</loc></url></urlset>`))
+		case "/slot-gacor/", "/slot-maxwin/":
+			w.Write([]byte(gamblingPage))
+		case "/slot-demo/":
+			http.NotFound(w, r)
+		default:
+			w.Write([]byte(`<html><body><h1>Dinas Pendidikan</h1><p>Informasi layanan publik.</p>
+				<div style="display:none"><a href="/slot-gacor/">slot gacor</a></div></body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	signals, injected := detectCompromiseSignals(server.Client(), server.URL+"/", `<html><body><h1>Dinas Pendidikan</h1>
+		<div style="display:none"><a href="/slot-gacor/">slot gacor</a></div></body></html>`)
+
+	found := make(map[string]bool)
+	for _, signal := range signals {
+		found[signal.SignalID] = true
+	}
+	for _, id := range []string{"COMPROMISE_HIDDEN_LINKS", "COMPROMISE_INJECTED_PATHS", "COMPROMISE_SITEMAP_ENTRIES", "COMPROMISE_INJECTED_PAGES"} {
+		if !found[id] {
+			t.Errorf("Expected %s signal, got %v", id, found)
+		}
+	}
+
+	expected := []string{
+		server.URL + "/slot-gacor/",
+		server.URL + "/slot-maxwin/",
+		server.URL + "/slot-demo/",
+	}
+	if len(injected) != len(expected) {
+		t.Fatalf("Expected injected URLs %v, got %v", expected, injected)
+	}
+	for i, u := range expected {
+		if injected[i] != u {
+			t.Errorf("Expected injected URL %d to be %s, got %s", i, u, injected[i])
+		}
+	}
+}
+
+// TestCompromiseCheckSuffixes tests that the check runs on institutional suffixes by default
+func TestCompromiseCheckSuffixes(t *testing.T) {
+	for domain, expected := range map[string]bool{
+		"disdik.jakarta.go.id": true,
+		"ft.ui.ac.id":          true,
+		"sman1.sch.id":         true,
+		"gacor88.xyz":          false,
+		"notgo.id":             false,
+	} {
+		if got := compromiseCheckEnabled(domain); got != expected {
+			t.Errorf("compromiseCheckEnabled(%s) = %v, expected %v", domain, got, expected)
+		}
+	}
+}
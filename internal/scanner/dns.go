@@ -0,0 +1,86 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/resolver"
+	"github.com/genesis410/fogger/internal/store"
+)
+
+// detectDNSSignals collects the DNS records of a domain through the
+// configured resolver and detects judol-typical DNS setups. Nameservers are
+// compared with those of the last saved scan of the domain, and addresses
+// are classified by the published CDN ranges.
+func detectDNSSignals(ctx context.Context, domain string, timeout time.Duration) ([]models.Signal, []models.DNSRecord, error) {
+	// Bound the whole collection, not only each query, when the server is unreachable
+	ctx, cancel := context.WithTimeout(ctx, 2*timeout)
+	defer cancel()
+
+	dnsResolver, err := scanResolver(timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	dnsDetector := detector.NewDNSDetector(dnsResolver)
+	snapshot, err := dnsDetector.CollectDNS(ctx, dnsName(domain))
+	if err != nil {
+		return nil, nil, err
+	}
+	snapshot.PreviousNS = previousNameservers(domain)
+	loadCDNRanges().ClassifyRecords(snapshot.Records)
+
+	return dnsDetector.DetectDNS(snapshot), snapshot.Records, nil
+}
+
+// scanResolver creates the resolver set by dns.server or --resolver
+func scanResolver(timeout time.Duration) (resolver.Resolver, error) {
+	return resolver.New(config.Get().DNS.Server, timeout)
+}
+
+// previousNameservers returns the nameservers stored with the last saved
+// result for domain, or nil if there is none
+func previousNameservers(domain string) []string {
+	previous := previousResult(domain)
+	if previous == nil {
+		return nil
+	}
+	return detector.DNSValues(previous.Domain.DNSRecords, "NS")
+}
+
+// previousResult returns the last saved result for domain, or nil if there
+// is none
+func previousResult(domain string) *models.AnalysisResult {
+	path, err := store.DefaultPath()
+	if err != nil {
+		return nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	db, err := store.Open(path)
+	if err != nil {
+		return nil
+	}
+	previous, ok := db.GetResult(domain)
+	if !ok {
+		return nil
+	}
+	return previous
+}
+
+// dnsName strips a scheme, path and port from a scan target
+func dnsName(domain string) string {
+	domain = strings.TrimPrefix(strings.TrimPrefix(domain, "https://"), "http://")
+	if i := strings.IndexAny(domain, "/?#"); i >= 0 {
+		domain = domain[:i]
+	}
+	if host, _, found := strings.Cut(domain, ":"); found {
+		domain = host
+	}
+	return strings.ToLower(domain)
+}
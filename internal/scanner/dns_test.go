@@ -0,0 +1,57 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/resolver/resolvertest"
+)
+
+// TestDNSModule tests record collection and signals against a local DNS server
+func TestDNSModule(t *testing.T) {
+	server, err := resolvertest.NewServer([]models.DNSRecord{
+		{Name: "gacor88.xyz", Type: "NS", TTL: 86400, Value: "ns1.afraid.org"},
+		{Name: "gacor88.xyz", Type: "SOA", TTL: 3600, Value: "ns1.afraid.org dnsadmin.afraid.org 2301011 3600 1800 604800 60"},
+		{Name: "gacor88.xyz", Type: "A", TTL: 3600, Value: "203.0.113.10"},
+		{Name: "www.gacor88.xyz", Type: "A", TTL: 30, Value: "203.0.113.11"},
+		{Name: "www.gacor88.xyz", Type: "TXT", TTL: 300, Value: "v=spf1 -all"},
+		{Name: "*.gacor88.xyz", Type: "A", TTL: 30, Value: "203.0.113.12"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start DNS server: %v", err)
+	}
+	defer server.Close()
+
+	previous := config.Get().DNS.Server
+	config.Get().DNS.Server = server.Addr
+	defer func() { config.Get().DNS.Server = previous }()
+
+	signals, records, err := detectDNSSignals(context.Background(), "www.gacor88.xyz", time.Second)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if ns := detector.DNSValues(records, "NS"); len(ns) != 1 || ns[0] != "ns1.afraid.org" {
+		t.Errorf("Expected the zone nameserver from the parent domain, got %v", ns)
+	}
+	if txt := detector.DNSValues(records, "TXT"); len(txt) != 1 {
+		t.Errorf("Expected the TXT record, got %v", records)
+	}
+
+	found := make(map[string]bool)
+	for _, signal := range signals {
+		found[signal.SignalID] = true
+		if source := signal.Evidence[0].Source; source != "udp://"+server.Addr {
+			t.Errorf("Expected evidence from udp://%s, got %q", server.Addr, source)
+		}
+	}
+	for _, id := range []string{"DNS_FREE_HOSTING", "DNS_SHORT_TTL", "DNS_WILDCARD"} {
+		if !found[id] {
+			t.Errorf("Expected %s signal, got %v", id, found)
+		}
+	}
+}
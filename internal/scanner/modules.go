@@ -0,0 +1,184 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// init registers the built-in detection modules in the order they run
+func init() {
+	body := []string{detector.InputBody}
+	headers := []string{detector.InputHeaders}
+	network := []string{detector.InputNetwork}
+
+	// CNAME chains of the host, apex and www names, matched against the CDN
+	// fingerprints; runs first so the other modules see the CDN they show
+	detector.Register(detector.NewFuncDetector("cname", "CDN", network, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		detectCNAMEChains(ctx, page)
+		return nil, nil
+	}))
+
+	detector.Register(detector.NewFuncDetector("cdn", "CDN", nil, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		return detectCDNSignals(page.CDN), nil
+	}))
+
+	detector.Register(detector.NewFuncDetector("ux_keywords", "UX", body, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		return detectGamblingUXSignals(page.Body), nil
+	}))
+
+	// Gambling keywords hidden from visitors for search engines
+	detector.Register(detector.NewFuncDetector("hidden_content", "UX", body, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		return detectHiddenContentSignals(page.Body), nil
+	}))
+
+	detector.Register(detector.NewFuncDetector("payment", "PAYMENT", body, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		return detectPaymentSignals(page.Body), nil
+	}))
+
+	detector.Register(detector.NewFuncDetector("infra_headers", "INFRA", headers, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		return detectInfrastructureSignals(page.Headers), nil
+	}))
+
+	// Operator contact channels shared across mirror domains
+	detector.Register(detector.NewFuncDetector("contacts", "INFRA", body, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		signals, resources := detectContactSignals(page.Body)
+		page.Resources = append(page.Resources, resources...)
+		return signals, nil
+	}))
+
+	// Analytics and tracker IDs for operator attribution
+	detector.Register(detector.NewFuncDetector("trackers", "INFRA", body, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		signals, resources := detectTrackerSignals(page.Body)
+		page.Resources = append(page.Resources, resources...)
+		return signals, nil
+	}))
+
+	detector.Register(detector.NewFuncDetector("game_providers", "UX", body, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		signals, providers := detectGameProviderSignals(page.Body)
+		page.GameProviders = providers
+		return signals, nil
+	}))
+
+	detector.Register(detector.NewFuncDetector("togel", "UX", body, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		return detectTogelSignals(page.Body), nil
+	}))
+
+	detector.Register(detector.NewFuncDetector("sportsbook", "UX", body, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		return detectSportsbookSignals(page.Body), nil
+	}))
+
+	detector.Register(detector.NewFuncDetector("live_casino", "UX", body, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		return detectLiveCasinoSignals(page.Body), nil
+	}))
+
+	// Illegal lending and investment scams share the same infrastructure
+	detector.Register(detector.NewFuncDetector("fraud", "UX", body, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		return detectFraudSignals(page.Body), nil
+	}))
+
+	// White-label panel the site is built on
+	detector.Register(detector.NewFuncDetector("panel", "INFRA", body, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		signals, resources, platform := detectPanelSignals(page.Body)
+		page.Resources = append(page.Resources, resources...)
+		page.Platform = platform
+		return signals, nil
+	}))
+
+	// Mirror domains the site advertises; shortlinks are kept as resources
+	// for the shortlinks module to follow
+	detector.Register(detector.NewFuncDetector("mirrors", "INFRA", body, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		signals, relations, shortlinks := detectMirrorSignals(page.Body, page.Domain)
+		page.Relations = append(page.Relations, relations...)
+		page.Resources = append(page.Resources, shortlinks...)
+		return signals, nil
+	}))
+
+	detector.Register(detector.NewFuncDetector("shortlinks", "DNS", network, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		var shortlinks []models.Resource
+		for _, resource := range page.Resources {
+			if resource.Type == detector.ResourceShortlink {
+				shortlinks = append(shortlinks, resource)
+			}
+		}
+		relations, chains := expandShortlinks(shortlinks, page.Domain, page.Timeout)
+		page.Relations = append(page.Relations, relations...)
+		page.RedirectChains = append(page.RedirectChains, chains...)
+		return nil, nil
+	}))
+
+	// Sibling domain names of a rotation set (slot88a.com, slot88b.net)
+	detector.Register(detector.NewFuncDetector("churn", "DNS", nil, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		signals, relations := detectChurnSignals(page.Domain, page.Relations)
+		page.Relations = append(page.Relations, relations...)
+		return signals, nil
+	}))
+
+	// Favicon, logo and banner hashes for visual clustering
+	detector.Register(detector.NewFuncDetector("visual", "INFRA", []string{detector.InputBody, detector.InputNetwork}, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		page.Resources = append(page.Resources, detectVisualResources(page.Client, page.URL, page.Body)...)
+		return nil, nil
+	}))
+
+	// DNS records: nameserver changes, free DNS hosting, short TTLs, wildcards
+	detector.Register(detector.NewFuncDetector("dns", "DNS", network, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		signals, records, err := detectDNSSignals(ctx, page.Domain, page.Timeout)
+		page.DNSRecords = append(page.DNSRecords, records...)
+		return signals, err
+	}))
+
+	// Registration age, registrar and privacy service from RDAP or WHOIS
+	detector.Register(detector.NewFuncDetector("registration", "DNS", network, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		signals, resources, registration, err := detectRegistrationSignals(ctx, page.Domain, page.Timeout)
+		page.Resources = append(page.Resources, resources...)
+		page.Registration = registration
+		return signals, err
+	}))
+
+	// Whether ISPs already block the domain (Internet Positif / TrustPositif)
+	detector.Register(detector.NewFuncDetector("block_status", "DNS", network, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		if !config.Get().BlockCheck.Enabled {
+			return nil, nil
+		}
+		status, err := checkBlockStatus(ctx, page.Domain, page.Timeout)
+		page.BlockStatus = status
+		return nil, err
+	}))
+
+	// Judol pages injected into legitimate (government, campus) sites
+	detector.Register(detector.NewFuncDetector("compromise", "INFRA", []string{detector.InputBody, detector.InputNetwork}, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		if !compromiseCheckEnabled(page.Domain) {
+			return nil, nil
+		}
+		signals, injectedURLs := detectCompromiseSignals(page.Client, page.URL, page.Body)
+		page.InjectedURLs = append(page.InjectedURLs, injectedURLs...)
+		return signals, nil
+	}))
+
+	// Subdomains from a wordlist, CT logs and page links, with their CDN status
+	detector.Register(detector.NewFuncDetector("subdomains", "INFRA", network, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		subdomains, err := enumerateSubdomains(ctx, page.Domain, page.Body, page.Timeout)
+		page.Subdomains = subdomains
+		return nil, err
+	}))
+
+	detector.Register(detector.NewFuncDetector("origin_ip", "INFRA", network, func(ctx context.Context, page *detector.Page) ([]models.Signal, error) {
+		originIPs, originEvidence, err := detectOriginIPs(page.Domain, page.Timeout, page.Subdomains)
+		if err != nil || len(originIPs) == 0 {
+			return nil, err
+		}
+		// Only add one signal to avoid spamming
+		return []models.Signal{
+			{
+				SignalID:    "origin_ip_detected",
+				Category:    "INFRA",
+				Description: fmt.Sprintf("Potential origin IP detected behind CDN: %s", originIPs[0]),
+				Confidence:  0.8,
+				Evidence:    originEvidence,
+			},
+		}, nil
+	}))
+}
@@ -0,0 +1,48 @@
+package scanner
+
+import (
+	"context"
+	"time"
+
+	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/rdap"
+	"github.com/genesis410/fogger/internal/store"
+)
+
+// detectRegistrationSignals looks up the registration of a domain over RDAP
+// or WHOIS and detects judol-typical registrations. Registrations saved to
+// the store with a result are reused for rdap.cache_hours.
+func detectRegistrationSignals(ctx context.Context, domain string, timeout time.Duration) ([]models.Signal, []models.Resource, *models.Registration, error) {
+	registration, err := lookupRegistration(ctx, dnsName(domain), timeout)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	registrationDetector := detector.NewRegistrationDetector()
+	registrationDetector.RiskyRegistrars = append(registrationDetector.RiskyRegistrars, config.Get().RDAP.RiskyRegistrars...)
+	signals, resources := registrationDetector.DetectRegistration(registration, time.Now())
+	return signals, resources, registration, nil
+}
+
+// lookupRegistration returns the registration of the domain a host is
+// registered under, from the store cache when it is fresh enough. The cache
+// is only read here; the commands that save results fill it.
+func lookupRegistration(ctx context.Context, host string, timeout time.Duration) (*models.Registration, error) {
+	cfg := config.Get().RDAP
+	domain, err := rdap.RegistrableDomain(host)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cache is best-effort; lookups still work without a store
+	if db, err := store.OpenDefault(); err == nil {
+		cached, ok := db.GetRegistration(domain)
+		if ok && time.Since(cached.FetchedAt) < time.Duration(cfg.CacheHours)*time.Hour {
+			return cached, nil
+		}
+	}
+
+	return rdap.New(cfg.BaseURL, cfg.WhoisServer, timeout).Lookup(ctx, domain)
+}
@@ -0,0 +1,65 @@
+package scanner
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/models"
+	"github.com/genesis410/fogger/internal/rdap/rdaptest"
+	"github.com/genesis410/fogger/internal/store"
+)
+
+// TestRegistrationModuleCaches tests registration signals against a local
+// RDAP server and that a lookup saved to the store is not repeated
+func TestRegistrationModuleCaches(t *testing.T) {
+	created := time.Now().AddDate(0, 0, -5)
+	server := rdaptest.NewServer(map[string]*models.Registration{
+		"gacor88.xyz": {Registrar: "NameSilo, LLC", CreatedAt: &created},
+	})
+	defer server.Close()
+
+	cfg := config.Get()
+	previous := cfg.RDAP
+	previousStore := cfg.Store.Path
+	cfg.RDAP.BaseURL = server.URL
+	cfg.Store.Path = filepath.Join(t.TempDir(), "store.json")
+	defer func() {
+		cfg.RDAP = previous
+		cfg.Store.Path = previousStore
+	}()
+
+	for i := 0; i < 2; i++ {
+		signals, resources, registration, err := detectRegistrationSignals(context.Background(), "www.gacor88.xyz", time.Second)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if registration.Domain != "gacor88.xyz" || registration.Registrar != "NameSilo, LLC" {
+			t.Errorf("Expected the registration of gacor88.xyz, got %+v", registration)
+		}
+		if len(signals) != 2 || len(resources) != 2 {
+			t.Errorf("Expected new domain and registrar signals with 2 resources, got %v and %v", signals, resources)
+		}
+
+		// The module only reads the cache; saving a scan fills it
+		db, err := store.Open(cfg.Store.Path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if i == 0 {
+			if _, ok := db.GetRegistration("gacor88.xyz"); ok {
+				t.Error("Expected the module not to write the store")
+			}
+		}
+		db.PutRegistration(registration)
+		if err := db.Save(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if requests := server.Requests(); len(requests) != 1 {
+		t.Errorf("Expected one RDAP request and a cached second lookup, got %v", requests)
+	}
+}
@@ -1,27 +1,44 @@
 package scanner
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/genesis410/fogger/internal/config"
 	"github.com/genesis410/fogger/internal/detector"
 	"github.com/genesis410/fogger/internal/models"
 )
 
 // ScanResult holds the result of a domain scan
 type ScanResult struct {
-	Domain      string
-	CDNProvider string
-	Signals     []models.Signal
-	StatusCode  int
-	Headers     http.Header
-	Body        string
+	Domain         string
+	CDNProvider    string
+	CDNMatches     []models.CDNMatch
+	CNAMEChains    []models.CNAMEChain
+	Signals        []models.Signal
+	StatusCode     int
+	Headers        http.Header
+	Body           string
+	Resources      []models.Resource
+	GameProviders  []string
+	Platform       string
+	Relations      []models.Relation
+	RedirectChains []models.RedirectChain
+	Signature      *models.PageSignature
+	Modules        []models.ModuleRun
+	InjectedURLs   []string
+	DNSRecords     []models.DNSRecord
+	BlockStatus    *models.BlockStatus
+	Registration   *models.Registration
+	Subdomains     []models.Subdomain
 }
 
 // ScanDomain performs a scan of the given domain
@@ -45,14 +62,31 @@ func ScanDomain(domain string, timeout time.Duration) *ScanResult {
 		url = "https://" + url
 	}
 
-	// Make request
-	resp, err := client.Get(url)
+	// Make request, noting the address that served the page
+	var remoteAddr string
+	ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			remoteAddr = info.Conn.RemoteAddr().String()
+		},
+	})
+	resp, err := getWithContext(ctx, client, url)
 	if err != nil {
 		// If HTTPS fails, try HTTP
 		url = strings.Replace(url, "https://", "http://", 1)
-		resp, err = client.Get(url)
+		resp, err = getWithContext(ctx, client, url)
 		if err != nil {
 			fmt.Printf("Error connecting to %s: %v\n", domain, err)
+			// Sites an ISP just blocked are often unreachable: the modules
+			// that only need the network still run, the others are skipped
+			cdn := loadCDNEngine().Identify(detector.CDNObservation{})
+			runModules(result, &detector.Page{
+				Domain:      domain,
+				URL:         url,
+				CDNProvider: cdn.Name,
+				CDN:         cdn,
+				Client:      client,
+				Timeout:     timeout,
+			})
 			return result
 		}
 	}
@@ -67,84 +101,147 @@ func ScanDomain(domain string, timeout time.Duration) *ScanResult {
 	result.StatusCode = resp.StatusCode
 	result.Headers = resp.Header
 	result.Body = string(body)
-
-	// Detect CDN
-	result.CDNProvider = detectCDN(resp.Header)
-
-	// Add signals based on analysis
-	result.Signals = append(result.Signals, detectCDNSignals(result.CDNProvider)...)
-	result.Signals = append(result.Signals, detectGamblingUXSignals(result.Body)...)
-	result.Signals = append(result.Signals, detectPaymentSignals(result.Body)...)
-	result.Signals = append(result.Signals, detectInfrastructureSignals(resp.Header)...)
-
-	// Try to detect origin IPs behind CDN
-	originIPs, originEvidence, err := detectOriginIPs(domain)
-	if err == nil && len(originIPs) > 0 {
-		// Add signals for detected origin IPs
-		for _, ip := range originIPs {
-			signal := models.Signal{
-				SignalID:    "origin_ip_detected",
-				Category:    "INFRA",
-				Description: fmt.Sprintf("Potential origin IP detected behind CDN: %s", ip),
-				Confidence:  0.8,
-				Evidence:    originEvidence,
-			}
-			result.Signals = append(result.Signals, signal)
-			break // Only add one to avoid spamming
-		}
-	}
+	result.Signature = detector.ComputePageSignature(result.Body)
+
+	// Detect CDN from the response; the cname module adds the CNAME chains
+	obs := cdnObservation(resp, result.Body, remoteAddr)
+	cdn := loadCDNEngine().Identify(obs)
+
+	runModules(result, &detector.Page{
+		Domain:         domain,
+		URL:            resp.Request.URL.String(),
+		StatusCode:     resp.StatusCode,
+		Headers:        resp.Header,
+		Body:           result.Body,
+		CDNProvider:    cdn.Name,
+		CDN:            cdn,
+		CDNObservation: obs,
+		Client:         client,
+		Timeout:        timeout,
+	})
 
 	return result
 }
 
-// detectCDN detects which CDN is being used
-func detectCDN(headers http.Header) string {
-	// Check for Cloudflare headers
-	if headers.Get("server") == "cloudflare" ||
-		headers.Get("cf-ray") != "" ||
-		headers.Get("cf-request-id") != "" {
-		return "cloudflare"
+// runModules runs the enabled detection modules against a page and records
+// what they found in result
+func runModules(result *ScanResult, page *detector.Page) {
+	modules, err := detector.DefaultRegistry.Select(config.Get().Modules.Enabled, config.Get().Modules.Skip)
+	if err != nil {
+		fmt.Printf("Error selecting modules, running all: %v\n", err)
+		modules = detector.DefaultRegistry
 	}
+	signals, runs := modules.Run(context.Background(), page)
+
+	result.CDNProvider = page.CDN.Name
+	result.CDNMatches = page.CDN.Matches
+	result.CNAMEChains = page.CDN.CNAMEChains
+	result.Signals = append(result.Signals, signals...)
+	result.Modules = runs
+	result.Resources = page.Resources
+	result.GameProviders = page.GameProviders
+	result.Platform = page.Platform
+	result.Relations = page.Relations
+	result.RedirectChains = page.RedirectChains
+	result.InjectedURLs = page.InjectedURLs
+	result.DNSRecords = page.DNSRecords
+	result.BlockStatus = page.BlockStatus
+	result.Registration = page.Registration
+	result.Subdomains = page.Subdomains
+}
 
-	// Check for other common CDN headers
-	if headers.Get("x-cache") != "" && strings.Contains(headers.Get("x-cache"), "cloudfront") {
-		return "cloudfront"
+// getWithContext fetches url with a request bound to ctx
+func getWithContext(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
 	}
+	return client.Do(req)
+}
 
-	if headers.Get("server") == "AkamaiGHost" ||
-		headers.Get("x-akamai-transformed") != "" {
-		return "akamai"
+// cdnObservation collects what identifies the CDN serving a page: its
+// response and the address that served it
+func cdnObservation(resp *http.Response, body, remoteAddr string) detector.CDNObservation {
+	obs := detector.CDNObservation{
+		Headers: resp.Header,
+		TLS:     resp.TLS,
+		Body:    body,
+	}
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		obs.Addresses = []string{host}
 	}
+	return obs
+}
 
-	// Check for other CDNs
-	if headers.Get("x-served-by") != "" || strings.Contains(headers.Get("via"), "fastly") {
-		return "fastly"
+// detectCNAMEChains follows the CNAME chains of the page's names and, when
+// there are any, identifies the CDN again with them
+func detectCNAMEChains(ctx context.Context, page *detector.Page) {
+	chains := cnameChains(ctx, page.Domain, page.Timeout)
+	if len(chains) == 0 {
+		return
 	}
+	page.CDNObservation.CNAMEChains = chains
+	page.CDN = loadCDNEngine().Identify(page.CDNObservation)
+	page.CDNProvider = page.CDN.Name
+}
 
-	if strings.Contains(headers.Get("server"), "Netlify") || headers.Get("x-nf-request-id") != "" {
-		return "netlify"
+// cnameChains follows the CNAME chains of the scanned host and of the apex
+// and www names of the domain it is registered under. Names without a
+// CNAME record are left out.
+func cnameChains(ctx context.Context, domain string, timeout time.Duration) []models.CNAMEChain {
+	host := dnsName(domain)
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	r, err := scanResolver(timeout)
+	if err != nil {
+		return nil
+	}
+	names := []string{host}
+	if name, err := detector.ParseDomainName(host); err == nil {
+		for _, apexName := range []string{name.Domain, "www." + name.Domain} {
+			if apexName != host {
+				names = append(names, apexName)
+			}
+		}
 	}
 
-	return "none"
+	// Bound all lookups, not only each query, when the server is unreachable
+	ctx, cancel := context.WithTimeout(ctx, 2*timeout)
+	defer cancel()
+	var chains []models.CNAMEChain
+	for _, name := range names {
+		// A chain cut short by an error or a loop still shows where it leads
+		chain, _ := detector.FollowCNAMEChain(ctx, r, name)
+		if len(chain.Targets) > 0 {
+			chains = append(chains, chain)
+		}
+	}
+	return chains
 }
 
 // detectCDNSignals returns signals related to CDN usage
-func detectCDNSignals(cdnProvider string) []models.Signal {
+func detectCDNSignals(cdn *detector.CDNInfo) []models.Signal {
 	signals := []models.Signal{}
 
-	if cdnProvider == "cloudflare" {
+	if cdn != nil && cdn.Name == "cloudflare" {
+		var evidence []models.Evidence
+		for _, match := range cdn.Matches {
+			if match.Provider != cdn.Name {
+				continue
+			}
+			evidence = append(evidence, models.Evidence{
+				Type:      match.Kind,
+				Reference: match.Reference,
+				Timestamp: time.Now(),
+			})
+		}
 		signal := models.Signal{
 			SignalID:    "cdn_cloudflare",
 			Category:    "CDN",
 			Description: "Domain is protected by Cloudflare CDN",
 			Confidence:  0.2,
-			Evidence: []models.Evidence{
-				{
-					Type:      "header",
-					Reference: "server: cloudflare",
-					Timestamp: time.Now(),
-				},
-			},
+			Evidence:    evidence,
 		}
 		signals = append(signals, signal)
 	}
@@ -195,6 +292,12 @@ func detectGamblingUXSignals(body string) []models.Signal {
 	return signals
 }
 
+// detectHiddenContentSignals detects hidden gambling text and keyword stuffing
+func detectHiddenContentSignals(body string) []models.Signal {
+	hiddenContentDetector := detector.NewHiddenContentDetector()
+	return hiddenContentDetector.DetectHiddenContent(body)
+}
+
 // detectPaymentSignals detects payment-related signals
 func detectPaymentSignals(body string) []models.Signal {
 	// Use the payment detector for comprehensive payment method detection
@@ -316,9 +419,222 @@ func detectInfrastructureSignals(headers http.Header) []models.Signal {
 	return signals
 }
 
-// detectOriginIPs attempts to find origin IPs behind CDN
-func detectOriginIPs(domain string) ([]string, []models.Evidence, error) {
+// detectContactSignals detects CS contact channels and returns them as shared resources
+func detectContactSignals(body string) ([]models.Signal, []models.Resource) {
+	contactDetector := detector.NewContactDetector()
+	return contactDetector.DetectContactChannels(body), contactDetector.ExtractContacts(body)
+}
+
+// detectTrackerSignals detects analytics and tracker IDs and returns them as shared resources
+func detectTrackerSignals(body string) ([]models.Signal, []models.Resource) {
+	trackerDetector := detector.NewTrackerDetector()
+	return trackerDetector.DetectTrackers(body), trackerDetector.ExtractTrackers(body)
+}
+
+// detectGameProviderSignals detects slot game providers embedded in the page
+func detectGameProviderSignals(body string) ([]models.Signal, []string) {
+	gameDetector, err := detector.NewGameProviderDetector(config.Get().Catalogs.GameProviders)
+	if err != nil {
+		fmt.Printf("Error loading game provider catalog, using built-in catalog: %v\n", err)
+		gameDetector, _ = detector.NewGameProviderDetector("")
+	}
+	return gameDetector.DetectGameProviders(body), gameDetector.GameProviderNames(body)
+}
+
+// detectTogelSignals detects togel result tables, markets, predictions and bet types
+func detectTogelSignals(body string) []models.Signal {
+	togelDetector := detector.NewTogelDetector()
+	return togelDetector.DetectTogel(body)
+}
+
+// detectSportsbookSignals detects odds tables, match lists, bet types and sportsbook brands
+func detectSportsbookSignals(body string) []models.Signal {
+	sportsbookDetector := detector.NewSportsbookDetector()
+	return sportsbookDetector.DetectSportsbook(body)
+}
+
+// detectLiveCasinoSignals detects live casino games, studios and lobbies
+func detectLiveCasinoSignals(body string) []models.Signal {
+	liveCasinoDetector := detector.NewLiveCasinoDetector()
+	return liveCasinoDetector.DetectLiveCasino(body)
+}
+
+// detectFraudSignals detects illegal lending and investment scam content
+func detectFraudSignals(body string) []models.Signal {
+	fraudDetector, err := detector.NewFraudDetector(config.Get().Catalogs.FraudRules)
+	if err != nil {
+		fmt.Printf("Error loading fraud rule pack, using built-in rule pack: %v\n", err)
+		fraudDetector, _ = detector.NewFraudDetector("")
+	}
+	return fraudDetector.DetectFraud(body)
+}
+
+// detectPanelSignals fingerprints the page structure and matches it against known panels
+func detectPanelSignals(body string) ([]models.Signal, []models.Resource, string) {
+	panelDetector, err := detector.NewPanelDetector(config.Get().Catalogs.Panels)
+	if err != nil {
+		fmt.Printf("Error loading panel catalog, using built-in catalog: %v\n", err)
+		panelDetector, _ = detector.NewPanelDetector("")
+	}
+	panel := panelDetector.FingerprintPanel(body)
+	return panelDetector.DetectPanelSignals(panel), panelDetector.ExtractPanelResources(panel), panel.Platform
+}
+
+// detectMirrorSignals detects advertised mirror domains and records them as
+// MIRROR_OF relations. Shortlinks are returned as resources since their
+// destination is not known without following them.
+func detectMirrorSignals(body, domain string) ([]models.Signal, []models.Relation, []models.Resource) {
+	mirrorDetector := detector.NewMirrorDetector()
+	links := mirrorDetector.ExtractMirrors(body, domain)
+
+	var relations []models.Relation
+	var shortlinks []models.Resource
+	for _, link := range links {
+		if link.Source == detector.MirrorSourceShortlink {
+			shortlinks = append(shortlinks, models.Resource{Type: detector.ResourceShortlink, Value: link.Domain})
+			continue
+		}
+		relations = append(relations, models.Relation{
+			Source: domain,
+			Target: link.Domain,
+			Type:   models.RelationMirrorOf,
+			Evidence: []models.Evidence{
+				{
+					Type:      "html",
+					Reference: "Linked as " + link.Source + ": " + link.URL,
+					Timestamp: time.Now(),
+				},
+			},
+		})
+	}
+
+	return mirrorDetector.DetectMirrorSignals(links), relations, shortlinks
+}
+
+// expandShortlinks resolves the shortlinks found on a page and records their
+// destinations as MIRROR_OF relations
+func expandShortlinks(shortlinks []models.Resource, domain string, timeout time.Duration) ([]models.Relation, []models.RedirectChain) {
+	var relations []models.Relation
+	var chains []models.RedirectChain
+
+	expander := NewShortlinkExpander(timeout)
+	for _, shortlink := range shortlinks {
+		if !expander.IsShortlink(shortlink.Value) {
+			continue
+		}
+
+		// Link-in-bio pages list several destinations
+		var expanded []models.RedirectChain
+		if expander.IsLinkPage(shortlink.Value) {
+			linkChains, err := expander.ExpandLinks(shortlink.Value)
+			if err != nil {
+				fmt.Printf("Error expanding link page %s: %v\n", shortlink.Value, err)
+				continue
+			}
+			expanded = linkChains
+		} else {
+			chain, err := expander.Expand(shortlink.Value)
+			if err != nil {
+				fmt.Printf("Error expanding shortlink %s: %v\n", shortlink.Value, err)
+				continue
+			}
+			expanded = append(expanded, *chain)
+		}
+
+		for _, chain := range expanded {
+			chains = append(chains, chain)
+
+			target := shortlinkHost(chain.Destination)
+			if target == "" || target == strings.TrimPrefix(domain, "www.") {
+				continue
+			}
+			relations = append(relations, models.Relation{
+				Source: domain,
+				Target: target,
+				Type:   models.RelationMirrorOf,
+				Evidence: []models.Evidence{
+					{
+						Type:      "html",
+						Reference: "Linked via shortlink " + chain.URL + " -> " + chain.Destination,
+						Timestamp: time.Now(),
+					},
+				},
+			})
+		}
+	}
+
+	return relations, chains
+}
+
+// detectVisualResources fetches the favicon and the top images of a page and
+// returns their hashes. Game thumbnails served by provider CDNs are skipped
+// since every site embedding the same games shares them.
+func detectVisualResources(client *http.Client, pageURL, body string) []models.Resource {
+	var resources []models.Resource
+
+	visualDetector := detector.NewVisualDetector()
+	favicon, images := visualDetector.ExtractImageURLs(body, pageURL, config.Get().Images.MaxImages*2)
+
+	if data, err := fetchAsset(client, favicon); err == nil {
+		resources = append(resources, visualDetector.FaviconResources(data)...)
+	}
+
+	gameDetector, err := detector.NewGameProviderDetector(config.Get().Catalogs.GameProviders)
+	if err != nil {
+		gameDetector, _ = detector.NewGameProviderDetector("")
+	}
+
+	hashed := 0
+	for _, imageURL := range images {
+		if hashed >= config.Get().Images.MaxImages {
+			break
+		}
+		if gameDetector.IsProviderAsset(imageURL) {
+			continue
+		}
+
+		data, err := fetchAsset(client, imageURL)
+		if err != nil {
+			continue
+		}
+		imageResources, err := visualDetector.ImageResources(data)
+		if err != nil || len(imageResources) == 0 {
+			continue
+		}
+		resources = append(resources, imageResources...)
+		hashed++
+	}
+
+	return resources
+}
+
+// fetchAsset downloads a page asset of at most 2 MB
+func fetchAsset(client *http.Client, assetURL string) ([]byte, error) {
+	resp, err := client.Get(assetURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d fetching %s", resp.StatusCode, assetURL)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+}
+
+// detectOriginIPs attempts to find origin IPs behind CDN. Subdomains come
+// from the subdomains module, or are enumerated when it did not run.
+func detectOriginIPs(domain string, timeout time.Duration, subdomains []models.Subdomain) ([]string, []models.Evidence, error) {
 	detector := detector.NewOriginIPDetector()
+	detector.Subdomains = subdomains
+	detector.Ranges = loadCDNRanges()
+	detector.Engine = loadCDNEngine()
+	originResolver, err := scanResolver(timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	detector.Resolver = originResolver
 	return detector.DetectOriginIPs(domain)
 }
 
@@ -0,0 +1,30 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/genesis410/fogger/internal/config"
+)
+
+// TestScanUnreachableRunsModules tests that an unreachable site still runs
+// the modules that do not need its page
+func TestScanUnreachableRunsModules(t *testing.T) {
+	previous := config.Get().Modules.Enabled
+	config.Get().Modules.Enabled = []string{"cdn", "ux_keywords"}
+	defer func() { config.Get().Modules.Enabled = previous }()
+
+	result := ScanDomain("127.0.0.1:1", time.Second)
+	if len(result.Modules) != 2 {
+		t.Fatalf("Expected both modules to be recorded, got %+v", result.Modules)
+	}
+	if cdn := result.Modules[0]; cdn.Name != "cdn" || cdn.Skipped {
+		t.Errorf("Expected the cdn module to run, got %+v", cdn)
+	}
+	if ux := result.Modules[1]; ux.Name != "ux_keywords" || !ux.Skipped || ux.Error != "missing input: body" {
+		t.Errorf("Expected the body module to be skipped, got %+v", ux)
+	}
+	if result.CDNProvider != "none" {
+		t.Errorf("Expected no CDN for an unreachable site, got %q", result.CDNProvider)
+	}
+}
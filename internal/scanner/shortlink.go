@@ -0,0 +1,282 @@
+package scanner
+
+import (
+	"crypto/tls"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// ShortlinkRule describes how to resolve links of one URL shortener
+type ShortlinkRule struct {
+	// Host is the shortener hostname, e.g. "s.id"
+	Host string
+	// Method is the request method: HEAD when the shortener answers with a
+	// plain redirect, GET when it usually serves a page
+	Method string
+	// Interstitial extracts the destination from a "continue to site" page.
+	// The first capture group must hold the URL.
+	Interstitial *regexp.Regexp
+	// Links marks a link-in-bio page, which lists several outbound links
+	// instead of redirecting. The first capture group must hold a URL.
+	Links *regexp.Regexp
+}
+
+// ShortlinkExpander resolves shortener URLs hop by hop without rendering pages
+type ShortlinkExpander struct {
+	Client  *http.Client
+	Rules   map[string]ShortlinkRule
+	MaxHops int
+}
+
+// interstitialRegex matches the redirect styles used by interstitial pages
+var interstitialRegex = regexp.MustCompile(`(?is)<meta[^>]+http-equiv=["']?refresh["']?[^>]+content=["'][^"']*url=([^"'>\s]+)|(?:window\.|document\.)?location(?:\.href)?\s*=\s*["']([^"']+)["']|location\.replace\(\s*["']([^"']+)["']`)
+
+// linkPageRegex matches the outbound links of link-in-bio pages, both as
+// anchors and in the JSON state of pages rendered client-side
+var linkPageRegex = regexp.MustCompile(`(?i)(?:href=|"url"\s*:\s*)["'](https?:(?:\\?/){2}[^"'\s<>]+)["']`)
+
+// shortlinkRule returns the rule resolving links of a catalog shortener
+func shortlinkRule(shortener detector.Shortener) ShortlinkRule {
+	rule := ShortlinkRule{Host: shortener.Host, Method: http.MethodGet}
+	switch shortener.Kind {
+	case detector.ShortenerRedirect:
+		rule.Method = http.MethodHead
+	case detector.ShortenerInterstitial:
+		rule.Interstitial = interstitialRegex
+	case detector.ShortenerLinkPage:
+		rule.Links = linkPageRegex
+	}
+	return rule
+}
+
+// NewShortlinkExpander creates a new shortlink expander with a rule for
+// every shortener of the detector.Shorteners catalog
+func NewShortlinkExpander(timeout time.Duration) *ShortlinkExpander {
+	se := &ShortlinkExpander{
+		Client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+			// Redirects are followed manually so every hop is recorded
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		Rules:   make(map[string]ShortlinkRule),
+		MaxHops: 10,
+	}
+
+	for _, shortener := range detector.Shorteners {
+		se.AddRule(shortlinkRule(shortener))
+	}
+
+	return se
+}
+
+// AddRule adds or replaces the rule for a shortener host of this expander.
+// Hosts whose links on a page should also be collected as shortlinks
+// belong in the detector.Shorteners catalog.
+func (se *ShortlinkExpander) AddRule(rule ShortlinkRule) {
+	if rule.Method == "" {
+		rule.Method = http.MethodGet
+	}
+	se.Rules[strings.ToLower(rule.Host)] = rule
+}
+
+// IsShortlink reports whether rawURL points to a known shortener
+func (se *ShortlinkExpander) IsShortlink(rawURL string) bool {
+	_, ok := se.Rules[shortlinkHost(rawURL)]
+	return ok
+}
+
+// Expand follows a shortlink until it leaves the known shorteners. Every
+// intermediate URL is recorded in Hops; Destination is the first URL that is
+// not handled by a shortener rule.
+func (se *ShortlinkExpander) Expand(rawURL string) (*models.RedirectChain, error) {
+	current := withScheme(rawURL)
+	expansion := &models.RedirectChain{URL: current}
+
+	for hop := 0; hop < se.MaxHops; hop++ {
+		rule, ok := se.Rules[shortlinkHost(current)]
+		if !ok {
+			expansion.Destination = current
+			return expansion, nil
+		}
+		if rule.Links != nil {
+			return expansion, fmt.Errorf("%s is a link page, not a redirect", current)
+		}
+		expansion.Hops = append(expansion.Hops, current)
+
+		next, err := se.nextHop(current, rule)
+		if err != nil {
+			return expansion, err
+		}
+		if next == "" {
+			return expansion, fmt.Errorf("no redirect found at %s", current)
+		}
+		current = next
+	}
+
+	return expansion, fmt.Errorf("too many hops expanding %s", rawURL)
+}
+
+// IsLinkPage reports whether rawURL points to a known link-in-bio host
+func (se *ShortlinkExpander) IsLinkPage(rawURL string) bool {
+	rule, ok := se.Rules[shortlinkHost(rawURL)]
+	return ok && rule.Links != nil
+}
+
+// ExpandLinks returns one chain per outbound link of a link-in-bio page.
+// Links that are shortlinks themselves are expanded; links back to the link
+// host and to social or contact platforms are skipped.
+func (se *ShortlinkExpander) ExpandLinks(rawURL string) ([]models.RedirectChain, error) {
+	current := withScheme(rawURL)
+	pageHost := shortlinkHost(current)
+	rule, ok := se.Rules[pageHost]
+	if !ok || rule.Links == nil {
+		return nil, fmt.Errorf("%s is not a link page", rawURL)
+	}
+
+	var body []byte
+	for hop := 0; body == nil; hop++ {
+		if hop == se.MaxHops {
+			return nil, fmt.Errorf("too many redirects fetching %s", rawURL)
+		}
+		resp, err := se.do(http.MethodGet, current)
+		if err != nil {
+			return nil, err
+		}
+		location := resp.Header.Get("Location")
+		switch {
+		case resp.StatusCode >= 300 && resp.StatusCode < 400 && location != "":
+			current = resolveReference(current, location)
+		case resp.StatusCode != http.StatusOK:
+			resp.Body.Close()
+			return nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, current)
+		default:
+			body, err = io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+			if err != nil {
+				resp.Body.Close()
+				return nil, err
+			}
+		}
+		resp.Body.Close()
+	}
+
+	mirrors := detector.NewMirrorDetector()
+	seen := make(map[string]bool)
+	var chains []models.RedirectChain
+	for _, m := range rule.Links.FindAllStringSubmatch(string(body), -1) {
+		link := html.UnescapeString(strings.ReplaceAll(m[1], `\/`, "/"))
+		host := shortlinkHost(link)
+		if host == "" || host == pageHost || mirrors.IsIgnoredHost(host) || seen[link] {
+			continue
+		}
+		seen[link] = true
+
+		chain := models.RedirectChain{URL: withScheme(rawURL), Hops: []string{current}, Destination: link}
+		if se.IsShortlink(link) && !se.IsLinkPage(link) {
+			expansion, err := se.Expand(link)
+			if err != nil {
+				continue
+			}
+			chain.Hops = append(chain.Hops, expansion.Hops...)
+			chain.Destination = expansion.Destination
+		}
+		chains = append(chains, chain)
+	}
+
+	return chains, nil
+}
+
+// nextHop requests one URL and returns the URL it points to
+func (se *ShortlinkExpander) nextHop(current string, rule ShortlinkRule) (string, error) {
+	resp, err := se.do(rule.Method, current)
+	if err != nil {
+		return "", err
+	}
+
+	// Some shorteners reject HEAD or answer it with an interstitial page;
+	// retry the hop with GET when HEAD did not redirect
+	if rule.Method == http.MethodHead && (resp.StatusCode < 300 || resp.StatusCode >= 400) {
+		resp.Body.Close()
+		resp, err = se.do(http.MethodGet, current)
+		if err != nil {
+			return "", err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		if location := resp.Header.Get("Location"); location != "" {
+			return resolveReference(current, location), nil
+		}
+	}
+
+	if rule.Interstitial != nil && resp.StatusCode == http.StatusOK {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		if err != nil {
+			return "", err
+		}
+		if m := rule.Interstitial.FindStringSubmatch(string(body)); m != nil {
+			for _, group := range m[1:] {
+				if group != "" {
+					return resolveReference(current, group), nil
+				}
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// do sends the request for one hop
+func (se *ShortlinkExpander) do(method, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequest(method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; fogger)")
+	return se.Client.Do(req)
+}
+
+// resolveReference resolves a possibly relative redirect target against base
+func resolveReference(base, ref string) string {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	refURL, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return ref
+	}
+	return baseURL.ResolveReference(refURL).String()
+}
+
+// shortlinkHost returns the lowercase hostname of a URL with or without scheme
+func shortlinkHost(rawURL string) string {
+	parsed, err := url.Parse(withScheme(rawURL))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+// withScheme prefixes https:// to URLs given without a scheme
+func withScheme(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if strings.HasPrefix(rawURL, "http://") || strings.HasPrefix(rawURL, "https://") {
+		return rawURL
+	}
+	return "https://" + strings.TrimPrefix(rawURL, "//")
+}
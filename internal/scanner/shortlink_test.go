@@ -0,0 +1,140 @@
+package scanner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/genesis410/fogger/internal/detector"
+)
+
+// TestShortlinkExpansion tests hop-by-hop expansion through redirects and an interstitial page
+func TestShortlinkExpansion(t *testing.T) {
+	destination := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body>Slot Gacor</body></html>"))
+	}))
+	defer destination.Close()
+
+	// The destination is reached through "localhost" so it does not match the
+	// shortener rule registered for 127.0.0.1
+	destinationURL := strings.Replace(destination.URL, "127.0.0.1", "localhost", 1) + "/daftar"
+
+	var methods []string
+	shortener := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/gacor88":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			http.Redirect(w, r, "/continue?id=gacor88", http.StatusFound)
+		case "/continue":
+			w.Write([]byte(`<html><head><meta http-equiv="refresh" content="3;url=` + destinationURL + `"></head></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer shortener.Close()
+
+	expander := NewShortlinkExpander(0)
+	expander.AddRule(ShortlinkRule{
+		Host:         "127.0.0.1",
+		Method:       http.MethodHead,
+		Interstitial: regexp.MustCompile(`url=([^"]+)"`),
+	})
+
+	if !expander.IsShortlink(shortener.URL + "/gacor88") {
+		t.Fatalf("Expected %s to be recognized as a shortlink", shortener.URL)
+	}
+
+	chain, err := expander.Expand(shortener.URL + "/gacor88")
+	if err != nil {
+		t.Fatalf("Expected shortlink to expand, got error: %v", err)
+	}
+
+	if chain.Destination != destinationURL {
+		t.Errorf("Expected destination %s, got %s", destinationURL, chain.Destination)
+	}
+
+	expectedHops := []string{shortener.URL + "/gacor88", shortener.URL + "/continue?id=gacor88"}
+	if strings.Join(chain.Hops, " ") != strings.Join(expectedHops, " ") {
+		t.Errorf("Expected hops %v, got %v", expectedHops, chain.Hops)
+	}
+
+	// HEAD is rejected, so the first hop must be retried with GET
+	expectedMethods := []string{"HEAD /gacor88", "GET /gacor88", "HEAD /continue", "GET /continue"}
+	if strings.Join(methods, ",") != strings.Join(expectedMethods, ",") {
+		t.Errorf("Expected requests %v, got %v", expectedMethods, methods)
+	}
+
+	if _, err := expander.Expand(shortener.URL + "/unknown"); err == nil {
+		t.Error("Expected an error for a shortlink without a destination")
+	}
+}
+
+// TestExpandLinkPage tests that a link-in-bio page yields one chain per
+// outbound site and is not treated as a redirect
+func TestExpandLinkPage(t *testing.T) {
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gacor88" {
+			http.Redirect(w, r, "/gacor88", http.StatusMovedPermanently)
+			return
+		}
+		w.Write([]byte(`<html><body>
+			<a href="https://gacor88b.net/daftar">Link Alternatif</a>
+			<a href="https://wa.me/6281234567890">CS</a>
+			<a href="https://instagram.com/gacor88">IG</a>
+			<a href="/about">About</a>
+			<script>{"links":[{"url":"https:\/\/gacor88vip.xyz\/"},{"url":"https:\/\/gacor88b.net\/daftar"}]}</script>
+		</body></html>`))
+	}))
+	defer page.Close()
+
+	expander := NewShortlinkExpander(0)
+	expander.AddRule(ShortlinkRule{Host: "127.0.0.1", Links: linkPageRegex})
+
+	if !expander.IsLinkPage(page.URL + "/gacor88") {
+		t.Fatalf("Expected %s to be recognized as a link page", page.URL)
+	}
+	if _, err := expander.Expand(page.URL + "/gacor88"); err == nil {
+		t.Error("Expected an error expanding a link page as a redirect")
+	}
+
+	chains, err := expander.ExpandLinks(page.URL + "/old")
+	if err != nil {
+		t.Fatalf("Expected link page to expand, got error: %v", err)
+	}
+	var destinations []string
+	for _, chain := range chains {
+		destinations = append(destinations, chain.Destination)
+	}
+	expected := []string{"https://gacor88b.net/daftar", "https://gacor88vip.xyz/"}
+	if strings.Join(destinations, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected destinations %v, got %v", expected, destinations)
+	}
+	if len(chains) > 0 && chains[0].Hops[0] != page.URL+"/gacor88" {
+		t.Errorf("Expected the link page as the first hop, got %v", chains[0].Hops)
+	}
+}
+
+// TestShortlinkCatalog tests that the expander and the mirrors module know
+// the same shorteners
+func TestShortlinkCatalog(t *testing.T) {
+	expander := NewShortlinkExpander(0)
+	mirrorDetector := detector.NewMirrorDetector()
+	for _, shortener := range detector.Shorteners {
+		rawURL := "https://" + shortener.Host + "/gacor88"
+		if !expander.IsShortlink(rawURL) || !mirrorDetector.IsShortlinkHost(shortener.Host) {
+			t.Errorf("Expected %s to be a shortener for both the expander and the mirrors module", shortener.Host)
+		}
+		if expander.IsLinkPage(rawURL) != (shortener.Kind == detector.ShortenerLinkPage) {
+			t.Errorf("Expected %s to be a link page only if it is cataloged as one", shortener.Host)
+		}
+	}
+	if len(expander.Rules) != len(detector.Shorteners) {
+		t.Errorf("Expected one rule per cataloged shortener, got %d", len(expander.Rules))
+	}
+}
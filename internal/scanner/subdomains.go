@@ -0,0 +1,49 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// enumerateSubdomains finds the resolving subdomains of the domain a host is
+// registered under, from the wordlist, certificate transparency and the
+// page's links, with the CDN status of each
+func enumerateSubdomains(ctx context.Context, domain, body string, timeout time.Duration) ([]models.Subdomain, error) {
+	cfg := config.Get()
+	name, err := detector.ParseDomainName(dnsName(domain))
+	if err != nil {
+		return nil, err
+	}
+	r, err := scanResolver(timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	wordlist, err := detector.LoadSubdomainWordlist(cfg.Catalogs.Subdomains)
+	if err != nil {
+		fmt.Printf("Error loading subdomain wordlist, using built-in wordlist: %v\n", err)
+		wordlist, _ = detector.LoadSubdomainWordlist("")
+	}
+
+	enumerator := detector.NewSubdomainEnumerator(r, wordlist)
+	enumerator.Concurrency = cfg.Subdomains.Concurrency
+	enumerator.CTURL = cfg.Subdomains.CTURL
+	enumerator.HTTP.Timeout = timeout
+	origin := detector.NewOriginIPDetector()
+	origin.Client.Timeout = timeout
+	origin.Ranges = loadCDNRanges()
+	origin.Engine = loadCDNEngine()
+	enumerator.CDNStatus = origin.CDNStatusOf
+
+	subdomains := enumerator.Enumerate(ctx, name.Domain, detector.ExtractSubdomains(body, name.Domain))
+	if subdomains == nil {
+		// Not nil, so the origin_ip module does not enumerate again
+		subdomains = []models.Subdomain{}
+	}
+	return subdomains, nil
+}
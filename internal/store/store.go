@@ -0,0 +1,358 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/genesis410/fogger/internal/config"
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// storeVersion is the format version written to the store file
+const storeVersion = 1
+
+// Store persists analysis results in a local JSON file
+type Store struct {
+	Path string
+
+	mu   sync.RWMutex
+	data storeData
+}
+
+// storeData is the on-disk layout of the store file
+type storeData struct {
+	Version int                               `json:"version"`
+	Domains map[string]*models.AnalysisResult `json:"domains"`
+	// Registrations caches RDAP and WHOIS lookups by registered domain
+	Registrations map[string]*models.Registration `json:"registrations,omitempty"`
+	// Siblings links registered domains whose names belong to one rotation
+	// set, in both directions
+	Siblings map[string][]string `json:"siblings,omitempty"`
+	// LSHBands is the band count the buckets were built with
+	LSHBands int `json:"lsh_bands,omitempty"`
+	// Buckets maps the LSH band keys of page signatures to the domains
+	// whose signatures fall in them
+	Buckets map[string][]string `json:"lsh_buckets,omitempty"`
+}
+
+// DefaultPath returns the configured store path, or ~/.fogger/store.json
+func DefaultPath() (string, error) {
+	if path := config.Get().Store.Path; path != "" {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %v", err)
+	}
+	return filepath.Join(home, ".fogger", "store.json"), nil
+}
+
+// Open loads the store at path. A missing file yields an empty store.
+func Open(path string) (*Store, error) {
+	s := &Store{
+		Path: path,
+		data: storeData{
+			Version: storeVersion,
+			Domains: make(map[string]*models.AnalysisResult),
+		},
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read store: %v", err)
+	}
+
+	if err := json.Unmarshal(data, &s.data); err != nil {
+		return nil, fmt.Errorf("failed to parse store %s: %v", path, err)
+	}
+	if s.data.Domains == nil {
+		s.data.Domains = make(map[string]*models.AnalysisResult)
+	}
+	// Stores written before the buckets, or with another band layout, are
+	// indexed once on load
+	if s.data.LSHBands != detector.LSHBands {
+		s.data.Buckets = nil
+		for domain, result := range s.data.Domains {
+			s.indexSignature(domain, result.Domain.Signature)
+		}
+	}
+
+	return s, nil
+}
+
+// OpenDefault opens the store at the default path
+func OpenDefault() (*Store, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return Open(path)
+}
+
+// PutResult stores the analysis result of a domain, replacing any previous
+// result but keeping the first-seen time
+func (s *Store) PutResult(result *models.AnalysisResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	domain := result.Domain.Domain
+	if previous, ok := s.data.Domains[domain]; ok {
+		if !previous.Domain.FirstSeen.IsZero() {
+			result.Domain.FirstSeen = previous.Domain.FirstSeen
+		}
+		s.unindexSignature(domain, previous.Domain.Signature)
+	}
+	s.data.Domains[domain] = result
+	s.indexSignature(domain, result.Domain.Signature)
+}
+
+// GetResult returns the stored analysis result of a domain
+func (s *Store) GetResult(domain string) (*models.AnalysisResult, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result, ok := s.data.Domains[domain]
+	return result, ok
+}
+
+// Results returns all stored analysis results ordered by domain
+func (s *Store) Results() []*models.AnalysisResult {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := make([]*models.AnalysisResult, 0, len(s.data.Domains))
+	for _, result := range s.data.Domains {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Domain.Domain < results[j].Domain.Domain
+	})
+	return results
+}
+
+// SimilarCandidates returns the stored results that may reach threshold
+// similarity with signature, ordered by domain: those sharing an LSH bucket
+// with it, or every result with a signature when threshold is below
+// detector.LSHMinSimilarity.
+func (s *Store) SimilarCandidates(signature *models.PageSignature, threshold float64) []*models.AnalysisResult {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var results []*models.AnalysisResult
+	if signature == nil || len(signature.MinHash) != detector.MinHashSize {
+		return results
+	}
+
+	if threshold < detector.LSHMinSimilarity {
+		for _, result := range s.data.Domains {
+			if result.Domain.Signature != nil {
+				results = append(results, result)
+			}
+		}
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].Domain.Domain < results[j].Domain.Domain
+		})
+		return results
+	}
+
+	seen := make(map[string]bool)
+	for _, key := range bucketKeys(signature) {
+		for _, domain := range s.data.Buckets[key] {
+			if seen[domain] {
+				continue
+			}
+			seen[domain] = true
+			if result, ok := s.data.Domains[domain]; ok {
+				results = append(results, result)
+			}
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Domain.Domain < results[j].Domain.Domain
+	})
+	return results
+}
+
+// indexSignature adds a domain to the LSH buckets of its signature
+func (s *Store) indexSignature(domain string, signature *models.PageSignature) {
+	if signature == nil || len(signature.MinHash) != detector.MinHashSize {
+		return
+	}
+	if s.data.Buckets == nil {
+		s.data.Buckets = make(map[string][]string)
+	}
+	s.data.LSHBands = detector.LSHBands
+	for _, key := range bucketKeys(signature) {
+		s.data.Buckets[key] = appendDomain(s.data.Buckets[key], domain)
+	}
+}
+
+// unindexSignature removes a domain from the LSH buckets of its signature
+func (s *Store) unindexSignature(domain string, signature *models.PageSignature) {
+	if signature == nil || len(signature.MinHash) != detector.MinHashSize {
+		return
+	}
+	for _, key := range bucketKeys(signature) {
+		domains := s.data.Buckets[key][:0]
+		for _, d := range s.data.Buckets[key] {
+			if d != domain {
+				domains = append(domains, d)
+			}
+		}
+		if len(domains) == 0 {
+			delete(s.data.Buckets, key)
+		} else {
+			s.data.Buckets[key] = domains
+		}
+	}
+}
+
+// bucketKeys returns the LSH band keys of a signature as map keys
+func bucketKeys(signature *models.PageSignature) []string {
+	var keys []string
+	for _, key := range detector.LSHBandKeys(signature.MinHash, detector.LSHBands, detector.LSHRows) {
+		keys = append(keys, strconv.FormatUint(key, 16))
+	}
+	return keys
+}
+
+// appendDomain appends domain to domains unless it is already present
+func appendDomain(domains []string, domain string) []string {
+	for _, d := range domains {
+		if d == domain {
+			return domains
+		}
+	}
+	return append(domains, domain)
+}
+
+// PutRegistration caches the registration of a domain
+func (s *Store) PutRegistration(registration *models.Registration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data.Registrations == nil {
+		s.data.Registrations = make(map[string]*models.Registration)
+	}
+	s.data.Registrations[registration.Domain] = registration
+}
+
+// GetRegistration returns the cached registration of a domain
+func (s *Store) GetRegistration(domain string) (*models.Registration, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	registration, ok := s.data.Registrations[domain]
+	return registration, ok
+}
+
+// LinkSiblings records that two registered domains belong to one rotation
+// set. It reports whether the link is new.
+func (s *Store) LinkSiblings(a, b string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if a == b {
+		return false
+	}
+	if s.data.Siblings == nil {
+		s.data.Siblings = make(map[string][]string)
+	}
+	for _, sibling := range s.data.Siblings[a] {
+		if sibling == b {
+			return false
+		}
+	}
+	s.data.Siblings[a] = append(s.data.Siblings[a], b)
+	s.data.Siblings[b] = append(s.data.Siblings[b], a)
+	sort.Strings(s.data.Siblings[a])
+	sort.Strings(s.data.Siblings[b])
+	return true
+}
+
+// LinkSiblingRelations links the SIBLING_OF relations of a result to the
+// stored domains they point at. Relations to domains that are not stored,
+// such as advertised mirrors, are left unlinked.
+func (s *Store) LinkSiblingRelations(result *models.AnalysisResult) {
+	stored := make(map[string]bool)
+	for _, storedResult := range s.Results() {
+		if domain := registeredDomain(storedResult.Domain.Domain); domain != "" {
+			stored[domain] = true
+		}
+	}
+	for _, relation := range result.Domain.Relations {
+		if relation.Type == models.RelationSiblingOf && stored[relation.Target] {
+			s.LinkSiblings(relation.Source, relation.Target)
+		}
+	}
+}
+
+// registeredDomain returns the registered domain of a stored domain, which
+// may have been saved as a URL
+func registeredDomain(domain string) string {
+	if parsed, err := url.Parse(domain); err == nil && parsed.Hostname() != "" {
+		domain = parsed.Hostname()
+	}
+	name, err := detector.ParseDomainName(domain)
+	if err != nil {
+		return ""
+	}
+	return name.Domain
+}
+
+// Siblings returns the domains linked to a registered domain as one
+// rotation set
+func (s *Store) Siblings(domain string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]string(nil), s.data.Siblings[domain]...)
+}
+
+// Save writes the store to disk, replacing the file atomically. Each save
+// writes its own temporary file, so concurrent saves do not clobber each
+// other's writes; the last one to finish replaces the store.
+func (s *Store) Save() error {
+	s.mu.RLock()
+	data, err := json.MarshalIndent(s.data, "", "  ")
+	s.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal store: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create store directory: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write store: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write store: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write store: %v", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write store: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), s.Path); err != nil {
+		return fmt.Errorf("failed to replace store: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package store
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/genesis410/fogger/internal/detector"
+	"github.com/genesis410/fogger/internal/models"
+)
+
+// TestStoreRoundTrip tests saving and reloading analysis results
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fogger", "store.json")
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Expected missing store to open empty, got error: %v", err)
+	}
+
+	firstSeen := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	db.PutResult(&models.AnalysisResult{
+		Domain:   models.Domain{Domain: "gacor88.com", FirstSeen: firstSeen},
+		JLIScore: 0.8,
+	})
+	db.PutResult(&models.AnalysisResult{
+		Domain:   models.Domain{Domain: "gacor88.com", FirstSeen: time.Now()},
+		JLIScore: 0.9,
+	})
+	db.PutResult(&models.AnalysisResult{Domain: models.Domain{Domain: "alpha.net"}})
+
+	if err := db.Save(); err != nil {
+		t.Fatalf("Expected store to save, got error: %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Expected store to reopen, got error: %v", err)
+	}
+
+	result, ok := reopened.GetResult("gacor88.com")
+	if !ok {
+		t.Fatal("Expected stored result for gacor88.com")
+	}
+	if result.JLIScore != 0.9 {
+		t.Errorf("Expected latest result to replace the previous one, got score %.2f", result.JLIScore)
+	}
+	if !result.Domain.FirstSeen.Equal(firstSeen) {
+		t.Errorf("Expected first-seen time to be kept, got %v", result.Domain.FirstSeen)
+	}
+
+	results := reopened.Results()
+	if len(results) != 2 || results[0].Domain.Domain != "alpha.net" {
+		t.Errorf("Expected 2 results ordered by domain, got %d", len(results))
+	}
+}
+
+// TestStoreSiblings tests that sibling links are kept in both directions
+func TestStoreSiblings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !db.LinkSiblings("slot88b.net", "slot88a.com") {
+		t.Error("Expected a new sibling link")
+	}
+	if db.LinkSiblings("slot88a.com", "slot88b.net") {
+		t.Error("Expected the reverse link to exist already")
+	}
+	db.LinkSiblings("slot88b.net", "slot88c.xyz")
+	if err := db.Save(); err != nil {
+		t.Fatalf("Expected store to save, got error: %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Expected store to reopen, got error: %v", err)
+	}
+	if siblings := reopened.Siblings("slot88b.net"); len(siblings) != 2 || siblings[0] != "slot88a.com" {
+		t.Errorf("Expected slot88a.com and slot88c.xyz, got %v", siblings)
+	}
+	if siblings := reopened.Siblings("slot88a.com"); len(siblings) != 1 || siblings[0] != "slot88b.net" {
+		t.Errorf("Expected slot88b.net, got %v", siblings)
+	}
+}
+
+// TestStoreLinkSiblingRelations tests that SIBLING_OF relations are linked
+// to stored domains only
+func TestStoreLinkSiblingRelations(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "store.json"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	db.PutResult(&models.AnalysisResult{Domain: models.Domain{Domain: "https://www.slot88a.com/"}})
+
+	db.LinkSiblingRelations(&models.AnalysisResult{Domain: models.Domain{
+		Domain: "slot88b.net",
+		Relations: []models.Relation{
+			{Source: "slot88b.net", Target: "slot88a.com", Type: models.RelationSiblingOf},
+			{Source: "slot88b.net", Target: "slot88c.xyz", Type: models.RelationSiblingOf},
+		},
+	}})
+
+	if siblings := db.Siblings("slot88b.net"); len(siblings) != 1 || siblings[0] != "slot88a.com" {
+		t.Errorf("Expected slot88b.net linked to the stored slot88a.com only, got %v", siblings)
+	}
+}
+
+// TestStoreConcurrentSaves tests that stores saved at once each write a
+// complete file
+func TestStoreConcurrentSaves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			db, err := Open(path)
+			if err != nil {
+				errs <- err
+				return
+			}
+			db.PutResult(&models.AnalysisResult{Domain: models.Domain{Domain: fmt.Sprintf("slot%da.com", i)}})
+			errs <- db.Save()
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+
+	if _, err := Open(path); err != nil {
+		t.Errorf("Expected a readable store, got error: %v", err)
+	}
+	if leftovers, _ := filepath.Glob(path + ".*.tmp"); len(leftovers) != 0 {
+		t.Errorf("Expected no temporary files left, got %v", leftovers)
+	}
+}
+
+// TestStoreSimilarCandidates tests that LSH buckets follow saved signatures
+// and survive a reload
+func TestStoreSimilarCandidates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	signature := func(offset uint32) *models.PageSignature {
+		minHash := make([]uint32, detector.MinHashSize)
+		for i := range minHash {
+			minHash[i] = uint32(i)*7919 + offset
+		}
+		return &models.PageSignature{MinHash: minHash}
+	}
+
+	db.PutResult(&models.AnalysisResult{Domain: models.Domain{Domain: "gacor88.com", Signature: signature(0)}})
+	db.PutResult(&models.AnalysisResult{Domain: models.Domain{Domain: "news.example", Signature: signature(1)}})
+	db.PutResult(&models.AnalysisResult{Domain: models.Domain{Domain: "gacor99.net", Signature: signature(0)}})
+	// A rescan with a different page leaves its old buckets
+	db.PutResult(&models.AnalysisResult{Domain: models.Domain{Domain: "gacor99.net", Signature: signature(2)}})
+
+	if err := db.Save(); err != nil {
+		t.Fatalf("Expected store to save, got error: %v", err)
+	}
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Expected store to reopen, got error: %v", err)
+	}
+
+	candidates := reopened.SimilarCandidates(signature(0), 0.8)
+	if len(candidates) != 1 || candidates[0].Domain.Domain != "gacor88.com" {
+		t.Errorf("Expected gacor88.com as the only candidate, got %v", candidates)
+	}
+	if candidates := reopened.SimilarCandidates(&models.PageSignature{}, 0.8); len(candidates) != 0 {
+		t.Errorf("Expected no candidates without a MinHash, got %v", candidates)
+	}
+	if candidates := reopened.SimilarCandidates(signature(0), 0.5); len(candidates) != 3 {
+		t.Errorf("Expected every stored page below the LSH similarity, got %v", candidates)
+	}
+}